@@ -0,0 +1,149 @@
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Xuanwo/storage"
+	"github.com/Xuanwo/storage/types"
+)
+
+// DefaultDeleteBatchConcurrency is the default count of objects deleted concurrently in DeleteBatch.
+const DefaultDeleteBatchConcurrency = 16
+
+// deleteAllBatchSize is the count of files deleted in one DeleteBatch while DeleteAll.
+const deleteAllBatchSize = 1000
+
+// DeleteBatch will delete multiple objects from store.
+//
+// Storager implements storage.BatchDeleter will use service's native batch delete, otherwise objects will be deleted
+// concurrently via Delete. Results will be returned in the same order as paths.
+func DeleteBatch(ctx context.Context, store storage.Storager, paths []string, pairs ...*types.Pair) (results []types.DeleteResult, err error) {
+	if d, ok := store.(storage.BatchDeleter); ok {
+		return d.DeleteBatchWithContext(ctx, paths, pairs...)
+	}
+
+	results = make([]types.DeleteResult, len(paths))
+	for k, v := range paths {
+		results[k].Path = v
+	}
+
+	idx := make(chan int)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < DefaultDeleteBatchConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range idx {
+				results[k].Err = store.DeleteWithContext(ctx, paths[k], pairs...)
+			}
+		}()
+	}
+
+	for k := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		idx <- k
+	}
+	close(idx)
+	wg.Wait()
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+/*
+DeleteAll will delete path and all objects under it from store, like os.RemoveAll.
+
+Storager implements storage.RecursiveDeleter will use service's native DeleteAll, otherwise files under path will be
+walked via Walk and deleted via DeleteBatch, and then dirs will be deleted from the deepest one.
+Objects which don't exist will be ignored. Deleting the root of store will be rejected with types.ErrRootNotAllowed.
+*/
+func DeleteAll(ctx context.Context, store storage.Storager, path string, pairs ...*types.Pair) (err error) {
+	if err = types.CheckNotRoot(path); err != nil {
+		return err
+	}
+
+	if d, ok := store.(storage.RecursiveDeleter); ok {
+		return d.DeleteAllWithContext(ctx, path, pairs...)
+	}
+
+	m, err := store.MetadataWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	flat, _ := m.GetFlatList()
+
+	// path could be a file or an empty dir which could be deleted directly.
+	err = store.DeleteWithContext(ctx, path, pairs...)
+	// Prefix based services could still have objects under path after deleted.
+	if err == nil && !flat {
+		return nil
+	}
+	if err != nil && !errors.Is(err, types.ErrDirNotEmpty) && !errors.Is(err, types.ErrObjectNotExist) {
+		return err
+	}
+
+	root := path
+	if !strings.HasSuffix(root, "/") {
+		root += "/"
+	}
+
+	files := make([]string, 0, deleteAllBatchSize)
+	deleteFiles := func() error {
+		results, err := DeleteBatch(ctx, store, files, pairs...)
+		if err != nil {
+			return err
+		}
+		for _, v := range results {
+			if v.Err != nil && !errors.Is(v.Err, types.ErrObjectNotExist) {
+				return v.Err
+			}
+		}
+		files = files[:0]
+		return nil
+	}
+
+	dirs := make([]string, 0)
+	err = Walk(ctx, store, root, func(o *types.Object) error {
+		if o.Type == types.ObjectTypeDir {
+			dirs = append(dirs, o.Name)
+			return nil
+		}
+
+		files = append(files, o.Name)
+		if len(files) < deleteAllBatchSize {
+			return nil
+		}
+		return deleteFiles()
+	}, nil)
+	if err != nil && !errors.Is(err, types.ErrObjectNotExist) {
+		return err
+	}
+	if err = deleteFiles(); err != nil {
+		return err
+	}
+
+	// Dirs could only be deleted after all objects under them deleted.
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return dirDepth(dirs[i]) > dirDepth(dirs[j])
+	})
+	dirs = append(dirs, path)
+	for _, v := range dirs {
+		err = store.DeleteWithContext(ctx, v, pairs...)
+		if err != nil && !errors.Is(err, types.ErrObjectNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func dirDepth(name string) int {
+	return strings.Count(strings.TrimSuffix(name, "/"), "/")
+}
@@ -0,0 +1,186 @@
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage"
+	"github.com/Xuanwo/storage/types"
+)
+
+type deleteStorager struct {
+	storage.Storager
+
+	lock    sync.Mutex
+	deleted []string
+}
+
+func (s *deleteStorager) DeleteWithContext(ctx context.Context, path string, pairs ...*types.Pair) error {
+	if path == "not-exist" {
+		return types.ErrObjectNotExist
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.deleted = append(s.deleted, path)
+	return nil
+}
+
+type batchDeleteStorager struct {
+	deleteStorager
+
+	paths []string
+}
+
+func (s *batchDeleteStorager) DeleteBatch(paths []string, pairs ...*types.Pair) ([]types.DeleteResult, error) {
+	panic("not implemented")
+}
+
+func (s *batchDeleteStorager) DeleteBatchWithContext(ctx context.Context, paths []string, pairs ...*types.Pair) ([]types.DeleteResult, error) {
+	s.paths = paths
+	return make([]types.DeleteResult, len(paths)), nil
+}
+
+func TestDeleteBatch(t *testing.T) {
+	paths := make([]string, 100)
+	for k := range paths {
+		paths[k] = strconv.Itoa(k)
+	}
+	paths[42] = "not-exist"
+
+	store := &deleteStorager{}
+	results, err := DeleteBatch(context.Background(), store, paths)
+	assert.NoError(t, err)
+	assert.Equal(t, len(paths), len(results))
+	assert.Equal(t, len(paths)-1, len(store.deleted))
+	for k, v := range results {
+		assert.Equal(t, paths[k], v.Path)
+		if k == 42 {
+			assert.True(t, errors.Is(v.Err, types.ErrObjectNotExist))
+		} else {
+			assert.NoError(t, v.Err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = DeleteBatch(ctx, &deleteStorager{}, paths)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestDeleteBatch_BatchDeleter(t *testing.T) {
+	store := &batchDeleteStorager{}
+
+	results, err := DeleteBatch(context.Background(), store, []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, []string{"a", "b"}, store.paths)
+	assert.Empty(t, store.deleted)
+}
+
+// deleteAllStorager is a fake storager which deletes files from walkStorager.
+type deleteAllStorager struct {
+	walkStorager
+
+	deleteLock sync.Mutex
+	deleted    []string
+}
+
+func (s *deleteAllStorager) DeleteWithContext(ctx context.Context, path string, pairs ...*types.Pair) error {
+	s.deleteLock.Lock()
+	defer s.deleteLock.Unlock()
+
+	isDeleted := func(name string) bool {
+		for _, v := range s.deleted {
+			if v == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	exist := false
+	for _, v := range s.files {
+		if v == path && !isDeleted(v) {
+			exist = true
+		}
+		if s.flat || !strings.HasPrefix(v, path+"/") {
+			continue
+		}
+		// Dir only exists in dir based storager.
+		exist = true
+		if !isDeleted(v) {
+			return types.ErrDirNotEmpty
+		}
+	}
+	if !exist || isDeleted(path) {
+		return types.ErrObjectNotExist
+	}
+
+	s.deleted = append(s.deleted, path)
+	return nil
+}
+
+type recursiveDeleteStorager struct {
+	storage.Storager
+
+	path string
+}
+
+func (s *recursiveDeleteStorager) DeleteAll(path string, pairs ...*types.Pair) error {
+	panic("not implemented")
+}
+
+func (s *recursiveDeleteStorager) DeleteAllWithContext(ctx context.Context, path string, pairs ...*types.Pair) error {
+	s.path = path
+	return nil
+}
+
+func TestDeleteAll(t *testing.T) {
+	for _, flat := range []bool{true, false} {
+		store := &deleteAllStorager{walkStorager: walkStorager{files: walkFiles, flat: flat}}
+
+		err := DeleteAll(context.Background(), store, "a")
+		assert.NoError(t, err)
+
+		expect := []string{"a/1.txt", "a/b/2.txt", "a/b/3.go", "a/c/4.txt"}
+		if !flat {
+			// Dirs should be deleted after all files under them.
+			expect = append(expect, "a/b", "a/c", "a")
+		}
+		assert.ElementsMatch(t, expect, store.deleted)
+		if !flat {
+			assert.Equal(t, "a", store.deleted[len(store.deleted)-1])
+		}
+	}
+
+	// Delete a file should also work.
+	store := &deleteAllStorager{walkStorager: walkStorager{files: walkFiles}}
+	err := DeleteAll(context.Background(), store, "6.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"6.txt"}, store.deleted)
+}
+
+func TestDeleteAll_Root(t *testing.T) {
+	for _, v := range []string{"", "/"} {
+		store := &deleteAllStorager{walkStorager: walkStorager{files: walkFiles}}
+
+		err := DeleteAll(context.Background(), store, v)
+		assert.True(t, errors.Is(err, types.ErrRootNotAllowed))
+		assert.Empty(t, store.deleted)
+	}
+}
+
+func TestDeleteAll_RecursiveDeleter(t *testing.T) {
+	store := &recursiveDeleteStorager{}
+
+	err := DeleteAll(context.Background(), store, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", store.path)
+}
@@ -3,20 +3,12 @@ package coreutils
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/Xuanwo/storage"
 	"github.com/Xuanwo/storage/pkg/config"
 	"github.com/Xuanwo/storage/pkg/namespace"
-	"github.com/Xuanwo/storage/services/azblob"
-	"github.com/Xuanwo/storage/services/cos"
-	"github.com/Xuanwo/storage/services/dropbox"
-	"github.com/Xuanwo/storage/services/fs"
-	"github.com/Xuanwo/storage/services/gcs"
-	"github.com/Xuanwo/storage/services/kodo"
-	"github.com/Xuanwo/storage/services/oss"
-	"github.com/Xuanwo/storage/services/qingstor"
-	"github.com/Xuanwo/storage/services/s3"
-	"github.com/Xuanwo/storage/services/uss"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/pairs"
 )
@@ -30,55 +22,106 @@ var (
 	ErrServiceNamespaceNotGiven = errors.New("service namespace not given")
 )
 
-type openFunc func(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error)
-
-var opener = map[string]openFunc{
-	azblob.Type:   openAzblob,
-	cos.Type:      openCOS,
-	dropbox.Type:  openDropbox,
-	fs.Type:       openFs,
-	gcs.Type:      openGCS,
-	kodo.Type:     openKodo,
-	oss.Type:      openOSS,
-	qingstor.Type: openQingStor,
-	s3.Type:       openS3,
-	uss.Type:      openUSS,
+// OpenFunc will open a service with namespace and pairs parsed from config string.
+type OpenFunc func(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error)
+
+var (
+	opener     = make(map[string]OpenFunc)
+	openerLock sync.RWMutex
+)
+
+// Register will register an OpenFunc for service type, so that this service could be opened via config string.
+//
+// Register is usually called in service package's init, and caller need to import the service package
+// to make it available:
+//
+//	import _ "github.com/Xuanwo/storage/services/qingstor"
+//
+// Register will panic if fn is nil or the same type registered twice.
+func Register(t string, fn OpenFunc) {
+	openerLock.Lock()
+	defer openerLock.Unlock()
+
+	if fn == nil {
+		panic(fmt.Sprintf("coreutils: Register open func of %s is nil", t))
+	}
+	if _, ok := opener[t]; ok {
+		panic(fmt.Sprintf("coreutils: Register called twice for %s", t))
+	}
+	opener[t] = fn
+}
+
+// Services will return all registered service types.
+func Services() []string {
+	openerLock.RLock()
+	defer openerLock.RUnlock()
+
+	ts := make([]string, 0, len(opener))
+	for k := range opener {
+		ts = append(ts, k)
+	}
+	sort.Strings(ts)
+	return ts
 }
 
 // Open will parse config string and return valid Servicer and Storager.
 //
 // Depends on config string's service type, Servicer could be nil.
 // Depends on config string's content, Storager could be nil if namespace not given.
+//
+// Config string without "://" like "<profile>:<namespace>" will be resolved via named profiles
+// in config.DefaultProfileFile, see config.Profile for more details.
 func Open(cfg string) (srv storage.Servicer, store storage.Storager, err error) {
 	errorMessage := "coreutils Open [%s]: <%w>"
 
-	t, ns, opt, err := config.Parse(cfg)
+	var t, ns string
+	var opt []*types.Pair
+	if config.IsProfile(cfg) {
+		t, ns, opt, err = parseProfile(cfg)
+	} else {
+		t, ns, opt, err = config.Parse(cfg)
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf(errorMessage, cfg, err)
+		return nil, nil, fmt.Errorf(errorMessage, config.Redact(cfg), err)
 	}
 
+	openerLock.RLock()
 	fn, ok := opener[t]
+	openerLock.RUnlock()
 	if !ok {
-		err = fmt.Errorf(errorMessage, cfg, ErrServiceNotSupported)
+		err = fmt.Errorf(errorMessage, config.Redact(cfg), ErrServiceNotSupported)
 		return nil, nil, err
 	}
 	srv, store, err = fn(ns, opt...)
 	if err != nil {
-		return nil, nil, fmt.Errorf(errorMessage, cfg, err)
+		return nil, nil, fmt.Errorf(errorMessage, config.Redact(cfg), err)
 	}
 	return
 }
 
+// parseProfile will parse config string via profiles loaded from default profile file.
+func parseProfile(cfg string) (t, ns string, opt []*types.Pair, err error) {
+	path, err := config.DefaultProfileFile()
+	if err != nil {
+		return
+	}
+	p, err := config.LoadProfiles(path)
+	if err != nil {
+		return
+	}
+	return p.Parse(cfg)
+}
+
 // OpenServicer will open a servicer from config string.
 func OpenServicer(cfg string) (srv storage.Servicer, err error) {
 	errorMessage := "coreutils OpenServicer [%s]: <%w>"
 
 	srv, _, err = Open(cfg)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, cfg, err)
+		return nil, fmt.Errorf(errorMessage, config.Redact(cfg), err)
 	}
 	if srv == nil {
-		return nil, fmt.Errorf(errorMessage, cfg, ErrServiceNotImplemented)
+		return nil, fmt.Errorf(errorMessage, config.Redact(cfg), ErrServiceNotImplemented)
 	}
 	return
 }
@@ -89,126 +132,51 @@ func OpenStorager(cfg string) (store storage.Storager, err error) {
 
 	_, store, err = Open(cfg)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, cfg, err)
+		return nil, fmt.Errorf(errorMessage, config.Redact(cfg), err)
 	}
 	if store == nil {
-		return nil, fmt.Errorf(errorMessage, cfg, ErrServiceNamespaceNotGiven)
+		return nil, fmt.Errorf(errorMessage, config.Redact(cfg), ErrServiceNamespaceNotGiven)
 	}
 	return
 }
 
-func openObjectStorage(srv storage.Servicer, ns string) (store storage.Storager, err error) {
+// OpenObjectStorage will get a storager from servicer via namespace like "<bucket>/<prefix>".
+//
+// It's a helper for object storage services' OpenFunc, store will be nil if bucket name not given.
+// opt will be passed to Get and Init, prefix in namespace will override work_dir in opt if not empty.
+func OpenObjectStorage(srv storage.Servicer, ns string, opt ...*types.Pair) (store storage.Storager, err error) {
 	name, prefix := namespace.ParseObjectStorage(ns)
 	// name == "" means no bucket name input, return nil directly.
 	if name == "" {
 		return
 	}
-	store, err = srv.Get(name)
-	if err != nil {
-		return
-	}
-	err = store.Init(pairs.WithWorkDir(prefix))
+	store, err = srv.Get(name, opt...)
 	if err != nil {
 		return
 	}
-	return
-}
-
-func openAzblob(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
-	srv, err = azblob.New(opt...)
-	if err != nil {
-		return
+	if prefix != "" {
+		opt = append(opt, pairs.WithWorkDir(prefix))
 	}
-	store, err = openObjectStorage(srv, ns)
-	return
-}
-
-func openCOS(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
-	srv, err = cos.New(opt...)
-	if err != nil {
-		return
-	}
-	store, err = openObjectStorage(srv, ns)
-	return
-}
-
-func openDropbox(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
-	store, err = dropbox.New(opt...)
-	if err != nil {
-		return
-	}
-
-	err = store.Init(pairs.WithWorkDir(ns))
+	err = store.Init(opt...)
 	if err != nil {
 		return
 	}
 	return
 }
 
-func openFs(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
-	store = fs.New()
-	path := namespace.ParseLocalFS(ns)
-	err = store.Init(pairs.WithWorkDir(path))
-	if err != nil {
-		return
-	}
-	return
-}
-
-func openGCS(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
-	srv, err = gcs.New(opt...)
-	if err != nil {
-		return
-	}
-	store, err = openObjectStorage(srv, ns)
-	return
-}
-
-func openKodo(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
-	srv, err = kodo.New(opt...)
-	if err != nil {
-		return
-	}
-	store, err = openObjectStorage(srv, ns)
-	return
-}
-
-func openOSS(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
-	srv, err = oss.New(opt...)
-	if err != nil {
-		return
-	}
-	store, err = openObjectStorage(srv, ns)
-	return
-}
-
-func openQingStor(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
-	srv, err = qingstor.New(opt...)
-	if err != nil {
-		return
-	}
-	store, err = openObjectStorage(srv, ns)
-	return
-}
-
-func openS3(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
-	srv, err = s3.New(opt...)
-	if err != nil {
-		return
-	}
-	store, err = openObjectStorage(srv, ns)
-	return
-}
-
-func openUSS(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
-	name, prefix := namespace.ParseObjectStorage(ns)
-	store, err = uss.New(name, opt...)
-	if err != nil {
-		return
-	}
-	err = store.Init(pairs.WithWorkDir(prefix))
-	if err != nil {
-		return
-	}
-	return
+// CheckOptions will check whether all pairs parsed from config string are in keys.
+//
+// It's a helper for services' OpenFunc, so that options which can't be applied by this service
+// will not be ignored silently, config.ErrInvalidConfig will be returned for them.
+func CheckOptions(opt []*types.Pair, keys ...string) (err error) {
+	supported := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		supported[k] = true
+	}
+	for _, v := range opt {
+		if !supported[v.Key] {
+			return fmt.Errorf("option %s not supported: %w", v.Key, config.ErrInvalidConfig)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,90 @@
+package coreutils
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage"
+	"github.com/Xuanwo/storage/pkg/config"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+func TestRegister(t *testing.T) {
+	called := false
+	var namespace string
+
+	Register("test-register", func(ns string, opt ...*types.Pair) (storage.Servicer, storage.Storager, error) {
+		called = true
+		namespace = ns
+		return nil, nil, nil
+	})
+
+	assert.Contains(t, Services(), "test-register")
+
+	_, _, err := Open("test-register:///bucket/prefix")
+	assert.NoError(t, err)
+	assert.True(t, called)
+	assert.Equal(t, "bucket/prefix", namespace)
+
+	assert.Panics(t, func() {
+		Register("test-register", func(ns string, opt ...*types.Pair) (storage.Servicer, storage.Storager, error) {
+			return nil, nil, nil
+		})
+	})
+	assert.Panics(t, func() {
+		Register("test-nil", nil)
+	})
+}
+
+func TestOpen(t *testing.T) {
+	_, _, err := Open("not-registered:///bucket")
+	assert.True(t, errors.Is(err, ErrServiceNotSupported))
+
+	_, err = OpenServicer("test-servicer:///bucket")
+	assert.True(t, errors.Is(err, ErrServiceNotSupported))
+}
+
+func TestOpenProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "profiles.json")
+	err = ioutil.WriteFile(path, []byte(`{"test-profile": {"type": "test-profile", "location": "pek3b"}}`), 0600)
+	assert.NoError(t, err)
+
+	os.Setenv(config.EnvProfileFile, path)
+	defer os.Unsetenv(config.EnvProfileFile)
+
+	var namespace string
+	var opt []*types.Pair
+	Register("test-profile", func(ns string, o ...*types.Pair) (storage.Servicer, storage.Storager, error) {
+		namespace, opt = ns, o
+		return nil, nil, nil
+	})
+
+	_, _, err = Open("test-profile:/bucket/prefix")
+	assert.NoError(t, err)
+	assert.Equal(t, "bucket/prefix", namespace)
+	assert.ElementsMatch(t, []*types.Pair{pairs.WithLocation("pek3b")}, opt)
+
+	_, _, err = Open("not-exist:/bucket/prefix")
+	assert.True(t, errors.Is(err, config.ErrProfileNotFound))
+}
+
+func TestCheckOptions(t *testing.T) {
+	opt := []*types.Pair{pairs.WithLocation("pek3b"), pairs.WithWorkDir("/prefix")}
+
+	assert.NoError(t, CheckOptions(opt, pairs.Location, pairs.WorkDir, pairs.StorageClass))
+	assert.NoError(t, CheckOptions(nil))
+
+	err := CheckOptions(opt, pairs.WorkDir)
+	assert.True(t, errors.Is(err, config.ErrInvalidConfig))
+	assert.Contains(t, err.Error(), pairs.Location)
+}
@@ -0,0 +1,354 @@
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Xuanwo/storage"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+// SkipDir could be returned by WalkFunc to skip a dir, it's the same value as filepath.SkipDir.
+var SkipDir = filepath.SkipDir
+
+// DefaultWalkConcurrency is the default count of dirs listed concurrently in Walk.
+const DefaultWalkConcurrency = 4
+
+// WalkFunc will be called for every object visited by Walk.
+//
+// If WalkFunc returns SkipDir on a Dir, Walk will not walk into it. If WalkFunc returns SkipDir on a File,
+// Walk will skip the remaining objects in the dir which contains this file.
+// Any other error will stop Walk and be returned by Walk as is.
+type WalkFunc func(o *types.Object) error
+
+// WalkOptions is the options for Walk, nil means all default values.
+type WalkOptions struct {
+	// Concurrency is the count of dirs listed concurrently, DefaultWalkConcurrency will be used if <= 0.
+	// It only works for services which don't support flat list.
+	Concurrency int
+	// MaxDepth is the max depth to walk, objects directly under root have depth 1. 0 means no limit.
+	MaxDepth int
+	// Include is glob patterns used to filter objects passed to WalkFunc, all objects are included if empty.
+	// Include doesn't prevent Walk from walking into dirs.
+	Include []string
+	// Exclude is glob patterns used to filter out objects, objects under an excluded dir will be excluded too.
+	Exclude []string
+	// Sorted will make Walk visit objects in a deterministic order: objects in the same dir are visited in
+	// lexical order, and dirs are walked in the order they are visited.
+	// For services which support flat list, objects are always visited in the order returned by services.
+	Sorted bool
+}
+
+/*
+Walk will walk the store from root recursively, and call fn for every object.
+
+Services support flat list (StorageMeta's FlatList is true) will be walked via a single List, otherwise dirs will be
+listed by a worker pool. fn will always be called sequentially, so caller doesn't need to care about concurrency.
+
+Patterns in Include and Exclude follow path.Match, patterns without "/" match object's base name, otherwise
+match object's path relative to root.
+*/
+func Walk(ctx context.Context, store storage.Storager, root string, fn WalkFunc, opts *WalkOptions) (err error) {
+	errorMessage := "coreutils Walk [%s]: <%w>"
+
+	if opts == nil {
+		opts = &WalkOptions{}
+	}
+	for _, patterns := range [][]string{opts.Include, opts.Exclude} {
+		for _, v := range patterns {
+			if _, err = path.Match(v, ""); err != nil {
+				return fmt.Errorf(errorMessage, root, fmt.Errorf("pattern %s: %w", v, err))
+			}
+		}
+	}
+
+	m, err := store.MetadataWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := &walker{
+		store: store,
+		root:  root,
+		fn:    fn,
+		opts:  opts,
+	}
+	if flat, ok := m.GetFlatList(); ok && flat {
+		return w.walkFlat(ctx)
+	}
+	return w.walkDir(ctx)
+}
+
+type walker struct {
+	store storage.Storager
+	root  string
+	fn    WalkFunc
+	opts  *WalkOptions
+
+	// skippedDirs contains dirs skipped via SkipDir on a Dir while walking flat.
+	skippedDirs []string
+	// skippedFileDirs contains dirs whose remaining files are skipped via SkipDir on a File while walking flat.
+	skippedFileDirs map[string]bool
+}
+
+// walkFlat will walk services which return all objects under root in a single List.
+func (w *walker) walkFlat(ctx context.Context) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var fnErr error
+	handle := func(o *types.Object) {
+		if fnErr != nil {
+			return
+		}
+
+		rel := w.relPath(o.Name)
+		if w.isSkipped(rel) {
+			return
+		}
+		if w.opts.MaxDepth > 0 && depth(rel) > w.opts.MaxDepth {
+			return
+		}
+		if w.isExcluded(rel) {
+			return
+		}
+
+		err := w.visit(o, rel)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, SkipDir) {
+			// List's callback can't return error, so we need to cancel the list.
+			fnErr = err
+			cancel()
+			return
+		}
+
+		if o.Type == types.ObjectTypeDir {
+			w.skippedDirs = append(w.skippedDirs, rel)
+			return
+		}
+		// Only skip the remaining files in the same dir, so a file under root will not skip the whole walk.
+		if w.skippedFileDirs == nil {
+			w.skippedFileDirs = make(map[string]bool)
+		}
+		w.skippedFileDirs[path.Dir(rel)] = true
+	}
+
+	err = w.store.ListWithContext(ctx, w.root, pairs.WithDirFunc(handle), pairs.WithFileFunc(handle))
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+type walkJob struct {
+	seq   int
+	dir   string
+	depth int
+}
+
+type walkResult struct {
+	walkJob
+	objects []*types.Object
+	err     error
+}
+
+// walkDir will walk services which only return objects in the dir via List.
+func (w *walker) walkDir(ctx context.Context) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	concurrency := w.opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultWalkConcurrency
+	}
+
+	jobs := make(chan walkJob)
+	results := make(chan walkResult, concurrency)
+
+	wg := &sync.WaitGroup{}
+	defer func() {
+		// Cancel running lists and wait for all workers exited.
+		cancel()
+		close(jobs)
+		wg.Wait()
+	}()
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				// Results will not be received after Walk returned, so we must not block on sending.
+				select {
+				case results <- w.list(ctx, j):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	pending := []walkJob{{dir: w.root}}
+	running, dispatched, handled := 0, 0, 0
+	// buffered is used to handle results in dispatched order while sorted.
+	buffered := make(map[int]walkResult)
+
+	for len(pending) > 0 || running > 0 {
+		var ch chan walkJob
+		var next walkJob
+		if len(pending) > 0 {
+			ch, next = jobs, pending[0]
+			next.seq = dispatched
+		}
+
+		select {
+		case ch <- next:
+			pending = pending[1:]
+			running++
+			dispatched++
+		case r := <-results:
+			running--
+			if !w.opts.Sorted {
+				dirs, err := w.handle(r)
+				if err != nil {
+					return err
+				}
+				pending = append(pending, dirs...)
+				continue
+			}
+
+			// Handle results in dispatched order so that the order of pending dirs is deterministic.
+			buffered[r.seq] = r
+			for {
+				r, ok := buffered[handled]
+				if !ok {
+					break
+				}
+				delete(buffered, handled)
+				handled++
+
+				dirs, err := w.handle(r)
+				if err != nil {
+					return err
+				}
+				pending = append(pending, dirs...)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// list will list all objects in a dir.
+func (w *walker) list(ctx context.Context, j walkJob) walkResult {
+	r := walkResult{walkJob: j}
+
+	collect := func(o *types.Object) {
+		r.objects = append(r.objects, o)
+	}
+	r.err = w.store.ListWithContext(ctx, j.dir, pairs.WithDirFunc(collect), pairs.WithFileFunc(collect))
+
+	if w.opts.Sorted {
+		sort.Slice(r.objects, func(i, j int) bool {
+			return r.objects[i].Name < r.objects[j].Name
+		})
+	}
+	return r
+}
+
+// handle will visit objects in a listed dir and return dirs need to be walked.
+func (w *walker) handle(r walkResult) (dirs []walkJob, err error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	for _, o := range r.objects {
+		rel := w.relPath(o.Name)
+		if w.isExcluded(rel) {
+			continue
+		}
+
+		err = w.visit(o, rel)
+		if err != nil && !errors.Is(err, SkipDir) {
+			return nil, err
+		}
+		if err != nil && o.Type != types.ObjectTypeDir {
+			// Skip the remaining objects in the dir which contains this file.
+			break
+		}
+		if err != nil || o.Type != types.ObjectTypeDir {
+			continue
+		}
+
+		if w.opts.MaxDepth > 0 && r.depth+1 >= w.opts.MaxDepth {
+			continue
+		}
+		dirs = append(dirs, walkJob{dir: o.Name, depth: r.depth + 1})
+	}
+	return dirs, nil
+}
+
+// visit will call fn if object is included.
+func (w *walker) visit(o *types.Object, rel string) error {
+	if len(w.opts.Include) > 0 && !match(w.opts.Include, rel) {
+		return nil
+	}
+	return w.fn(o)
+}
+
+func (w *walker) relPath(name string) string {
+	rel := strings.TrimPrefix(name, w.root)
+	return strings.Trim(rel, "/")
+}
+
+func (w *walker) isSkipped(rel string) bool {
+	if w.skippedFileDirs[path.Dir(rel)] {
+		return true
+	}
+	for _, v := range w.skippedDirs {
+		if rel == v || strings.HasPrefix(rel, v+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcluded will check whether object or any of its parent dirs is excluded.
+func (w *walker) isExcluded(rel string) bool {
+	if len(w.opts.Exclude) == 0 {
+		return false
+	}
+	for p := rel; p != "." && p != ""; p = path.Dir(p) {
+		if match(w.opts.Exclude, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// match will check whether rel matches any pattern.
+func match(patterns []string, rel string) bool {
+	for _, v := range patterns {
+		name := rel
+		if !strings.Contains(v, "/") {
+			name = path.Base(rel)
+		}
+		// Patterns have been checked before walk, so error will not happen here.
+		if ok, _ := path.Match(v, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func depth(rel string) int {
+	return strings.Count(rel, "/") + 1
+}
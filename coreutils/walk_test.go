@@ -0,0 +1,260 @@
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/metadata"
+)
+
+// walkStorager is a fake storager built from a list of file paths.
+type walkStorager struct {
+	storage.Storager
+
+	files []string
+	flat  bool
+
+	lock   sync.Mutex
+	listed []string
+}
+
+func (s *walkStorager) MetadataWithContext(ctx context.Context, pairs ...*types.Pair) (metadata.StorageMeta, error) {
+	m := metadata.NewStorageMeta()
+	if s.flat {
+		m.SetFlatList(true)
+	}
+	return m, nil
+}
+
+func (s *walkStorager) ListWithContext(ctx context.Context, dir string, ps ...*types.Pair) error {
+	s.lock.Lock()
+	s.listed = append(s.listed, dir)
+	s.lock.Unlock()
+
+	var dirFunc, fileFunc types.ObjectFunc
+	for _, v := range ps {
+		switch v.Key {
+		case "dir_func":
+			dirFunc = v.Value.(types.ObjectFunc)
+		case "file_func":
+			fileFunc = v.Value.(types.ObjectFunc)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range s.files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if s.flat {
+			if strings.HasPrefix(v, dir) {
+				fileFunc(&types.Object{Name: v, Type: types.ObjectTypeFile})
+			}
+			continue
+		}
+
+		prefix := strings.TrimSuffix(dir, "/") + "/"
+		if dir == "" {
+			prefix = ""
+		}
+		if !strings.HasPrefix(v, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(v, prefix)
+		if idx := strings.Index(rest, "/"); idx != -1 {
+			name := prefix + rest[:idx]
+			if !seen[name] {
+				seen[name] = true
+				dirFunc(&types.Object{Name: name, Type: types.ObjectTypeDir})
+			}
+			continue
+		}
+		fileFunc(&types.Object{Name: v, Type: types.ObjectTypeFile})
+	}
+	return nil
+}
+
+var walkFiles = []string{
+	"a/1.txt",
+	"a/b/2.txt",
+	"a/b/3.go",
+	"a/c/4.txt",
+	"d/5.go",
+	"6.txt",
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   *WalkOptions
+		skip   string
+		expect []string
+	}{
+		{
+			"default",
+			nil,
+			"",
+			[]string{"6.txt", "a/1.txt", "a/b/2.txt", "a/b/3.go", "a/c/4.txt", "d/5.go"},
+		},
+		{
+			"include",
+			&WalkOptions{Include: []string{"*.go"}},
+			"",
+			[]string{"a/b/3.go", "d/5.go"},
+		},
+		{
+			"exclude dir",
+			&WalkOptions{Exclude: []string{"a/b"}},
+			"",
+			[]string{"6.txt", "a/1.txt", "a/c/4.txt", "d/5.go"},
+		},
+		{
+			"max depth",
+			&WalkOptions{MaxDepth: 2},
+			"",
+			[]string{"6.txt", "a/1.txt", "d/5.go"},
+		},
+		{
+			"skip dir",
+			nil,
+			"a/b",
+			[]string{"6.txt", "a/1.txt", "a/c/4.txt", "d/5.go"},
+		},
+	}
+
+	for _, flat := range []bool{true, false} {
+		for _, v := range tests {
+			t.Run(v.name, func(t *testing.T) {
+				store := &walkStorager{files: walkFiles, flat: flat}
+
+				files := make([]string, 0)
+				err := Walk(context.Background(), store, "", func(o *types.Object) error {
+					if o.Type == types.ObjectTypeDir {
+						if o.Name == v.skip {
+							return SkipDir
+						}
+						return nil
+					}
+					// Flat list doesn't return dirs, so we need to skip via file.
+					if path.Dir(o.Name) == v.skip {
+						return SkipDir
+					}
+					files = append(files, o.Name)
+					return nil
+				}, v.opts)
+				assert.NoError(t, err)
+
+				sort.Strings(files)
+				assert.Equal(t, v.expect, files)
+				if flat {
+					assert.Equal(t, []string{""}, store.listed)
+				}
+			})
+		}
+	}
+}
+
+func TestWalk_Sorted(t *testing.T) {
+	var expect []string
+	for i := 0; i < 5; i++ {
+		store := &walkStorager{files: walkFiles}
+
+		names := make([]string, 0)
+		err := Walk(context.Background(), store, "", func(o *types.Object) error {
+			names = append(names, o.Name)
+			return nil
+		}, &WalkOptions{Sorted: true, Concurrency: 8})
+		assert.NoError(t, err)
+
+		if expect == nil {
+			expect = names
+		}
+		assert.Equal(t, expect, names)
+	}
+	assert.Equal(t, []string{"6.txt", "a", "d", "a/1.txt", "a/b", "a/c", "d/5.go", "a/b/2.txt", "a/b/3.go", "a/c/4.txt"}, expect)
+}
+
+func TestWalk_Error(t *testing.T) {
+	expectErr := errors.New("walk error")
+
+	for _, flat := range []bool{true, false} {
+		store := &walkStorager{files: walkFiles, flat: flat}
+
+		count := 0
+		err := Walk(context.Background(), store, "", func(o *types.Object) error {
+			count++
+			return expectErr
+		}, nil)
+		assert.True(t, errors.Is(err, expectErr))
+		assert.Equal(t, 1, count)
+	}
+
+	err := Walk(context.Background(), &walkStorager{}, "", nil, &WalkOptions{Include: []string{"["}})
+	assert.True(t, errors.Is(err, path.ErrBadPattern))
+}
+
+func TestWalk_ErrorWithManyDirs(t *testing.T) {
+	expectErr := errors.New("walk error")
+
+	files := make([]string, 0)
+	for i := 0; i < 20; i++ {
+		for j := 0; j < 20; j++ {
+			files = append(files, fmt.Sprintf("%d/%d/%d.txt", i, j, j))
+		}
+	}
+
+	for concurrency := 2; concurrency <= 4; concurrency++ {
+		for n := 1; n <= 60; n++ {
+			store := &walkStorager{files: files}
+
+			done := make(chan error, 1)
+			go func() {
+				count := 0
+				done <- Walk(context.Background(), store, "", func(o *types.Object) error {
+					count++
+					if count > n {
+						return expectErr
+					}
+					// Yield so that workers could fill up results while fn is running.
+					runtime.Gosched()
+					return nil
+				}, &WalkOptions{Concurrency: concurrency})
+			}()
+
+			select {
+			case err := <-done:
+				assert.True(t, errors.Is(err, expectErr))
+			case <-time.After(5 * time.Second):
+				t.Fatalf("walk with concurrency %d hangs after %d objects", concurrency, n)
+			}
+		}
+	}
+}
+
+func TestWalk_FlatSkipRootFile(t *testing.T) {
+	store := &walkStorager{files: []string{"1.txt", "2.txt", "a/3.txt", "b/4.txt"}, flat: true}
+
+	files := make([]string, 0)
+	err := Walk(context.Background(), store, "", func(o *types.Object) error {
+		files = append(files, o.Name)
+		if o.Name == "1.txt" {
+			return SkipDir
+		}
+		return nil
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.txt", "a/3.txt", "b/4.txt"}, files)
+}
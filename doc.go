@@ -19,7 +19,9 @@ Examples
 
 The most common case to use a Storager service could be following:
 
-1. Init a service.
+1. Import the service and init it.
+
+    import _ "github.com/Xuanwo/storage/services/qingstor"
 
     srv, store, err := coreutils.Open("qingstor://hmac:test_access_key:test_secret_key@https:qingstor.com:443/test_bucket_name")
 	if err != nil {
@@ -1,6 +1,6 @@
 // Code generated by go-bindata. DO NOT EDIT.
 // sources:
-// metadata.tmpl (1.178kB)
+// metadata.tmpl (1.206kB)
 
 package main
 
@@ -69,7 +69,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _metadataTmpl = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb4\x93\x41\x8f\xd3\x30\x10\x85\xef\xfe\x15\x8f\xaa\x87\x44\xea\xc6\x77\x50\x0f\x88\x22\xc4\x81\x5d\x24\x2a\x84\x40\x08\x4d\x93\x69\x88\xe2\xd8\x95\xed\x78\xb5\x64\xf3\xdf\x91\x93\x14\x9a\xdd\xc2\xf6\xb2\x3d\x54\x9e\x49\xde\x97\xf7\x66\x12\x29\xf1\xc6\x14\x8c\x92\x35\x5b\xf2\x5c\x60\x77\x87\xd2\xfc\xa9\x51\x69\xcf\x56\x93\x92\x79\x53\xc8\x86\x3d\x15\xe4\xe9\x15\x36\x37\xb8\xbe\xd9\xe2\xed\xe6\xfd\x36\x13\x07\xca\x6b\x2a\x19\xc7\xcb\x42\x54\xcd\xc1\x58\x8f\x44\x00\xc0\xa2\xac\xfc\xcf\x76\x97\xe5\xa6\x91\x5f\x5a\xd2\xb7\x46\x3a\x6f\x2c\x95\x2c\x0f\x75\x79\x3c\xe7\x8a\x9c\x5b\x88\x54\x88\x40\x16\x3f\x70\xda\xce\xb6\x77\x07\x16\xa2\xeb\xae\xb0\x74\x6c\x43\x95\xf3\x35\x35\x8c\x97\x6b\x64\xc3\xe1\x1e\xde\x7c\x24\x97\x93\x42\xdf\x0b\x21\x25\x5e\x2b\x05\x0a\x54\x29\xda\xa9\xbf\xce\x32\x91\x1b\xed\xa2\xb1\xc8\xb2\xa4\x4b\xc6\xb2\x5e\x61\x19\x06\xd6\x86\x3c\x45\x40\x74\xdd\x75\xf3\x67\xf5\x7d\xec\x84\x6c\x2a\xb0\xc6\x22\x36\x6a\xf4\xfd\x62\xa0\xb1\x2e\xa2\x36\x15\xff\x67\x4b\x89\x77\xec\xe7\xac\xdb\x4a\x29\x94\xec\x71\x24\x22\x90\x6a\x19\x7b\x6b\x9a\x13\xef\xfb\x56\xe7\x48\x9a\x33\xd6\xd2\x47\xcc\x24\x45\x32\x36\xe2\xec\xd0\xf7\x2b\xec\x8c\x51\x29\xd0\x0d\xf1\xc2\x0a\xa6\x8e\xc6\x9a\xac\xf9\xf6\x44\xd6\xef\x83\xa2\xda\xe3\x85\xa9\x27\xf9\x38\xa1\xab\xd8\x5c\x86\xec\x2b\x5b\xf3\x79\x70\x3c\x0d\x2f\xfe\x2c\xfb\xd6\x6a\x8c\xa4\xd3\x3b\x56\xd8\x93\x72\x3c\xe3\xb0\x72\xff\x16\x0f\x09\xee\xf1\xeb\x29\xc6\xb8\x80\x58\x8e\xff\x13\x24\x64\xf3\x49\xa4\x2b\x78\xdb\xb2\x18\x5f\x94\x0f\xad\xf3\xcf\xb1\x90\x73\xdc\x24\xc5\xcc\xc9\x71\x19\x93\xd1\x4b\x56\xf1\x30\xcb\x94\xe2\xd3\xd9\x04\xee\x71\x82\x4a\x7b\x73\x61\x82\x87\xcc\x24\xcc\xdd\xa7\x67\x54\x53\xa0\x4b\x92\x60\x8d\x30\x4b\x2f\xfa\xd3\xef\xe8\x77\x00\x00\x00\xff\xff\x8f\xee\x9b\x75\x9a\x04\x00\x00")
+var _metadataTmpl = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb5\x53\x4d\x6f\xd4\x30\x10\xbd\xfb\x57\x0c\xd1\x1e\x12\x29\xb5\xef\xa0\x1e\x10\x5b\x21\x0e\xb4\x48\xac\x10\x02\xa1\x6a\x36\x99\x0d\x51\xfc\xb1\xb2\x1d\x57\x65\xbb\xff\x1d\x3b\xc9\xc2\xa6\x5d\xda\xbd\x90\x83\x35\x7e\xf1\x7b\x79\x6f\x26\x16\x02\xde\x99\x9a\xa0\x21\x4d\x16\x3d\xd5\xb0\xbe\x87\xc6\xfc\xd9\x43\xab\x3d\x59\x8d\x52\x54\xaa\x16\x8a\x3c\xd6\xe8\xf1\x0d\x2c\x6f\xe0\xfa\x66\x05\x57\xcb\x0f\x2b\xce\xb6\x58\x75\xd8\x10\x1c\x5e\x33\xd6\xaa\xad\xb1\x1e\x72\x06\xf1\xc9\x7c\xab\x28\x63\x63\xdd\xb4\xfe\x67\xbf\xe6\x95\x51\xe2\x6b\x8f\xfa\xce\x08\xe7\x8d\x8d\x6c\xb1\xed\x9a\x43\x5d\x49\x74\x2e\x63\x05\x63\x01\x2d\xdc\xc2\x31\xcc\x57\xf7\x5b\x9a\xf0\x24\xcc\x57\x71\x61\x6c\xb7\xbb\x80\x85\x23\x1b\xda\x8a\xae\x51\x11\xbc\xbe\x04\x3e\x14\x0f\xe0\xcd\x27\x74\x15\x4a\xd8\xef\x19\x13\x02\xde\x4a\x09\x18\xb0\x95\xb8\x96\x7f\x5d\x73\x56\x19\xed\x92\xe9\xa4\x65\x51\xc7\x44\x8b\xae\x84\x45\x18\xb4\x96\xf1\x48\x12\x48\x29\x76\xbb\xf9\xb7\xf6\xfb\x84\x04\x3e\x6d\xe0\x12\xb2\x04\x74\xb1\xce\x06\x35\xd2\x75\xe2\x16\xec\x79\xed\xe8\xed\x3d\xf9\xb9\xd6\x5d\x1b\xdd\x36\xe4\xe1\xa0\x08\x01\x65\x4f\xb0\xb1\x46\x1d\x79\xdf\xf4\xba\x82\x5c\x9d\xb0\x56\x3c\xd1\xcc\x0b\xc8\x47\x20\xf5\x32\x02\x25\xac\x8d\x91\x45\x0c\x36\xc4\x0b\x25\x98\x2e\x19\x53\x5c\x7d\x7f\x21\xeb\x8f\x81\xd1\x6e\xe0\x55\xa4\x8c\xf4\xb1\x43\x17\x09\x8c\xe7\xbe\x91\x35\x5f\x06\xc7\x53\xf3\xd2\x63\xc9\xf7\x56\xc3\xa8\x74\x7c\xa2\x84\x0d\x4a\x47\x33\x1d\x8a\xc0\x3f\xc9\x43\x82\x07\xf8\xf5\x92\xc6\x38\x80\xb4\x1d\xd7\x49\x24\xf0\x79\x27\x8a\x12\xbc\xed\x89\x8d\x3f\xca\xc7\xde\xf9\xff\x31\x90\x53\xba\x71\x28\x33\x27\x87\x61\x4c\x46\xcf\x19\xc5\xe3\x2c\x53\x8a\xcf\x27\x13\xb8\xa7\x09\xe2\x5d\x37\x67\x26\x78\xac\x99\x87\xb9\xfb\xe2\x04\x6b\x0a\x74\x4e\x92\x78\x83\xc2\x2c\x7d\x8c\x72\x34\xc6\xdf\x13\xd4\x66\xbc\xb6\x04\x00\x00")
 
 func metadataTmplBytes() ([]byte, error) {
 	return bindataRead(
@@ -85,7 +85,7 @@ func metadataTmpl() (*asset, error) {
 	}
 
 	info := bindataFileInfo{name: "metadata.tmpl", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xda, 0x95, 0xfa, 0xfe, 0x82, 0x1d, 0xd, 0x87, 0x8b, 0xc3, 0x2b, 0x2f, 0x7f, 0x7b, 0x32, 0xe8, 0x9, 0x74, 0x69, 0x73, 0x6d, 0x7b, 0xb5, 0xfa, 0xa7, 0xff, 0xd7, 0x7d, 0x84, 0x58, 0xee, 0xba}}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfd, 0x4e, 0x35, 0x8d, 0x30, 0x52, 0xbe, 0xc8, 0xc3, 0xa, 0x6a, 0x15, 0x9d, 0x70, 0x5e, 0x9d, 0xba, 0xe2, 0xe4, 0x8d, 0x10, 0xde, 0xfa, 0xb4, 0x62, 0x9c, 0xb3, 0x91, 0xb9, 0x6a, 0x7b, 0xf9}}
 	return a, nil
 }
 
@@ -1,6 +1,6 @@
 // Code generated by go-bindata. DO NOT EDIT.
 // sources:
-// pair.tmpl (715B)
+// pair.tmpl (726B)
 // sf-kjlqsc36tch53jrf.tmp (8.29kB)
 
 package main
@@ -70,7 +70,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _pairTmpl = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x50\x4d\x8f\xd3\x30\x10\xbd\xfb\x57\x3c\x45\x15\xda\xa2\x25\xbe\x17\xed\x01\x51\x0e\x08\x89\xee\x61\x05\x5c\xa7\xce\x90\xb5\xe2\xda\x96\x3d\xc9\x52\x05\xff\x77\xe4\x04\xca\xc7\x81\xad\x4f\x9e\xf7\xde\xbc\x99\x79\x5a\xe3\x6d\xe8\x18\x3d\x7b\x4e\x24\xdc\xe1\x78\x46\x1f\x2e\x35\xac\x17\x4e\x9e\x9c\x36\xa7\x4e\x47\xb2\x29\xbf\xc6\xfe\x80\x8f\x87\x07\xbc\xdb\xbf\x7f\x68\x55\x24\x33\x50\xcf\x58\x38\xa5\xec\x29\x86\x24\xb8\x51\x00\xd0\x98\xe0\x85\xbf\x49\xa3\xd6\xb2\xb7\xf2\x38\x1e\x5b\x13\x4e\xfa\xcb\x48\xfe\x29\xe8\x2c\x21\x51\xcf\xcd\x33\xbc\x8e\x43\xaf\x33\xf7\x27\xf6\x72\x95\x96\x7d\x17\x83\xbd\x52\x6c\x12\x77\xec\xc5\x92\xbb\x6e\x8f\xf5\x6f\x1c\xe5\xfc\x6c\x83\x9c\x23\xe7\x46\x6d\x95\xd2\x1a\x6f\x9c\x03\x4d\x64\x1d\x1d\xdd\xcf\xc4\x5a\x65\x82\xcf\x35\xb0\x79\x7e\x85\x44\xbe\x67\x6c\x86\x5b\x6c\x26\xec\xee\xd0\xee\x49\x08\xa5\x2c\x53\xe6\x19\x9b\x01\xdf\x21\xe1\x9e\xb2\x21\x87\x52\x70\x87\x66\x85\x4b\x69\x16\x07\xf6\x5d\xd5\x6f\xd5\xff\xfd\x2a\xb9\x89\x43\xc5\xfe\xf5\xac\x8b\x7e\xb6\xf2\x58\x7d\x63\x35\xc6\x93\xad\x7b\xc7\xe8\xce\xf8\x35\x0c\x13\xb9\x91\x21\x01\x87\x28\x36\xf8\xac\xbe\x8e\xde\xfc\xdd\x77\x33\x2d\xf2\x09\xa5\x6c\xf1\x72\x09\xa2\xbd\x27\x9b\x30\x2f\xe7\x24\x96\x31\x79\xbc\xf8\x4d\xac\x78\x7d\x1f\xf8\xbc\xc3\xc5\xe8\xf6\x82\x7f\xaa\x53\x77\x98\x56\xa4\xa8\xf2\xc7\xcd\x3f\x02\x00\x00\xff\xff\xef\xa9\x77\x96\xcb\x02\x00\x00")
+var _pairTmpl = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\x50\xb1\x4e\xc3\x30\x10\xdd\xfd\x15\xa7\xa8\x42\x2d\x2a\xf1\x5e\xd4\x01\x51\x06\x84\x44\x3b\x54\xc0\x7a\x75\x4c\xb0\xe2\xd8\x96\xe3\xa4\x44\x21\xff\xce\x39\x81\x42\x19\x68\x3d\x9d\xdf\x7b\xf7\xee\xde\x71\x0e\xb7\x36\x93\x90\x4b\x23\x3d\x06\x99\xc1\xae\x85\xdc\x1e\xfe\xa0\x4c\x90\xde\xa0\xe6\xa2\xcc\xb8\x43\xe5\xab\x6b\x58\xad\xe1\x71\xbd\x85\xbb\xd5\xfd\x36\x65\x0e\x45\x81\xb9\x84\x81\x63\x4c\x95\xce\xfa\x00\x53\x06\xf4\x12\x61\xa9\xfd\x3d\x24\xe3\x2f\xa8\x52\x26\x6c\xac\x73\x15\xde\xea\x5d\x2a\x6c\xc9\x5f\x6a\x34\x7b\xcb\xab\x60\x3d\x19\x25\x27\x78\xee\x8a\x9c\x57\x32\x2f\xa5\x09\x67\x69\xa5\xc9\x9c\x55\x67\x8a\x85\x97\x19\x19\x2b\xd4\xe7\xed\x31\xd6\x42\x63\x55\x9d\x6c\x08\xad\x93\xa4\x9a\x31\xc6\x39\xdc\x68\x0d\xd8\xa0\xd2\xb8\xd3\x5f\xc7\x4b\x19\x9d\xab\x8a\xb7\xeb\xba\x2b\xf0\x68\xe8\xaa\x93\x62\x0e\x93\x06\x16\x4b\x48\x57\x18\x10\xfa\x7e\x98\xd2\x75\xc4\xc0\x07\x04\xbb\xc1\x4a\xa0\x26\x1c\x96\x90\x8c\x70\xdf\x27\x83\x03\x05\x8f\x7a\x9a\xf7\xaf\x5f\x24\x27\xae\x88\xd8\x5f\xcf\xb8\xe8\x33\xe5\x89\xbe\x2e\x1a\xc3\x5e\xc5\xbd\x9d\xd3\x2d\x7c\x0f\x83\x06\x75\x2d\xa9\x0d\xd6\x2e\x28\x4a\xc0\x5e\x6b\x23\x8e\xfb\xa6\xcd\x20\x6f\xa8\x9c\xc1\xe5\x70\x88\x74\x43\x99\xa1\x1b\xe2\x78\x19\x6a\x6f\xe0\xe2\x87\x18\xf1\xf8\x1e\x64\xbb\x80\x83\xd1\xfc\x80\x3f\xc5\xa9\x0b\x68\x46\xa4\x67\xfd\xaf\xcc\x9f\x74\x64\x03\x8e\xd6\x02\x00\x00")
 
 func pairTmplBytes() ([]byte, error) {
 	return bindataRead(
@@ -86,7 +86,7 @@ func pairTmpl() (*asset, error) {
 	}
 
 	info := bindataFileInfo{name: "pair.tmpl", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x49, 0xfe, 0x56, 0x85, 0xdb, 0x90, 0x73, 0x96, 0xc8, 0xde, 0x5d, 0x23, 0x1, 0x79, 0x9a, 0x97, 0xf3, 0x19, 0xc8, 0x79, 0x37, 0xdc, 0x32, 0x35, 0x77, 0x11, 0xef, 0xb0, 0x14, 0x27, 0x36, 0xd5}}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x35, 0x63, 0x53, 0xd9, 0xd5, 0x62, 0x6c, 0x89, 0x88, 0xab, 0x4b, 0xc, 0x8c, 0xaa, 0x5c, 0x2, 0x69, 0xa0, 0xc4, 0xf2, 0xdd, 0x6a, 0xaf, 0xdc, 0xb, 0xf6, 0x8c, 0x98, 0x5b, 0xd7, 0x2, 0x63}}
 	return a, nil
 }
 
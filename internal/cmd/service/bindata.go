@@ -1,7 +1,7 @@
 // Code generated by go-bindata. DO NOT EDIT.
 // sources:
 // tmpl/context.tmpl (542B)
-// tmpl/header.tmpl (642B)
+// tmpl/header.tmpl (748B)
 // tmpl/meta.tmpl (63B)
 // tmpl/pairs.tmpl (1.342kB)
 
@@ -92,7 +92,7 @@ func contextTmpl() (*asset, error) {
 	return a, nil
 }
 
-var _headerTmpl = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x90\xbb\x6e\xf3\x30\x0c\x85\x77\x3d\x05\xe1\xe9\xff\x87\x4a\x0f\xd0\xb1\xe9\xd0\x25\x29\x10\x0f\xdd\x0a\x46\x22\x54\x21\xd6\x05\x14\xe3\xd6\x08\xf2\xee\x05\x2a\xa7\x37\x14\xb0\x27\x1e\x1e\x7e\xba\xf0\x18\x03\x77\xd9\x11\x78\x4a\xc4\x28\xe4\xe0\x30\x81\xcf\x9f\x3d\x8c\x01\x21\x24\x21\x4e\x38\x18\x1b\x9d\xa9\xc4\x63\xb0\x74\x0b\x9b\x1d\x6c\x77\x3d\xdc\x6f\x1e\x7a\xad\x0a\xda\x23\x7a\x82\xf3\x19\xf4\x16\x23\xc1\xe5\xa2\x54\x88\x25\xb3\xc0\x3f\x05\x00\xd0\xd9\x9c\x84\xde\xa4\x6b\x5d\xc8\x9d\x6a\xca\x07\x79\x39\x1d\xb4\xcd\xd1\xe4\x42\x49\x18\x6d\x48\xfe\xbb\xbe\xf1\x7f\xc1\x4f\x27\x4c\xaf\xd9\x54\xc9\x8c\x9e\xba\x85\xb9\x29\x47\x6f\x2a\xf9\x48\x49\x56\xb1\x94\x5c\xc9\x61\x25\x6c\x99\x1c\x25\x09\x38\xac\xfb\x47\xd3\x76\xc0\x5a\x17\x0f\xc8\x54\x68\xa6\x4a\x5d\x04\x4d\xc1\xc0\x2b\x2f\x35\x91\x04\x1d\x0a\x76\xea\xbf\x52\x23\x32\x3c\xc3\xd7\x26\xfa\x91\xf3\x18\x1c\xf1\x3c\xb9\x06\xf2\xdb\x9f\x43\xd5\xfb\x56\xaf\x6e\x7b\x48\xef\x5b\xe5\x9f\xf6\xc7\xe6\xba\x9f\x0a\xa9\xf7\x00\x00\x00\xff\xff\x53\xe8\xbb\x29\x82\x02\x00\x00")
+var _headerTmpl = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\x52\xbb\x4e\x03\x31\x10\xec\xfd\x15\xab\xab\xa0\xc0\xfe\x00\x4a\x42\x91\x26\x41\x4a\x0a\x3a\xb4\xb1\x57\x66\x21\xf6\x5a\x3e\xe7\x20\x8a\xf2\xef\x38\x9c\xc3\x4b\x48\x39\x37\xfb\x1a\x8f\x66\xc7\x36\x06\xee\xc4\x11\x78\x8a\x94\xb1\x90\x83\xcd\x1e\xbc\x7c\xd5\x30\x30\x02\xc7\x42\x39\xe2\xd6\xd8\xe0\x4c\x4f\x79\x60\x4b\xb7\x30\x5b\xc2\x62\xb9\x86\xfb\xd9\x7c\xad\x55\x42\xfb\x8a\x9e\xe0\x70\x00\xbd\xc0\x40\x70\x3c\x2a\xc5\x21\x49\x2e\x70\xa5\xa0\x9e\xce\x4a\xa5\x79\x2f\xdd\x58\xb1\xb4\xa4\x70\xa0\x4e\x8d\xb9\xe7\xf2\xbc\xdb\x68\x2b\xc1\x48\xa2\x58\x32\x5a\x8e\xfe\x67\x7e\xe3\xe5\x1f\xf0\xe3\x0e\xe3\x9b\x98\xbe\x48\xae\x22\xba\x0b\x73\x93\x5e\x7d\x5d\xc3\x87\xca\x3a\x09\x4b\xd1\x25\xe1\x89\x60\x2e\x27\xe3\x24\x4f\x02\xdb\x4c\xae\xaa\x60\xdc\x4e\x13\x3d\xe6\x76\x8b\x7d\x7f\xf1\x42\xd9\x27\x6a\xa8\xd4\x5f\x04\x9a\x84\x9c\x27\x92\x9a\x40\x05\x1d\x16\xec\xd4\xb5\x52\x03\x66\x78\x82\xef\x4d\xf4\x43\x96\x81\x1d\xe5\x36\x39\xbb\xf7\xb7\x7f\x36\x4a\x2f\x37\x2f\x64\xcb\xbc\x95\x6d\xda\xde\x47\xaf\xc6\x78\xee\x8e\x32\xf4\x6a\x8c\xf9\x77\xfb\xd3\x17\xbd\xae\x12\x5b\xff\xf4\xb7\xf4\x6c\x57\x79\x59\xa2\xfa\x00\xeb\xc2\x95\xb5\xec\x02\x00\x00")
 
 func headerTmplBytes() ([]byte, error) {
 	return bindataRead(
@@ -108,7 +108,7 @@ func headerTmpl() (*asset, error) {
 	}
 
 	info := bindataFileInfo{name: "header.tmpl", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x9f, 0xdc, 0x74, 0x26, 0xca, 0x7e, 0x88, 0x55, 0x8c, 0x41, 0x43, 0x4d, 0xb4, 0x47, 0x7f, 0xdd, 0xfe, 0x43, 0xff, 0xa0, 0x20, 0x9b, 0xef, 0xa, 0x49, 0x24, 0x27, 0x32, 0xb2, 0xb6, 0x1b, 0x26}}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xbb, 0x11, 0xc, 0x12, 0x3e, 0xe0, 0xeb, 0x8e, 0x17, 0x2, 0x81, 0xd, 0x82, 0x8e, 0x7a, 0x59, 0x36, 0xdd, 0xd2, 0x9e, 0xc5, 0x59, 0x8a, 0xa4, 0xb6, 0x89, 0xf5, 0x34, 0x8a, 0x35, 0x7a, 0x2f}}
 	return a, nil
 }
 
@@ -138,6 +138,10 @@ func formatExpr(t ast.Expr) string {
 		return "*" + formatExpr(v.X)
 	case *ast.Ellipsis:
 		return "..." + formatExpr(v.Elt)
+	case *ast.ArrayType:
+		return "[]" + formatExpr(v.Elt)
+	case *ast.MapType:
+		return "map[" + formatExpr(v.Key) + "]" + formatExpr(v.Value)
 	default:
 		println(fmt.Sprintf("not handled type %+#v", v))
 		return ""
@@ -0,0 +1,173 @@
+package checksum
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/metadata"
+)
+
+var (
+	// ErrUnsupportedType will return if checksum type is unsupported.
+	ErrUnsupportedType = errors.New("unsupported checksum type")
+)
+
+// Type is the type for checksum algorithm.
+type Type string
+
+// All available checksum types.
+const (
+	// MD5 is the md5 checksum, maps to metadata content-md5.
+	MD5 Type = "md5"
+	// CRC32C is the crc32 checksum with Castagnoli polynomial, maps to metadata content-crc32c.
+	CRC32C Type = "crc32c"
+	// SHA256 is the sha256 checksum, maps to metadata content-sha256.
+	SHA256 Type = "sha256"
+	// DropboxContentHash is the dropbox content hash, maps to metadata dropbox-content-hash.
+	//
+	// ref: https://www.dropbox.com/developers/reference/content-hash
+	DropboxContentHash Type = "dropbox-content-hash"
+	// QiniuETag is the qiniu etag, maps to metadata qiniu-etag.
+	//
+	// ref: https://developer.qiniu.com/kodo/manual/1231/appendix#qiniu-etag
+	QiniuETag Type = "qiniu-etag"
+)
+
+// New will create a new hash.Hash for checksum type.
+func New(t Type) (hash.Hash, error) {
+	switch t {
+	case MD5:
+		return md5.New(), nil
+	case CRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), nil
+	case SHA256:
+		return sha256.New(), nil
+	case DropboxContentHash:
+		return newDropboxContentHash(), nil
+	case QiniuETag:
+		return newQiniuETag(), nil
+	default:
+		return nil, fmt.Errorf("new checksum [%s]: %w", t, ErrUnsupportedType)
+	}
+}
+
+// Pick will pick the strongest checksum from object metadata.
+//
+// All checksum in metadata should be lower case hex encoded.
+func Pick(m metadata.ObjectMeta) (t Type, value string, ok bool) {
+	if v, ok := m.GetContentSHA256(); ok && v != "" {
+		return SHA256, v, true
+	}
+	if v, ok := m.GetDropboxContentHash(); ok && v != "" {
+		return DropboxContentHash, v, true
+	}
+	if v, ok := m.GetQiniuETag(); ok && v != "" {
+		return QiniuETag, v, true
+	}
+	if v, ok := m.GetContentMD5(); ok && v != "" {
+		return MD5, v, true
+	}
+	if v, ok := m.GetContentCRC32C(); ok && v != "" {
+		return CRC32C, v, true
+	}
+	return "", "", false
+}
+
+// md5ETagRegexp matches etag which is the md5 of whole object.
+var md5ETagRegexp = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// multipartETagRegexp matches etag which is computed by multipart upload: md5 of parts' md5 with parts count.
+var multipartETagRegexp = regexp.MustCompile(`^[0-9a-f]{32}-\d+$`)
+
+// FromETag will set content-md5 or multipart-etag in metadata via etag.
+//
+// Most object storage services return md5 as etag for simple uploaded object, and
+// `<md5 of parts' md5>-<parts count>` for multipart uploaded object.
+// ETag in other format will be ignored.
+//
+// ETag of server side encrypted objects (like SSE-KMS or SSE-C) is not md5 of content even
+// if it looks like one, callers should not call FromETag for them. List results don't
+// carry encryption info, so services could only skip them in Stat and Read.
+func FromETag(m metadata.ObjectMeta, etag string) {
+	etag = strings.ToLower(strings.Trim(etag, "\""))
+
+	switch {
+	case md5ETagRegexp.MatchString(etag):
+		m.SetContentMD5(etag)
+	case multipartETagRegexp.MatchString(etag):
+		m.SetMultipartETag(etag)
+	}
+}
+
+// FormatBytes will format checksum bytes returned by service into hex encoded string.
+func FormatBytes(b []byte) string {
+	return hex.EncodeToString(b)
+}
+
+// FormatCRC32C will format crc32c value returned by service into hex encoded string.
+func FormatCRC32C(v uint32) string {
+	return fmt.Sprintf("%08x", v)
+}
+
+// NewVerifyReadCloser will create a ReadCloser which verifies content with the strongest checksum in metadata.
+//
+// Read will return types.ErrChecksumMismatch instead of io.EOF while all data read but checksum mismatch.
+// If there is no available checksum in metadata, types.ErrChecksumNotAvailable will be returned.
+func NewVerifyReadCloser(r io.ReadCloser, m metadata.ObjectMeta) (io.ReadCloser, error) {
+	const errorMessage = "new verify reader: %w"
+
+	t, expected, ok := Pick(m)
+	if !ok {
+		return nil, fmt.Errorf(errorMessage, types.ErrChecksumNotAvailable)
+	}
+	h, err := New(t)
+	if err != nil {
+		return nil, fmt.Errorf(errorMessage, err)
+	}
+	return &VerifiedReadCloser{
+		r:        r,
+		h:        h,
+		t:        t,
+		expected: strings.ToLower(expected),
+	}, nil
+}
+
+// VerifiedReadCloser will verify checksum while all data read.
+type VerifiedReadCloser struct {
+	r        io.ReadCloser
+	h        hash.Hash
+	t        Type
+	expected string
+}
+
+// Read will read data from underlying reader and verify checksum at EOF.
+func (v *VerifiedReadCloser) Read(p []byte) (n int, err error) {
+	n, err = v.r.Read(p)
+	if n > 0 {
+		// hash.Hash's Write never returns an error.
+		_, _ = v.h.Write(p[:n])
+	}
+	if err != io.EOF {
+		return
+	}
+
+	actual := hex.EncodeToString(v.h.Sum(nil))
+	if actual != v.expected {
+		return n, fmt.Errorf("%s expected %s, actual %s: %w", v.t, v.expected, actual, types.ErrChecksumMismatch)
+	}
+	return
+}
+
+// Close will close underlying reader.
+func (v *VerifiedReadCloser) Close() error {
+	return v.r.Close()
+}
@@ -0,0 +1,176 @@
+package checksum
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/metadata"
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name     string
+		t        Type
+		content  string
+		expected string
+		err      error
+	}{
+		{"md5", MD5, "hello", "5d41402abc4b2a76b9719d911017c592", nil},
+		{"crc32c", CRC32C, "hello", "9a71bb4c", nil},
+		{"sha256", SHA256, "hello", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", nil},
+		{"not supported", Type("xxx"), "", "", ErrUnsupportedType},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := New(tt.t)
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err))
+				return
+			}
+			assert.NoError(t, err)
+
+			_, _ = h.Write([]byte(tt.content))
+			assert.Equal(t, tt.expected, FormatBytes(h.Sum(nil)))
+		})
+	}
+}
+
+func TestDropboxContentHash(t *testing.T) {
+	blockHash := func(b []byte) []byte {
+		h := sha256.Sum256(b)
+		return h[:]
+	}
+
+	content := bytes.Repeat([]byte("a"), dropboxBlockSize+1)
+	expected := sha256.Sum256(append(
+		blockHash(content[:dropboxBlockSize]),
+		blockHash(content[dropboxBlockSize:])...,
+	))
+
+	h, err := New(DropboxContentHash)
+	assert.NoError(t, err)
+
+	// Write in small pieces to make sure blocks are split correctly.
+	for i := 0; i < len(content); i += 1000 {
+		end := i + 1000
+		if end > len(content) {
+			end = len(content)
+		}
+		_, _ = h.Write(content[i:end])
+	}
+	assert.Equal(t, FormatBytes(expected[:]), FormatBytes(h.Sum(nil)))
+
+	h.Reset()
+	empty := sha256.Sum256(nil)
+	assert.Equal(t, FormatBytes(empty[:]), FormatBytes(h.Sum(nil)))
+}
+
+func TestQiniuETag(t *testing.T) {
+	blockHash := func(b []byte) []byte {
+		h := sha1.Sum(b)
+		return h[:]
+	}
+
+	h, err := New(QiniuETag)
+	assert.NoError(t, err)
+
+	// Empty content's etag is well known.
+	expected, err := base64.URLEncoding.DecodeString("Fto5o-5ea0sNMlW_75VgGJCv2AcJ")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatBytes(expected), FormatBytes(h.Sum(nil)))
+
+	content := bytes.Repeat([]byte("a"), qiniuBlockSize)
+	_, _ = h.Write(content)
+	assert.Equal(t, FormatBytes(append([]byte{0x16}, blockHash(content)...)), FormatBytes(h.Sum(nil)))
+
+	_, _ = h.Write([]byte("a"))
+	overall := sha1.Sum(append(blockHash(content), blockHash([]byte("a"))...))
+	assert.Equal(t, FormatBytes(append([]byte{0x96}, overall[:]...)), FormatBytes(h.Sum(nil)))
+}
+
+func TestFromETag(t *testing.T) {
+	cases := []struct {
+		name      string
+		etag      string
+		md5       string
+		multipart string
+	}{
+		{"md5", `"5D41402ABC4B2A76B9719D911017C592"`, "5d41402abc4b2a76b9719d911017c592", ""},
+		{"multipart", `"5d41402abc4b2a76b9719d911017c592-12"`, "", "5d41402abc4b2a76b9719d911017c592-12"},
+		{"other", "FvHmjq4EK9WUIxfJ_ZwYm0dXFOXF", "", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metadata.NewObjectMeta()
+			FromETag(m, tt.etag)
+
+			v, _ := m.GetContentMD5()
+			assert.Equal(t, tt.md5, v)
+			v, _ = m.GetMultipartETag()
+			assert.Equal(t, tt.multipart, v)
+		})
+	}
+}
+
+func TestPick(t *testing.T) {
+	m := metadata.NewObjectMeta()
+	_, _, ok := Pick(m)
+	assert.False(t, ok)
+
+	m.SetContentCRC32C("9a71bb4c")
+	typ, v, ok := Pick(m)
+	assert.True(t, ok)
+	assert.Equal(t, CRC32C, typ)
+	assert.Equal(t, "9a71bb4c", v)
+
+	m.SetContentMD5("5d41402abc4b2a76b9719d911017c592")
+	typ, _, _ = Pick(m)
+	assert.Equal(t, MD5, typ)
+
+	m.SetContentSHA256("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+	typ, _, _ = Pick(m)
+	assert.Equal(t, SHA256, typ)
+}
+
+func TestNewVerifyReadCloser(t *testing.T) {
+	cases := []struct {
+		name string
+		md5  string
+		err  error
+	}{
+		{"match", "5d41402abc4b2a76b9719d911017c592", nil},
+		{"mismatch", "00000000000000000000000000000000", types.ErrChecksumMismatch},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metadata.NewObjectMeta()
+			m.SetContentMD5(tt.md5)
+
+			r, err := NewVerifyReadCloser(ioutil.NopCloser(bytes.NewBufferString("hello")), m)
+			assert.NoError(t, err)
+
+			content, err := ioutil.ReadAll(r)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, "hello", string(content))
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+			assert.NoError(t, r.Close())
+		})
+	}
+
+	_, err := NewVerifyReadCloser(ioutil.NopCloser(&bytes.Buffer{}), metadata.NewObjectMeta())
+	assert.True(t, errors.Is(err, types.ErrChecksumNotAvailable))
+}
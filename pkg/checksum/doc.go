@@ -0,0 +1,7 @@
+/*
+Package checksum provided checksum support for storage.
+
+All checksums in object metadata are lower case hex encoded, so that checksums returned by different services could be
+compared with each other directly.
+*/
+package checksum
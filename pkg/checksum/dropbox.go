@@ -0,0 +1,70 @@
+package checksum
+
+import (
+	"crypto/sha256"
+	"hash"
+)
+
+// dropboxBlockSize is the block size used by dropbox content hash.
+const dropboxBlockSize = 4 * 1024 * 1024
+
+// dropboxContentHash implements hash.Hash for dropbox content hash.
+//
+// Content will be split into 4MB blocks, every block will be hashed with sha256,
+// and the content hash is the sha256 of all blocks' hash concatenated.
+type dropboxContentHash struct {
+	// blocks is the concatenated hash of all finished blocks.
+	blocks []byte
+	block  hash.Hash
+	// written is the size of data written in current block.
+	written int
+}
+
+func newDropboxContentHash() *dropboxContentHash {
+	return &dropboxContentHash{
+		block: sha256.New(),
+	}
+}
+
+func (d *dropboxContentHash) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		l := dropboxBlockSize - d.written
+		if l > len(p) {
+			l = len(p)
+		}
+		_, _ = d.block.Write(p[:l])
+		d.written += l
+		n += l
+		p = p[l:]
+
+		if d.written == dropboxBlockSize {
+			d.blocks = d.block.Sum(d.blocks)
+			d.block.Reset()
+			d.written = 0
+		}
+	}
+	return n, nil
+}
+
+func (d *dropboxContentHash) Sum(b []byte) []byte {
+	overall := sha256.New()
+	_, _ = overall.Write(d.blocks)
+	if d.written > 0 {
+		_, _ = overall.Write(d.block.Sum(nil))
+	}
+	return overall.Sum(b)
+}
+
+func (d *dropboxContentHash) Reset() {
+	d.blocks = nil
+	d.block.Reset()
+	d.written = 0
+}
+
+func (d *dropboxContentHash) Size() int {
+	return sha256.Size
+}
+
+func (d *dropboxContentHash) BlockSize() int {
+	return sha256.BlockSize
+}
@@ -0,0 +1,77 @@
+package checksum
+
+import (
+	"crypto/sha1"
+	"hash"
+)
+
+// qiniuBlockSize is the block size used by qiniu etag.
+const qiniuBlockSize = 4 * 1024 * 1024
+
+// qiniuETag implements hash.Hash for qiniu etag.
+//
+// Content will be split into 4MB blocks, every block will be hashed with sha1.
+// For content with only one block, the etag is 0x16 followed by the block's hash,
+// otherwise it's 0x96 followed by the sha1 of all blocks' hash concatenated.
+//
+// ref: https://github.com/qiniu/qetag
+type qiniuETag struct {
+	// blocks is the concatenated hash of all finished blocks.
+	blocks []byte
+	block  hash.Hash
+	// written is the size of data written in current block.
+	written int
+}
+
+func newQiniuETag() *qiniuETag {
+	return &qiniuETag{
+		block: sha1.New(),
+	}
+}
+
+func (q *qiniuETag) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		l := qiniuBlockSize - q.written
+		if l > len(p) {
+			l = len(p)
+		}
+		_, _ = q.block.Write(p[:l])
+		q.written += l
+		n += l
+		p = p[l:]
+
+		if q.written == qiniuBlockSize {
+			q.blocks = q.block.Sum(q.blocks)
+			q.block.Reset()
+			q.written = 0
+		}
+	}
+	return n, nil
+}
+
+func (q *qiniuETag) Sum(b []byte) []byte {
+	blocks := q.blocks
+	if q.written > 0 || len(blocks) == 0 {
+		blocks = q.block.Sum(blocks[:len(blocks):len(blocks)])
+	}
+
+	if len(blocks) == sha1.Size {
+		return append(append(b, 0x16), blocks...)
+	}
+	overall := sha1.Sum(blocks)
+	return append(append(b, 0x96), overall[:]...)
+}
+
+func (q *qiniuETag) Reset() {
+	q.blocks = nil
+	q.block.Reset()
+	q.written = 0
+}
+
+func (q *qiniuETag) Size() int {
+	return 1 + sha1.Size
+}
+
+func (q *qiniuETag) BlockSize() int {
+	return sha1.BlockSize
+}
@@ -18,14 +18,17 @@ var (
 
 // Parse will parse config string and return service type and namespace.
 //
-// TODO: Options didn't support for now.
+// Options in "?key=value&key=value" will be converted into pairs with the type declared
+// in types/pairs/pairs.json, ErrInvalidConfig will be returned for unknown key or invalid value.
 func Parse(cfg string) (t, namespace string, opt []*types.Pair, err error) {
 	errorMessage := "parse config [%s]: <%w>"
+	// Secrets should never be included in error.
+	redacted := Redact(cfg)
 
 	// Parse type from: "<type>://<config>"
 	s := strings.Split(cfg, "://")
 	if len(s) != 2 || s[0] == "" || s[1] == "" {
-		err = fmt.Errorf(errorMessage, cfg, ErrInvalidConfig)
+		err = fmt.Errorf(errorMessage, redacted, ErrInvalidConfig)
 		return
 	}
 	t = s[0]
@@ -38,20 +41,20 @@ func Parse(cfg string) (t, namespace string, opt []*types.Pair, err error) {
 		// Split <credential>@<endpoint> into tow parts.
 		ce := strings.Split(s[0], "@")
 		if len(ce) == 0 || len(ce) > 2 {
-			return "", "", nil, fmt.Errorf(errorMessage, cfg, ErrInvalidConfig)
+			return "", "", nil, fmt.Errorf(errorMessage, redacted, ErrInvalidConfig)
 		}
 
 		// We always have credential part
-		cred, err := credential.Parse(ce[0])
+		cred, err := credential.ParseProvider(ce[0])
 		if err != nil {
-			return "", "", nil, fmt.Errorf(errorMessage, cfg, err)
+			return "", "", nil, fmt.Errorf(errorMessage, redacted, err)
 		}
 		opt = append(opt, pairs.WithCredential(cred))
 
 		if len(ce) == 2 {
 			end, err := endpoint.Parse(ce[1])
 			if err != nil {
-				return "", "", nil, fmt.Errorf(errorMessage, cfg, err)
+				return "", "", nil, fmt.Errorf(errorMessage, redacted, err)
 			}
 			opt = append(opt, pairs.WithEndpoint(end))
 		}
@@ -63,6 +66,10 @@ func Parse(cfg string) (t, namespace string, opt []*types.Pair, err error) {
 		// We don't have options, return directly.
 		return
 	}
-	// TODO: handle options.
+	opts, err := parseOptions(s[1])
+	if err != nil {
+		return "", "", nil, fmt.Errorf(errorMessage, redacted, err)
+	}
+	opt = append(opt, opts...)
 	return
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/pairs"
 	"github.com/stretchr/testify/assert"
@@ -38,11 +39,58 @@ func TestParse(t *testing.T) {
 		},
 		{
 			"no credential, endpoint, but with options",
-			"posixfs:///path?test_key=test_value",
+			"posixfs:///path?work_dir=/tmp&part_size=1024",
 			"posixfs",
 			"path",
+			[]*types.Pair{
+				pairs.WithWorkDir("/tmp"),
+				pairs.WithPartSize(1024),
+			},
+			nil,
+		},
+		{
+			"options with storage class",
+			"s3://env/bucket/prefix?location=us-east-1&storage_class=warm",
+			"s3",
+			"bucket/prefix",
+			[]*types.Pair{
+				pairs.WithCredential(credential.MustNewEnv()),
+				pairs.WithLocation("us-east-1"),
+				pairs.WithStorageClass(storageclass.Warm),
+			},
+			nil,
+		},
+		{
+			"unknown option",
+			"posixfs:///path?test_key=test_value",
+			"",
+			"",
+			nil,
+			ErrInvalidConfig,
+		},
+		{
+			"invalid option value",
+			"posixfs:///path?part_size=abc",
+			"",
+			"",
 			nil,
+			ErrInvalidConfig,
+		},
+		{
+			"invalid storage class",
+			"posixfs:///path?storage_class=xxx",
+			"",
+			"",
+			nil,
+			ErrInvalidConfig,
+		},
+		{
+			"duplicated option",
+			"posixfs:///path?location=a&location=b",
+			"",
+			"",
 			nil,
+			ErrInvalidConfig,
 		},
 		{
 			"no endpoint, but with credential and options",
@@ -65,6 +113,17 @@ func TestParse(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			"ipv6 endpoint with default port",
+			"s3://env@http:[::1]/bucket",
+			"s3",
+			"bucket",
+			[]*types.Pair{
+				pairs.WithCredential(credential.MustNewEnv()),
+				pairs.WithEndpoint(endpoint.NewHTTP("::1", 80)),
+			},
+			nil,
+		},
 	}
 
 	for _, tt := range cases {
@@ -81,3 +140,15 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRefreshableCredential(t *testing.T) {
+	_, _, opt, err := Parse("s3://chain/bucket")
+	assert.NoError(t, err)
+	assert.Len(t, opt, 1)
+	assert.IsType(t, &credential.Chain{}, opt[0].Value)
+
+	_, _, opt, err = Parse("s3://instance/bucket")
+	assert.NoError(t, err)
+	assert.Len(t, opt, 1)
+	assert.IsType(t, &credential.Refreshable{}, opt[0].Value)
+}
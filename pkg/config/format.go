@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+// Format will format service type, namespace and pairs into config string which could be parsed by Parse.
+//
+// Secrets in credential will be included, use Redacted instead for logging or displaying.
+// ErrInvalidConfig will be returned if pair could not be represented in config string.
+func Format(t, namespace string, opt ...*types.Pair) (string, error) {
+	return format(t, namespace, false, opt...)
+}
+
+// Redacted will format config string like Format, but secrets in credential will be masked.
+//
+// Returned config string is only used for displaying, and can't be used to init service.
+func Redacted(t, namespace string, opt ...*types.Pair) (string, error) {
+	return format(t, namespace, true, opt...)
+}
+
+// Redact will mask secrets in a config string.
+//
+// Credential part will be replaced by "***" entirely if it can't be parsed.
+func Redact(cfg string) string {
+	s := strings.SplitN(cfg, "://", 2)
+	if len(s) != 2 {
+		return cfg
+	}
+	t, rest := s[0], s[1]
+
+	// Find credential in <credential>@<endpoint>/<namespace>?<options>
+	idx := strings.Index(rest, "/")
+	if idx == -1 {
+		idx = len(rest)
+	}
+	cred := rest[:idx]
+	if at := strings.Index(cred, "@"); at != -1 {
+		cred = cred[:at]
+	}
+	if cred == "" {
+		return cfg
+	}
+
+	redacted := "***"
+	if p, err := credential.Parse(cred); err == nil {
+		redacted = p.Redacted()
+	} else if cred == credential.ProviderChain || cred == credential.ProviderInstance {
+		// Refreshable providers don't carry any secrets.
+		redacted = cred
+	}
+	return t + "://" + redacted + rest[len(cred):]
+}
+
+func format(t, namespace string, redacted bool, opt ...*types.Pair) (string, error) {
+	errorMessage := "format config [%s]: <%w>"
+
+	var cred, end string
+	values := url.Values{}
+	for _, v := range opt {
+		switch v.Key {
+		case pairs.Credential:
+			// Only static credential could be represented in config string.
+			p, ok := v.Value.(*credential.Static)
+			if !ok {
+				err := fmt.Errorf("credential [%s] is not static: %w", v.Value, ErrInvalidConfig)
+				return "", fmt.Errorf(errorMessage, t, err)
+			}
+			if redacted {
+				cred = p.Redacted()
+			} else {
+				cred = p.Format()
+			}
+		case pairs.Endpoint:
+			end = v.Value.(endpoint.Provider).Value().Format()
+			// Endpoint with path can't be represented in config string.
+			if strings.Contains(end, "/") {
+				err := fmt.Errorf("endpoint [%s] contains path: %w", end, ErrInvalidConfig)
+				return "", fmt.Errorf(errorMessage, t, err)
+			}
+		default:
+			if _, ok := optionParsers[v.Key]; !ok {
+				err := fmt.Errorf("option [%s] unsupported: %w", v.Key, ErrInvalidConfig)
+				return "", fmt.Errorf(errorMessage, t, err)
+			}
+			values.Set(v.Key, fmt.Sprint(v.Value))
+		}
+	}
+	if cred == "" && end != "" {
+		return "", fmt.Errorf(errorMessage, t, fmt.Errorf("endpoint without credential: %w", ErrInvalidConfig))
+	}
+
+	var b strings.Builder
+	b.WriteString(t)
+	b.WriteString("://")
+	b.WriteString(cred)
+	if end != "" {
+		b.WriteString("@")
+		b.WriteString(end)
+	}
+	b.WriteString("/")
+	b.WriteString(namespace)
+	if len(values) > 0 {
+		b.WriteString("?")
+		// Encode will sort values by key.
+		b.WriteString(values.Encode())
+	}
+	return b.String(), nil
+}
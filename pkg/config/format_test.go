@@ -0,0 +1,143 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		name      string
+		t         string
+		namespace string
+		opt       []*types.Pair
+		expected  string
+		redacted  string
+		err       error
+	}{
+		{
+			"no credential, endpoint and options",
+			"fs",
+			"path",
+			nil,
+			"fs:///path",
+			"fs:///path",
+			nil,
+		},
+		{
+			"all elements available",
+			"qingstor",
+			"bucket/prefix",
+			[]*types.Pair{
+				pairs.WithCredential(credential.MustNewHmac("ak", "sk")),
+				pairs.WithEndpoint(endpoint.NewHTTPS("qingstor.com", 443)),
+				pairs.WithStorageClass(storageclass.Warm),
+				pairs.WithLocation("pek3b"),
+			},
+			"qingstor://hmac:ak:sk@https:qingstor.com:443/bucket/prefix?location=pek3b&storage_class=warm",
+			"qingstor://hmac:ak:***@https:qingstor.com:443/bucket/prefix?location=pek3b&storage_class=warm",
+			nil,
+		},
+		{
+			"api key",
+			"dropbox",
+			"path",
+			[]*types.Pair{
+				pairs.WithCredential(credential.MustNewAPIKey("key")),
+			},
+			"dropbox://apikey:key/path",
+			"dropbox://apikey:***/path",
+			nil,
+		},
+		{
+			"endpoint without credential",
+			"qingstor",
+			"bucket",
+			[]*types.Pair{
+				pairs.WithEndpoint(endpoint.NewHTTPS("qingstor.com", 443)),
+			},
+			"",
+			"",
+			ErrInvalidConfig,
+		},
+		{
+			"endpoint with path",
+			"s3",
+			"bucket",
+			[]*types.Pair{
+				pairs.WithCredential(credential.MustNewEnv()),
+				pairs.WithEndpoint(endpoint.NewUnix("/var/run/minio.sock")),
+			},
+			"",
+			"",
+			ErrInvalidConfig,
+		},
+		{
+			"unsupported pair",
+			"qingstor",
+			"bucket",
+			[]*types.Pair{
+				pairs.WithContext(context.Background()),
+			},
+			"",
+			"",
+			ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Format(tt.t, tt.namespace, tt.opt...)
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg)
+
+			redacted, err := Redacted(tt.t, tt.namespace, tt.opt...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.redacted, redacted)
+			assert.Equal(t, tt.redacted, Redact(cfg))
+
+			typ, namespace, opt, err := Parse(cfg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.t, typ)
+			assert.Equal(t, tt.namespace, namespace)
+			assert.ElementsMatch(t, tt.opt, opt)
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	cases := []struct {
+		name     string
+		cfg      string
+		expected string
+	}{
+		{"invalid config", "xxx", "xxx"},
+		{"no credential", "fs:///path", "fs:///path"},
+		{"hmac without namespace", "qingstor://hmac:ak:sk", "qingstor://hmac:ak:***"},
+		{"invalid credential", "qingstor://hmac:ak:sk:xxx@https:qingstor.com:443/bucket", "qingstor://***@https:qingstor.com:443/bucket"},
+		{"chain credential", "s3://chain/bucket", "s3://chain/bucket"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Redact(tt.cfg))
+		})
+	}
+
+	_, _, _, err := Parse("qingstor://hmac:ak:secret@https:qingstor.com:xxx/bucket")
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+// optionParser will convert option value into a pair.
+type optionParser func(v string) (*types.Pair, error)
+
+// optionParsers contains pairs which could be set via config string's options.
+//
+// Only pairs used while creating Servicer and Storager are included, per call pairs like
+// offset or if_match should be passed to the operation directly.
+var optionParsers = map[string]optionParser{
+	pairs.ForcePathStyle: parseBoolOption(pairs.WithForcePathStyle),
+	pairs.Location:       parseStringOption(pairs.WithLocation),
+	pairs.PartSize:       parseInt64Option(pairs.WithPartSize),
+	pairs.Project:        parseStringOption(pairs.WithProject),
+	pairs.StorageClass:   parseStorageClassOption,
+	pairs.Versioning:     parseBoolOption(pairs.WithVersioning),
+	pairs.WorkDir:        parseStringOption(pairs.WithWorkDir),
+}
+
+// parseOptions will parse options like "key=value&key=value" into pairs.
+//
+// Returned pairs are sorted by key.
+func parseOptions(s string) (opt []*types.Pair, err error) {
+	values, err := url.ParseQuery(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse options [%s]: %s: %w", s, err, ErrInvalidConfig)
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if len(values[k]) != 1 {
+			return nil, fmt.Errorf("parse option [%s]: duplicated key: %w", k, ErrInvalidConfig)
+		}
+
+		p, err := parseOption(k, values[k][0])
+		if err != nil {
+			return nil, err
+		}
+		opt = append(opt, p)
+	}
+	return opt, nil
+}
+
+// parseOption will parse a single option into pair.
+func parseOption(k, v string) (*types.Pair, error) {
+	fn, ok := optionParsers[k]
+	if !ok {
+		return nil, fmt.Errorf("parse option [%s]: unknown key: %w", k, ErrInvalidConfig)
+	}
+	p, err := fn(v)
+	if err != nil {
+		return nil, fmt.Errorf("parse option [%s] with value [%s]: %s: %w", k, v, err, ErrInvalidConfig)
+	}
+	return p, nil
+}
+
+func parseStringOption(fn func(string) *types.Pair) optionParser {
+	return func(v string) (*types.Pair, error) {
+		return fn(v), nil
+	}
+}
+
+func parseInt64Option(fn func(int64) *types.Pair) optionParser {
+	return func(v string) (*types.Pair, error) {
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return fn(i), nil
+	}
+}
+
+func parseBoolOption(fn func(bool) *types.Pair) optionParser {
+	return func(v string) (*types.Pair, error) {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, err
+		}
+		return fn(b), nil
+	}
+}
+
+func parseStorageClassOption(v string) (*types.Pair, error) {
+	switch t := storageclass.Type(v); t {
+	case storageclass.Hot, storageclass.Warm, storageclass.Cold:
+		return pairs.WithStorageClass(t), nil
+	default:
+		return nil, fmt.Errorf("storage class %s not supported", v)
+	}
+}
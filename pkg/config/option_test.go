@@ -0,0 +1,30 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionParsers(t *testing.T) {
+	content, err := ioutil.ReadFile("../../types/pairs/pairs.json")
+	assert.NoError(t, err)
+
+	var data map[string]string
+	err = json.Unmarshal(content, &data)
+	assert.NoError(t, err)
+
+	// All options should be valid pairs.
+	for k := range optionParsers {
+		_, ok := data[k]
+		assert.True(t, ok, "option %s is not a pair", k)
+	}
+
+	// Per call pairs should not be set via options.
+	for _, k := range []string{"offset", "size", "if_match", "version_id", "content_type", "type"} {
+		_, ok := optionParsers[k]
+		assert.False(t, ok, "option %s should not be supported", k)
+	}
+}
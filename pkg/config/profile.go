@@ -0,0 +1,248 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+var (
+	// ErrProfileNotFound will be returned when profile not found in profiles.
+	ErrProfileNotFound = errors.New("profile not found")
+)
+
+const (
+	// EnvProfileFile is the env to specify profile file path.
+	EnvProfileFile = "STORAGE_PROFILE_FILE"
+	// EnvProfilePrefix is the prefix of env to override value in profile.
+	//
+	// Env will be formatted as "STORAGE_PROFILE_<NAME>_<KEY>", name and key will be
+	// upper cased and "-" will be replaced by "_", for example:
+	// STORAGE_PROFILE_PROD_ARCHIVE_CREDENTIAL will override credential in profile prod-archive.
+	//
+	// Profile not in profile file could be defined via env as long as its type is set, the name
+	// will be lower cased with "_" replaced by "-", for example: STORAGE_PROFILE_PROD_ARCHIVE_TYPE
+	// will define profile prod-archive.
+	EnvProfilePrefix = "STORAGE_PROFILE_"
+
+	// profileType is the key of service type in profile.
+	profileType = "type"
+)
+
+// profileNameRegexp is the valid profile name, which contains letters, digits, "-" and "_",
+// and starts with letter or digit.
+var profileNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// Profile is a named remote which holds service type, credential, endpoint and options.
+//
+// All values are in the same format as config string, for example:
+//
+//	{
+//	  "type": "qingstor",
+//	  "credential": "hmac:ak:sk",
+//	  "endpoint": "https:qingstor.com:443",
+//	  "location": "pek3b"
+//	}
+type Profile map[string]string
+
+// Profiles is the collection of profiles with their name.
+type Profiles map[string]Profile
+
+// DefaultProfileFile will return profile file path.
+//
+// Path in env STORAGE_PROFILE_FILE will be used if set, or "storage/profiles.json"
+// in user's config dir will be returned.
+func DefaultProfileFile() (string, error) {
+	if path := os.Getenv(EnvProfileFile); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "storage", "profiles.json"), nil
+}
+
+// LoadProfiles will load profiles from a json file, and apply env overrides.
+//
+// Empty profiles will be returned if file not exist.
+func LoadProfiles(path string) (Profiles, error) {
+	errorMessage := "load profiles [%s]: <%w>"
+
+	p := make(Profiles)
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf(errorMessage, path, err)
+	}
+	if err == nil {
+		err = json.Unmarshal(content, &p)
+		if err != nil {
+			return nil, fmt.Errorf(errorMessage, path, err)
+		}
+	}
+
+	for name := range p {
+		if !profileNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf(errorMessage, path, fmt.Errorf("profile name %s: %w", name, ErrInvalidConfig))
+		}
+	}
+	for _, name := range envProfileNames() {
+		if _, ok := p[name]; !ok && !p.hasEnvName(name) {
+			p[name] = nil
+		}
+	}
+
+	for name, v := range p {
+		if v == nil {
+			v = make(Profile)
+			p[name] = v
+		}
+		v.applyEnv(name)
+	}
+	return p, nil
+}
+
+// Parse will parse config string like "<profile>:<namespace>?<options>" via profiles.
+//
+// Options in config string will override options in profile.
+func (p Profiles) Parse(cfg string) (t, namespace string, opt []*types.Pair, err error) {
+	errorMessage := "parse config [%s] via profile: <%w>"
+
+	s := strings.SplitN(cfg, ":", 2)
+	if len(s) != 2 || s[0] == "" {
+		err = fmt.Errorf(errorMessage, cfg, ErrInvalidConfig)
+		return
+	}
+	profile, ok := p[s[0]]
+	if !ok {
+		err = fmt.Errorf(errorMessage, cfg, ErrProfileNotFound)
+		return
+	}
+
+	t, opt, err = profile.parse()
+	if err != nil {
+		return "", "", nil, fmt.Errorf(errorMessage, cfg, err)
+	}
+
+	// Handle namespace and options.
+	s = strings.SplitN(strings.TrimPrefix(s[1], "/"), "?", 2)
+	namespace = s[0]
+	if len(s) == 1 {
+		return
+	}
+	opts, err := parseOptions(s[1])
+	if err != nil {
+		return "", "", nil, fmt.Errorf(errorMessage, cfg, err)
+	}
+	opt = append(opt, opts...)
+	return
+}
+
+// IsProfile will check whether config string should be parsed via profiles.
+//
+// Config string without "://" and starts with a valid profile name will be treated as
+// "<profile>:<namespace>".
+func IsProfile(cfg string) bool {
+	if strings.Contains(cfg, "://") {
+		return false
+	}
+	s := strings.SplitN(cfg, ":", 2)
+	return len(s) == 2 && profileNameRegexp.MatchString(s[0])
+}
+
+// parse will parse profile into service type and pairs.
+func (p Profile) parse() (t string, opt []*types.Pair, err error) {
+	t = p[profileType]
+	if t == "" {
+		return "", nil, fmt.Errorf("profile type missing: %w", ErrInvalidConfig)
+	}
+
+	for k, v := range p {
+		switch k {
+		case profileType:
+			continue
+		case pairs.Credential:
+			cred, err := credential.ParseProvider(v)
+			if err != nil {
+				return "", nil, err
+			}
+			opt = append(opt, pairs.WithCredential(cred))
+		case pairs.Endpoint:
+			end, err := endpoint.Parse(v)
+			if err != nil {
+				return "", nil, err
+			}
+			opt = append(opt, pairs.WithEndpoint(end))
+		default:
+			pair, err := parseOption(k, v)
+			if err != nil {
+				return "", nil, err
+			}
+			opt = append(opt, pair)
+		}
+	}
+	return t, opt, nil
+}
+
+// applyEnv will override profile's values via env.
+func (p Profile) applyEnv(name string) {
+	for _, k := range profileKeys() {
+		if v, ok := os.LookupEnv(profileEnv(name, k)); ok {
+			p[k] = v
+		}
+	}
+}
+
+// hasEnvName will check whether there is a profile which shares the same env name with name.
+func (p Profiles) hasEnvName(name string) bool {
+	for k := range p {
+		if profileEnv(k, profileType) == profileEnv(name, profileType) {
+			return true
+		}
+	}
+	return false
+}
+
+// envProfileNames will return names of profiles whose type is set via env.
+func envProfileNames() (names []string) {
+	typeSuffix := profileEnv("", profileType)[len(EnvProfilePrefix):]
+
+	for _, env := range os.Environ() {
+		key := strings.SplitN(env, "=", 2)[0]
+		if !strings.HasPrefix(key, EnvProfilePrefix) || !strings.HasSuffix(key, typeSuffix) {
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(key, EnvProfilePrefix), typeSuffix)
+		name = strings.ToLower(strings.Replace(name, "_", "-", -1))
+		if profileNameRegexp.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// profileKeys will return all keys which could be set in profile.
+func profileKeys() []string {
+	keys := []string{profileType, pairs.Credential, pairs.Endpoint}
+	for k := range optionParsers {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func profileEnv(name, key string) string {
+	r := strings.NewReplacer("-", "_", ".", "_")
+	return EnvProfilePrefix + strings.ToUpper(r.Replace(name)) + "_" + strings.ToUpper(r.Replace(key))
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "profiles.json")
+	content := `{
+  "prod-archive": {
+    "type": "qingstor",
+    "credential": "hmac:ak:sk",
+    "endpoint": "https:qingstor.com:443",
+    "location": "pek3b"
+  }
+}`
+	err = ioutil.WriteFile(path, []byte(content), 0600)
+	assert.NoError(t, err)
+
+	os.Setenv("STORAGE_PROFILE_PROD_ARCHIVE_LOCATION", "sh1a")
+	defer os.Unsetenv("STORAGE_PROFILE_PROD_ARCHIVE_LOCATION")
+
+	p, err := LoadProfiles(path)
+	assert.NoError(t, err)
+	assert.Equal(t, Profile{
+		"type":       "qingstor",
+		"credential": "hmac:ak:sk",
+		"endpoint":   "https:qingstor.com:443",
+		"location":   "sh1a",
+	}, p["prod-archive"])
+
+	p, err = LoadProfiles(filepath.Join(dir, "not_exist.json"))
+	assert.NoError(t, err)
+	assert.Empty(t, p)
+
+	err = ioutil.WriteFile(path, []byte(`{"prod archive": {"type": "qingstor"}}`), 0600)
+	assert.NoError(t, err)
+	_, err = LoadProfiles(path)
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+}
+
+func TestLoadProfiles_Env(t *testing.T) {
+	envs := map[string]string{
+		"STORAGE_PROFILE_DEV_BACKUP_TYPE":       "s3",
+		"STORAGE_PROFILE_DEV_BACKUP_CREDENTIAL": "hmac:ak:sk",
+		"STORAGE_PROFILE_DEV_BACKUP_LOCATION":   "us-east-1",
+	}
+	for k, v := range envs {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	p, err := LoadProfiles(filepath.Join(os.TempDir(), "not_exist.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, Profiles{
+		"dev-backup": Profile{
+			"type":       "s3",
+			"credential": "hmac:ak:sk",
+			"location":   "us-east-1",
+		},
+	}, p)
+}
+
+func TestProfiles_Parse(t *testing.T) {
+	p := Profiles{
+		"prod-archive": Profile{
+			"type":       "qingstor",
+			"credential": "hmac:ak:sk",
+			"endpoint":   "https:qingstor.com:443",
+			"location":   "pek3b",
+		},
+		"no-type": Profile{
+			"location": "pek3b",
+		},
+		"invalid-option": Profile{
+			"type":     "qingstor",
+			"test_key": "test_value",
+		},
+	}
+
+	cases := []struct {
+		name      string
+		cfg       string
+		t         string
+		namespace string
+		opt       []*types.Pair
+		err       error
+	}{
+		{
+			"normal",
+			"prod-archive:/bucket/prefix",
+			"qingstor",
+			"bucket/prefix",
+			[]*types.Pair{
+				pairs.WithCredential(credential.MustNewHmac("ak", "sk")),
+				pairs.WithEndpoint(endpoint.NewHTTPS("qingstor.com", 443)),
+				pairs.WithLocation("pek3b"),
+			},
+			nil,
+		},
+		{
+			"with options",
+			"prod-archive:bucket?work_dir=/prefix",
+			"qingstor",
+			"bucket",
+			[]*types.Pair{
+				pairs.WithCredential(credential.MustNewHmac("ak", "sk")),
+				pairs.WithEndpoint(endpoint.NewHTTPS("qingstor.com", 443)),
+				pairs.WithLocation("pek3b"),
+				pairs.WithWorkDir("/prefix"),
+			},
+			nil,
+		},
+		{
+			"invalid config",
+			"prod-archive",
+			"",
+			"",
+			nil,
+			ErrInvalidConfig,
+		},
+		{
+			"profile not found",
+			"not-exist:/bucket",
+			"",
+			"",
+			nil,
+			ErrProfileNotFound,
+		},
+		{
+			"type missing",
+			"no-type:/bucket",
+			"",
+			"",
+			nil,
+			ErrInvalidConfig,
+		},
+		{
+			"invalid option in profile",
+			"invalid-option:/bucket",
+			"",
+			"",
+			nil,
+			ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, namespace, opt, err := p.Parse(tt.cfg)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+			assert.Equal(t, tt.t, typ)
+			assert.Equal(t, tt.namespace, namespace)
+			assert.ElementsMatch(t, tt.opt, opt)
+		})
+	}
+}
+
+func TestIsProfile(t *testing.T) {
+	assert.True(t, IsProfile("prod-archive:/bucket"))
+	assert.True(t, IsProfile("prod_archive:"))
+	assert.False(t, IsProfile("qingstor://hmac:ak:sk/bucket"))
+	assert.False(t, IsProfile("prod-archive"))
+	assert.False(t, IsProfile("/tmp/prod:bucket"))
+	assert.False(t, IsProfile(":bucket"))
+	assert.False(t, IsProfile(""))
+}
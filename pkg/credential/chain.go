@@ -0,0 +1,118 @@
+package credential
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// ProviderChain is the config string for provider created by NewDefaultChain.
+	ProviderChain = "chain"
+	// ProviderInstance is the config string for provider created by NewInstanceMetadata
+	// with DefaultInstanceMetadataEndpoint.
+	ProviderInstance = "instance"
+)
+
+// ParseProvider will parse config string to create a Provider.
+//
+// Besides static credentials supported by Parse, ProviderChain and ProviderInstance could
+// be used to create refreshable providers, so that they could be used in config string.
+func ParseProvider(cfg string) (Provider, error) {
+	switch cfg {
+	case ProviderChain:
+		return NewDefaultChain(), nil
+	case ProviderInstance:
+		return NewInstanceMetadata(DefaultInstanceMetadataEndpoint), nil
+	}
+
+	p, err := Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Chain will retrieve credential from a list of providers, the first available one will be used.
+type Chain struct {
+	providers []Provider
+
+	current Provider
+	lock    sync.Mutex
+}
+
+// NewChain will create a chain provider.
+func NewChain(providers ...Provider) *Chain {
+	return &Chain{providers: providers}
+}
+
+// NewDefaultChain will create a chain provider which tries following providers in order:
+//
+//   - Credential config string in env STORAGE_CREDENTIAL
+//   - Shared credential file, see DefaultSharedFile and NewSharedFile
+//   - Instance metadata service, see NewInstanceMetadata
+//   - Given static providers, nil will be ignored
+func NewDefaultChain(static ...Provider) *Chain {
+	profile := os.Getenv(EnvSharedProfile)
+	if profile == "" {
+		profile = DefaultSharedProfile
+	}
+
+	providers := []Provider{
+		NewEnvVar(EnvCredential),
+		NewSharedFile(DefaultSharedFile(), profile),
+		NewInstanceMetadata(DefaultInstanceMetadataEndpoint),
+	}
+	for _, v := range static {
+		if v != nil {
+			providers = append(providers, v)
+		}
+	}
+	return NewChain(providers...)
+}
+
+// Retrieve implements Provider.Retrieve
+//
+// Provider which returned value last time will be tried first, and other providers
+// will be tried in order if it failed.
+func (c *Chain) Retrieve() (Value, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.current != nil {
+		v, err := c.current.Retrieve()
+		if err == nil {
+			return v, nil
+		}
+	}
+
+	errs := make([]string, 0, len(c.providers))
+	for _, p := range c.providers {
+		v, err := p.Retrieve()
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		c.current = p
+		return v, nil
+	}
+	c.current = nil
+	return Value{}, fmt.Errorf("retrieve credential from chain [%s]: %w", strings.Join(errs, "; "), ErrCredentialNotFound)
+}
+
+// IsExpired implements Provider.IsExpired
+func (c *Chain) IsExpired() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.current == nil {
+		return true
+	}
+	return c.current.IsExpired()
+}
+
+// String implements fmt.Stringer, secrets will never be included.
+func (c *Chain) String() string {
+	return "chain"
+}
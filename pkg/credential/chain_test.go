@@ -0,0 +1,43 @@
+package credential
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	notFound := NewRefreshable(func() (Value, error) {
+		return Value{}, ErrCredentialNotFound
+	}, 0)
+
+	c := NewChain(notFound, MustNewHmac("ak", "sk"), MustNewAPIKey("key"))
+	assert.True(t, c.IsExpired())
+
+	v, err := c.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolHmac, v.Protocol)
+	assert.False(t, c.IsExpired())
+
+	_, err = NewChain(notFound).Retrieve()
+	assert.True(t, errors.Is(err, ErrCredentialNotFound))
+}
+
+func TestParseProvider(t *testing.T) {
+	p, err := ParseProvider(ProviderChain)
+	assert.NoError(t, err)
+	assert.IsType(t, &Chain{}, p)
+
+	p, err = ParseProvider(ProviderInstance)
+	assert.NoError(t, err)
+	assert.IsType(t, &Refreshable{}, p)
+
+	p, err = ParseProvider("hmac:ak:sk")
+	assert.NoError(t, err)
+	assert.Equal(t, MustNewHmac("ak", "sk"), p)
+
+	p, err = ParseProvider("unknown")
+	assert.True(t, errors.Is(err, ErrUnsupportedProtocol))
+	assert.Nil(t, p)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 var (
@@ -11,6 +12,8 @@ var (
 	ErrInvalidConfig = errors.New("invalid config")
 	// ErrUnsupportedProtocol will return if protocol is unsupported.
 	ErrUnsupportedProtocol = errors.New("unsupported protocol")
+	// ErrCredentialNotFound will return if provider can't find any credential.
+	ErrCredentialNotFound = errors.New("credential not found")
 )
 
 const (
@@ -22,13 +25,18 @@ const (
 	//
 	// value = [Access Key, Secret Key]
 	ProtocolHmac = "hmac"
+	// ProtocolSTS will hold temporary credential issued by security token service.
+	//
+	// value = [Access Key, Secret Key, Session Token]
+	ProtocolSTS = "sts"
 	// ProtocolAPIKey will hold api key credential.
 	//
 	// value = [API Key]
 	ProtocolAPIKey = "apikey"
 	// ProtocolFile will hold file credential.
 	//
-	// value = [File Path], service decide how to use this file
+	// value = [File Path] or [File Path, Profile], service will load this file in its native format,
+	// and select the profile if service's format supports.
 	ProtocolFile = "file"
 	// ProtocolEnv will represent credential from env.
 	//
@@ -36,31 +44,133 @@ const (
 	ProtocolEnv = "env"
 )
 
-// Provider will provide credential protocol and values.
-type Provider struct {
+// Provider will provide credential value.
+//
+// Provider could be refreshed, services should retrieve value again while it's expired.
+type Provider interface {
+	// Retrieve will return current credential value, value will be refreshed if expired.
+	Retrieve() (Value, error)
+	// IsExpired will check whether current credential value is expired.
+	IsExpired() bool
+}
+
+// Value is the credential value retrieved from Provider.
+type Value struct {
+	Protocol string
+	// Args is credential's value in string array, see protocols' comments for details.
+	Args []string
+	// Expiration is the time when this value expired, zero means never expire.
+	Expiration time.Time
+}
+
+// Format will format value into config string which could be parsed by Parse.
+//
+// Secrets will be included, use Redacted or String instead for logging or displaying.
+func (v Value) Format() string {
+	return strings.Join(append([]string{v.Protocol}, v.Args...), ":")
+}
+
+// Redacted will format value into config string with secrets masked.
+//
+// Secret key and session token in hmac and sts, and key in apikey will be replaced by "***".
+func (v Value) Redacted() string {
+	switch v.Protocol {
+	case ProtocolHmac, ProtocolSTS, ProtocolAPIKey:
+		// Mask all values for malformed value to prevent leaking secrets.
+		if len(v.Args) == 0 || (v.Protocol != ProtocolAPIKey && len(v.Args) == 1) {
+			return strings.Join([]string{v.Protocol, redactedValue}, ":")
+		}
+	}
+
+	switch v.Protocol {
+	case ProtocolHmac:
+		return strings.Join([]string{v.Protocol, v.Args[0], redactedValue}, ":")
+	case ProtocolSTS:
+		return strings.Join([]string{v.Protocol, v.Args[0], redactedValue, redactedValue}, ":")
+	case ProtocolAPIKey:
+		return strings.Join([]string{v.Protocol, redactedValue}, ":")
+	default:
+		return v.Format()
+	}
+}
+
+// File will return file path and profile for file credential, profile will be empty if not given.
+func (v Value) File() (path, profile string) {
+	if v.Protocol != ProtocolFile || len(v.Args) == 0 {
+		return "", ""
+	}
+	if len(v.Args) > 1 {
+		profile = v.Args[1]
+	}
+	return v.Args[0], profile
+}
+
+// String implements fmt.Stringer, secrets will never be included.
+func (v Value) String() string {
+	return v.Redacted()
+}
+
+// redactedValue will be used to replace secrets.
+const redactedValue = "***"
+
+// Static will provide credential protocol and values which never expire.
+type Static struct {
 	protocol string
 	args     []string
 }
 
 // Protocol provides current credential's protocol.
-func (p *Provider) Protocol() string {
+func (p *Static) Protocol() string {
 	return p.protocol
 }
 
 // Value provides current credential's value in string array.
-func (p *Provider) Value() []string {
+func (p *Static) Value() []string {
 	return p.args
 }
 
-// Parse will parse config string to create a credential Provider.
-func Parse(cfg string) (*Provider, error) {
+// Retrieve implements Provider.Retrieve
+func (p *Static) Retrieve() (Value, error) {
+	return Value{Protocol: p.protocol, Args: p.args}, nil
+}
+
+// IsExpired implements Provider.IsExpired, static credential never expire.
+func (p *Static) IsExpired() bool {
+	return false
+}
+
+// Format will format provider into config string which could be parsed by Parse.
+//
+// Secrets will be included, use Redacted or String instead for logging or displaying.
+func (p *Static) Format() string {
+	v, _ := p.Retrieve()
+	return v.Format()
+}
+
+// Redacted will format provider into config string with secrets masked.
+func (p *Static) Redacted() string {
+	v, _ := p.Retrieve()
+	return v.Redacted()
+}
+
+// String implements fmt.Stringer, secrets will never be included.
+func (p *Static) String() string {
+	return p.Redacted()
+}
+
+// Parse will parse config string to create a static credential Provider.
+func Parse(cfg string) (*Static, error) {
 	errorMessage := "parse credential config [%s]: %w"
 
 	s := strings.Split(cfg, ":")
+	// Only protocol will be included in error to prevent leaking secrets.
+	cfg = s[0]
 
 	switch s[0] {
 	case ProtocolHmac:
 		return NewHmac(s[1:]...)
+	case ProtocolSTS:
+		return NewSTS(s[1:]...)
 	case ProtocolAPIKey:
 		return NewAPIKey(s[1:]...)
 	case ProtocolFile:
@@ -73,17 +183,17 @@ func Parse(cfg string) (*Provider, error) {
 }
 
 // NewHmac create a hmac provider.
-func NewHmac(value ...string) (*Provider, error) {
-	errorMessage := "parse hmac credential [%s]: %w"
+func NewHmac(value ...string) (*Static, error) {
+	errorMessage := "parse hmac credential with %d values: %w"
 
 	if len(value) != 2 {
-		return nil, fmt.Errorf(errorMessage, value, ErrInvalidConfig)
+		return nil, fmt.Errorf(errorMessage, len(value), ErrInvalidConfig)
 	}
-	return &Provider{ProtocolHmac, []string{value[0], value[1]}}, nil
+	return &Static{ProtocolHmac, []string{value[0], value[1]}}, nil
 }
 
 // MustNewHmac make sure Provider must be created if no panic happened.
-func MustNewHmac(value ...string) *Provider {
+func MustNewHmac(value ...string) *Static {
 	p, err := NewHmac(value...)
 	if err != nil {
 		panic(err)
@@ -91,18 +201,39 @@ func MustNewHmac(value ...string) *Provider {
 	return p
 }
 
+// NewSTS create a sts provider.
+//
+// Provider created by NewSTS never refreshes, use Refreshable to refresh temporary credential.
+func NewSTS(value ...string) (*Static, error) {
+	errorMessage := "parse sts credential with %d values: %w"
+
+	if len(value) != 3 {
+		return nil, fmt.Errorf(errorMessage, len(value), ErrInvalidConfig)
+	}
+	return &Static{ProtocolSTS, []string{value[0], value[1], value[2]}}, nil
+}
+
+// MustNewSTS make sure Provider must be created if no panic happened.
+func MustNewSTS(value ...string) *Static {
+	p, err := NewSTS(value...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
 // NewAPIKey create a api key provider.
-func NewAPIKey(value ...string) (*Provider, error) {
-	errorMessage := "parse apikey credential [%s]: %w"
+func NewAPIKey(value ...string) (*Static, error) {
+	errorMessage := "parse apikey credential with %d values: %w"
 
 	if len(value) != 1 {
-		return nil, fmt.Errorf(errorMessage, value, ErrInvalidConfig)
+		return nil, fmt.Errorf(errorMessage, len(value), ErrInvalidConfig)
 	}
-	return &Provider{ProtocolAPIKey, []string{value[0]}}, nil
+	return &Static{ProtocolAPIKey, []string{value[0]}}, nil
 }
 
 // MustNewAPIKey make sure Provider must be created if no panic happened.
-func MustNewAPIKey(value ...string) *Provider {
+func MustNewAPIKey(value ...string) *Static {
 	p, err := NewAPIKey(value...)
 	if err != nil {
 		panic(err)
@@ -111,17 +242,19 @@ func MustNewAPIKey(value ...string) *Provider {
 }
 
 // NewFile create a file provider.
-func NewFile(value ...string) (*Provider, error) {
-	errorMessage := "parse file credential [%s]: %w"
+//
+// Profile is optional, config string like "file:<path>:<profile>" could be used to select profile.
+func NewFile(value ...string) (*Static, error) {
+	errorMessage := "parse file credential with %d values: %w"
 
-	if len(value) != 1 {
-		return nil, fmt.Errorf(errorMessage, value, ErrInvalidConfig)
+	if len(value) != 1 && len(value) != 2 {
+		return nil, fmt.Errorf(errorMessage, len(value), ErrInvalidConfig)
 	}
-	return &Provider{ProtocolFile, []string{value[0]}}, nil
+	return &Static{ProtocolFile, append([]string{}, value...)}, nil
 }
 
 // MustNewFile make sure Provider must be created if no panic happened.
-func MustNewFile(value ...string) *Provider {
+func MustNewFile(value ...string) *Static {
 	p, err := NewFile(value...)
 	if err != nil {
 		panic(err)
@@ -130,12 +263,12 @@ func MustNewFile(value ...string) *Provider {
 }
 
 // NewEnv create a env provider.
-func NewEnv(_ ...string) (*Provider, error) {
-	return &Provider{ProtocolEnv, nil}, nil
+func NewEnv(_ ...string) (*Static, error) {
+	return &Static{ProtocolEnv, nil}, nil
 }
 
 // MustNewEnv make sure Provider must be created if no panic happened.
-func MustNewEnv(value ...string) *Provider {
+func MustNewEnv(value ...string) *Static {
 	p, _ := NewEnv(value...)
 	return p
 }
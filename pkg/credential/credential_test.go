@@ -2,7 +2,9 @@ package credential
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -12,41 +14,75 @@ func TestProvider(t *testing.T) {
 	protocol := uuid.New().String()
 	args := []string{uuid.New().String(), uuid.New().String()}
 
-	p := &Provider{protocol: protocol, args: args}
+	p := &Static{protocol: protocol, args: args}
 
 	assert.Equal(t, protocol, p.Protocol())
 	assert.EqualValues(t, args, p.Value())
 }
 
+func TestProvider_Format(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    *Static
+		format   string
+		redacted string
+	}{
+		{"hmac", MustNewHmac("ak", "sk"), "hmac:ak:sk", "hmac:ak:***"},
+		{"sts", MustNewSTS("ak", "sk", "token"), "sts:ak:sk:token", "sts:ak:***:***"},
+		{"api key", MustNewAPIKey("key"), "apikey:key", "apikey:***"},
+		{"file", MustNewFile("/path/to/file"), "file:/path/to/file", "file:/path/to/file"},
+		{"env", MustNewEnv(), "env", "env"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.format, tt.value.Format())
+			assert.Equal(t, tt.redacted, tt.value.Redacted())
+			assert.Equal(t, tt.redacted, tt.value.String())
+			assert.Equal(t, tt.redacted, fmt.Sprintf("%v", tt.value))
+
+			p, err := Parse(tt.value.Format())
+			assert.NoError(t, err)
+			assert.EqualValues(t, tt.value, p)
+		})
+	}
+}
+
 func TestParse(t *testing.T) {
 	cases := []struct {
 		name  string
 		cfg   string
-		value *Provider
+		value *Static
 		err   error
 	}{
 		{
 			"hmac",
 			"hmac:ak:sk",
-			&Provider{protocol: ProtocolHmac, args: []string{"ak", "sk"}},
+			&Static{protocol: ProtocolHmac, args: []string{"ak", "sk"}},
 			nil,
 		},
 		{
 			"api key",
 			"apikey:key",
-			&Provider{protocol: ProtocolAPIKey, args: []string{"key"}},
+			&Static{protocol: ProtocolAPIKey, args: []string{"key"}},
 			nil,
 		},
 		{
 			"file",
 			"file:/path/to/file",
-			&Provider{protocol: ProtocolFile, args: []string{"/path/to/file"}},
+			&Static{protocol: ProtocolFile, args: []string{"/path/to/file"}},
+			nil,
+		},
+		{
+			"file with profile",
+			"file:/path/to/file:default",
+			&Static{protocol: ProtocolFile, args: []string{"/path/to/file", "default"}},
 			nil,
 		},
 		{
 			"env",
 			"env",
-			&Provider{protocol: ProtocolEnv},
+			&Static{protocol: ProtocolEnv},
 			nil,
 		},
 		{
@@ -74,13 +110,13 @@ func TestNewHmac(t *testing.T) {
 	cases := []struct {
 		name  string
 		input []string
-		value *Provider
+		value *Static
 		err   error
 	}{
 		{
 			"normal",
 			[]string{"ak", "sk"},
-			&Provider{ProtocolHmac, []string{"ak", "sk"}},
+			&Static{ProtocolHmac, []string{"ak", "sk"}},
 			nil,
 		},
 		{
@@ -141,13 +177,13 @@ func TestNewAPIKey(t *testing.T) {
 	cases := []struct {
 		name  string
 		input []string
-		value *Provider
+		value *Static
 		err   error
 	}{
 		{
 			"normal",
 			[]string{"key"},
-			&Provider{ProtocolAPIKey, []string{"key"}},
+			&Static{ProtocolAPIKey, []string{"key"}},
 			nil,
 		},
 		{
@@ -208,13 +244,19 @@ func TestNewFile(t *testing.T) {
 	cases := []struct {
 		name  string
 		input []string
-		value *Provider
+		value *Static
 		err   error
 	}{
 		{
 			"normal",
 			[]string{"/path/to/file"},
-			&Provider{ProtocolFile, []string{"/path/to/file"}},
+			&Static{ProtocolFile, []string{"/path/to/file"}},
+			nil,
+		},
+		{
+			"with profile",
+			[]string{"/path/to/file", "default"},
+			&Static{ProtocolFile, []string{"/path/to/file", "default"}},
 			nil,
 		},
 		{
@@ -238,6 +280,27 @@ func TestNewFile(t *testing.T) {
 	}
 }
 
+func TestValue_File(t *testing.T) {
+	cases := []struct {
+		name    string
+		value   Value
+		path    string
+		profile string
+	}{
+		{"path", Value{ProtocolFile, []string{"/path/to/file"}, time.Time{}}, "/path/to/file", ""},
+		{"path and profile", Value{ProtocolFile, []string{"/path/to/file", "default"}, time.Time{}}, "/path/to/file", "default"},
+		{"not file", Value{ProtocolHmac, []string{"ak", "sk"}, time.Time{}}, "", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			path, profile := tt.value.File()
+			assert.Equal(t, tt.path, path)
+			assert.Equal(t, tt.profile, profile)
+		})
+	}
+}
+
 func TestMustNewFile(t *testing.T) {
 	cases := []struct {
 		name  string
@@ -275,13 +338,13 @@ func TestNewMev(t *testing.T) {
 	cases := []struct {
 		name  string
 		input []string
-		value *Provider
+		value *Static
 		err   error
 	}{
 		{
 			"normal",
 			[]string{""},
-			&Provider{ProtocolEnv, nil},
+			&Static{ProtocolEnv, nil},
 			nil,
 		},
 	}
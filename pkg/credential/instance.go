@@ -0,0 +1,137 @@
+package credential
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultInstanceMetadataEndpoint is the default instance metadata service endpoint.
+const DefaultInstanceMetadataEndpoint = "http://169.254.169.254"
+
+// instanceMetadataTimeout is the timeout for instance metadata requests, it should be short
+// because instance metadata service is not available outside the cloud.
+const instanceMetadataTimeout = time.Second
+
+const (
+	// instanceTokenPath is the path to fetch session token for instance metadata service v2.
+	instanceTokenPath = "/latest/api/token"
+	// instanceTokenTTLHeader is the header to specify ttl in seconds while fetching session token.
+	instanceTokenTTLHeader = "X-aws-ec2-metadata-token-ttl-seconds"
+	// instanceTokenHeader is the header to carry session token in instance metadata requests.
+	instanceTokenHeader = "X-aws-ec2-metadata-token"
+	// instanceTokenTTL is the ttl of session token, every refresh will fetch a new one,
+	// so a short ttl is enough.
+	instanceTokenTTL = "60"
+)
+
+// instanceCredential is the credential returned by instance metadata service.
+type instanceCredential struct {
+	Code            string
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string
+	Token           string
+	Expiration      time.Time
+}
+
+// NewInstanceMetadata will create a provider which retrieves temporary credential of
+// instance's role from instance metadata service.
+//
+// The service should be compatible with AWS EC2 instance metadata service, value will be
+// refreshed 5 minutes before its expiration.
+// Session token of IMDSv2 will be used if service supports it, or requests will fall back to IMDSv1.
+// ErrCredentialNotFound will be returned if service is not available or no role attached.
+func NewInstanceMetadata(endpoint string) *Refreshable {
+	client := &http.Client{Timeout: instanceMetadataTimeout}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	base := endpoint + "/latest/meta-data/iam/security-credentials/"
+
+	return NewRefreshable(func() (Value, error) {
+		errorMessage := "retrieve credential from instance metadata [%s]: %w"
+
+		token, err := getInstanceToken(client, endpoint+instanceTokenPath)
+		if err != nil {
+			return Value{}, fmt.Errorf(errorMessage, endpoint, err)
+		}
+
+		roles, err := getInstanceMetadata(client, base, token)
+		if err != nil {
+			return Value{}, fmt.Errorf(errorMessage, endpoint, err)
+		}
+		role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+		if role == "" {
+			return Value{}, fmt.Errorf(errorMessage, endpoint, ErrCredentialNotFound)
+		}
+
+		content, err := getInstanceMetadata(client, base+role, token)
+		if err != nil {
+			return Value{}, fmt.Errorf(errorMessage, endpoint, err)
+		}
+		cred := &instanceCredential{}
+		err = json.Unmarshal(content, cred)
+		if err != nil {
+			return Value{}, fmt.Errorf(errorMessage, endpoint, err)
+		}
+		if cred.Code != "" && cred.Code != "Success" {
+			return Value{}, fmt.Errorf(errorMessage, endpoint, fmt.Errorf("code %s: %w", cred.Code, ErrCredentialNotFound))
+		}
+
+		return Value{
+			Protocol:   ProtocolSTS,
+			Args:       []string{cred.AccessKeyID, cred.SecretAccessKey, cred.Token},
+			Expiration: cred.Expiration,
+		}, nil
+	}, DefaultExpiryWindow)
+}
+
+// getInstanceToken will fetch a session token for IMDSv2.
+//
+// Empty token will be returned if service doesn't support IMDSv2, so that requests could fall back to IMDSv1.
+func getInstanceToken(client *http.Client, url string) (string, error) {
+	req, err := http.NewRequest(http.MethodPut, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(instanceTokenTTLHeader, instanceTokenTTL)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Instance metadata service is not reachable, no need to try IMDSv1.
+		return "", fmt.Errorf("%s: %w", err, ErrCredentialNotFound)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func getInstanceMetadata(client *http.Client, url, token string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set(instanceTokenHeader, token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Instance metadata service is not reachable.
+		return nil, fmt.Errorf("%s: %w", err, ErrCredentialNotFound)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d: %w", resp.StatusCode, ErrCredentialNotFound)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,74 @@
+package credential
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInstanceMetadata(t *testing.T) {
+	expiration := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest/meta-data/iam/security-credentials/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("test-role\n"))
+	})
+	mux.HandleFunc("/latest/meta-data/iam/security-credentials/test-role", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+  "Code": "Success",
+  "AccessKeyId": "ak",
+  "SecretAccessKey": "sk",
+  "Token": "token",
+  "Expiration": "` + expiration.Format(time.RFC3339) + `"
+}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := NewInstanceMetadata(srv.URL)
+	v, err := p.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolSTS, v.Protocol)
+	assert.Equal(t, []string{"ak", "sk", "token"}, v.Args)
+	assert.True(t, expiration.Equal(v.Expiration))
+	assert.False(t, p.IsExpired())
+}
+
+func TestNewInstanceMetadata_NotAvailable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := NewInstanceMetadata(srv.URL).Retrieve()
+	assert.True(t, errors.Is(err, ErrCredentialNotFound))
+}
+
+func TestNewInstanceMetadata_V2(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest/api/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.NotEmpty(t, r.Header.Get(instanceTokenTTLHeader))
+		_, _ = w.Write([]byte("session-token"))
+	})
+	mux.HandleFunc("/latest/meta-data/iam/security-credentials/", func(w http.ResponseWriter, r *http.Request) {
+		// Service which requires IMDSv2 will reject requests without token.
+		if r.Header.Get(instanceTokenHeader) != "session-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/latest/meta-data/iam/security-credentials/" {
+			_, _ = w.Write([]byte("test-role"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Code": "Success", "AccessKeyId": "ak", "SecretAccessKey": "sk", "Token": "token"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	v, err := NewInstanceMetadata(srv.URL).Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ak", "sk", "token"}, v.Args)
+}
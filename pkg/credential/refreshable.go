@@ -0,0 +1,176 @@
+package credential
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// EnvCredential is the env which holds credential config string, like "hmac:ak:sk".
+	EnvCredential = "STORAGE_CREDENTIAL"
+	// EnvSharedFile is the env to specify shared credential file path.
+	EnvSharedFile = "STORAGE_SHARED_CREDENTIAL_FILE"
+	// EnvSharedProfile is the env to specify profile in shared credential file.
+	EnvSharedProfile = "STORAGE_SHARED_CREDENTIAL_PROFILE"
+
+	// DefaultSharedProfile is the profile used in shared credential file if not specified.
+	DefaultSharedProfile = "default"
+	// DefaultExpiryWindow is the duration before expiration to refresh credential.
+	DefaultExpiryWindow = 5 * time.Minute
+
+	// sharedFileTTL is the duration that value read from shared file will be cached,
+	// so that rotated keys in file could be picked up.
+	sharedFileTTL = 5 * time.Minute
+)
+
+// RefreshFunc will retrieve a fresh credential value.
+type RefreshFunc func() (Value, error)
+
+// Refreshable will cache credential value returned by RefreshFunc and refresh it while expired.
+//
+// Refreshable could be used to support temporary credentials, like sts or assume role tokens.
+type Refreshable struct {
+	fn     RefreshFunc
+	window time.Duration
+
+	value Value
+	valid bool
+	lock  sync.Mutex
+
+	// now is used to mock time in test.
+	now func() time.Time
+}
+
+// NewRefreshable will create a Refreshable provider.
+//
+// Value will be refreshed window before its expiration, value with zero expiration will never be refreshed.
+func NewRefreshable(fn RefreshFunc, window time.Duration) *Refreshable {
+	return &Refreshable{
+		fn:     fn,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// Retrieve implements Provider.Retrieve
+func (r *Refreshable) Retrieve() (Value, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.isExpired() {
+		return r.value, nil
+	}
+
+	v, err := r.fn()
+	if err != nil {
+		return Value{}, err
+	}
+	r.value, r.valid = v, true
+	return v, nil
+}
+
+// IsExpired implements Provider.IsExpired
+func (r *Refreshable) IsExpired() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.isExpired()
+}
+
+func (r *Refreshable) isExpired() bool {
+	if !r.valid {
+		return true
+	}
+	if r.value.Expiration.IsZero() {
+		return false
+	}
+	return !r.now().Add(r.window).Before(r.value.Expiration)
+}
+
+// String implements fmt.Stringer, secrets will never be included.
+func (r *Refreshable) String() string {
+	return "refreshable"
+}
+
+// NewEnvVar will create a provider which reads credential config string from env.
+//
+// ErrCredentialNotFound will be returned if env is not set.
+func NewEnvVar(name string) *Refreshable {
+	return NewRefreshable(func() (Value, error) {
+		errorMessage := "retrieve credential from env [%s]: %w"
+
+		cfg, ok := os.LookupEnv(name)
+		if !ok || cfg == "" {
+			return Value{}, fmt.Errorf(errorMessage, name, ErrCredentialNotFound)
+		}
+		p, err := Parse(cfg)
+		if err != nil {
+			return Value{}, fmt.Errorf(errorMessage, name, err)
+		}
+		return p.Retrieve()
+	}, 0)
+}
+
+// DefaultSharedFile will return shared credential file path.
+//
+// Path in env STORAGE_SHARED_CREDENTIAL_FILE will be used if set, or "storage/credentials.json"
+// in user's config dir will be returned.
+func DefaultSharedFile() string {
+	if path := os.Getenv(EnvSharedFile); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "storage", "credentials.json")
+}
+
+// NewSharedFile will create a provider which reads credential config string from a shared json file.
+//
+// Shared file maps profile name to credential config string, for example:
+//
+//	{
+//	  "default": "hmac:ak:sk"
+//	}
+//
+// File will be read again every 5 minutes to pick up rotated keys.
+// ErrCredentialNotFound will be returned if file or profile not exist.
+func NewSharedFile(path, profile string) *Refreshable {
+	r := NewRefreshable(nil, 0)
+	r.fn = func() (Value, error) {
+		errorMessage := "retrieve credential from shared file [%s] with profile [%s]: %w"
+
+		content, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			return Value{}, fmt.Errorf(errorMessage, path, profile, ErrCredentialNotFound)
+		}
+		if err != nil {
+			return Value{}, fmt.Errorf(errorMessage, path, profile, err)
+		}
+
+		var profiles map[string]string
+		err = json.Unmarshal(content, &profiles)
+		if err != nil {
+			return Value{}, fmt.Errorf(errorMessage, path, profile, err)
+		}
+		cfg, ok := profiles[profile]
+		if !ok {
+			return Value{}, fmt.Errorf(errorMessage, path, profile, ErrCredentialNotFound)
+		}
+
+		p, err := Parse(cfg)
+		if err != nil {
+			return Value{}, fmt.Errorf(errorMessage, path, profile, err)
+		}
+		v, _ := p.Retrieve()
+		v.Expiration = r.now().Add(sharedFileTTL)
+		return v, nil
+	}
+	return r
+}
@@ -0,0 +1,104 @@
+package credential
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshable(t *testing.T) {
+	now := time.Now()
+	called := 0
+
+	r := NewRefreshable(func() (Value, error) {
+		called++
+		return Value{
+			Protocol:   ProtocolSTS,
+			Args:       []string{"ak", "sk", "token"},
+			Expiration: now.Add(time.Hour),
+		}, nil
+	}, 5*time.Minute)
+	r.now = func() time.Time { return now }
+
+	assert.True(t, r.IsExpired())
+
+	v, err := r.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolSTS, v.Protocol)
+	assert.Equal(t, 1, called)
+	assert.False(t, r.IsExpired())
+
+	// Value should be cached before expired.
+	_, err = r.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, called)
+
+	// Value should be refreshed in expiry window.
+	r.now = func() time.Time { return now.Add(56 * time.Minute) }
+	assert.True(t, r.IsExpired())
+	_, err = r.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, called)
+}
+
+func TestRefreshable_Error(t *testing.T) {
+	expected := errors.New("refresh failed")
+
+	r := NewRefreshable(func() (Value, error) {
+		return Value{}, expected
+	}, 0)
+
+	_, err := r.Retrieve()
+	assert.True(t, errors.Is(err, expected))
+	assert.True(t, r.IsExpired())
+}
+
+func TestNewEnvVar(t *testing.T) {
+	name := "STORAGE_TEST_CREDENTIAL"
+
+	_, err := NewEnvVar(name).Retrieve()
+	assert.True(t, errors.Is(err, ErrCredentialNotFound))
+
+	os.Setenv(name, "hmac:ak:sk")
+	defer os.Unsetenv(name)
+
+	v, err := NewEnvVar(name).Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, Value{Protocol: ProtocolHmac, Args: []string{"ak", "sk"}}, v)
+}
+
+func TestNewSharedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "credentials.json")
+
+	_, err = NewSharedFile(path, "default").Retrieve()
+	assert.True(t, errors.Is(err, ErrCredentialNotFound))
+
+	err = ioutil.WriteFile(path, []byte(`{"default": "hmac:ak:sk"}`), 0600)
+	assert.NoError(t, err)
+
+	p := NewSharedFile(path, "default")
+	v, err := p.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolHmac, v.Protocol)
+	assert.Equal(t, []string{"ak", "sk"}, v.Args)
+
+	// Rotated keys should be picked up after ttl.
+	err = ioutil.WriteFile(path, []byte(`{"default": "hmac:ak:rotated"}`), 0600)
+	assert.NoError(t, err)
+	p.now = func() time.Time { return time.Now().Add(sharedFileTTL) }
+	v, err = p.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ak", "rotated"}, v.Args)
+
+	_, err = NewSharedFile(path, "not-exist").Retrieve()
+	assert.True(t, errors.Is(err, ErrCredentialNotFound))
+}
@@ -3,6 +3,8 @@ package endpoint
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"strconv"
 	"strings"
 )
@@ -24,36 +26,167 @@ type Value struct {
 	Protocol string
 	Host     string
 	Port     int
+	// Path is the base path for http and https, or the socket path for unix.
+	Path string
 }
 
 // String will compose all info into a valid URL.
 func (v Value) String() string {
-	return fmt.Sprintf("%s://%s:%d", v.Protocol, v.Host, v.Port)
+	if v.Protocol == ProtocolUnix {
+		return fmt.Sprintf("%s://%s", v.Protocol, v.Path)
+	}
+	return fmt.Sprintf("%s://%s%s", v.Protocol, net.JoinHostPort(v.Host, strconv.Itoa(v.Port)), v.Path)
+}
+
+// Format will format value into config string which could be parsed by Parse.
+//
+// Compact form "<protocol>:<host>:<port>" will be used if possible, or URL form will be used.
+func (v Value) Format() string {
+	if v.Protocol == ProtocolUnix || v.Path != "" {
+		return v.String()
+	}
+	return fmt.Sprintf("%s:%s", v.Protocol, net.JoinHostPort(v.Host, strconv.Itoa(v.Port)))
+}
+
+// CheckHostOnly will check whether value only contains protocol, host and port of http or https.
+//
+// Services whose SDK can't connect via unix socket or sign requests with base path should call it.
+func (v Value) CheckHostOnly() error {
+	if v.Protocol != ProtocolHTTPS && v.Protocol != ProtocolHTTP {
+		return fmt.Errorf("protocol %s: %w", v.Protocol, ErrUnsupportedProtocol)
+	}
+	if strings.Trim(v.Path, "/") != "" {
+		return fmt.Errorf("endpoint with path %s not supported: %w", v.Path, ErrInvalidConfig)
+	}
+	return nil
 }
 
 // Parse will parse config string to create a endpoint Provider.
+//
+// Following forms are supported:
+//
+//   - Compact form: "<protocol>:<host>[:<port>]", like "https:qingstor.com:443" or "http:[::1]:9000"
+//   - URL form: "<protocol>://<host>[:<port>][<path>]", like "https://example.com/s3"
+//   - Unix socket: "unix://<path>" or "unix:<path>", like "unix:///var/run/minio.sock"
+//
+// Port could be omitted for http (80) and https (443), IPv6 host must be bracketed.
+// Compact form could be used in config string, others contain "/" and could only be used
+// in profiles or called directly.
 func Parse(cfg string) (Provider, error) {
-	errorMessage := "parse credential config [%s]: <%w>"
+	errorMessage := "parse endpoint config [%s]: <%w>"
+
+	var v Value
+	var err error
+	if strings.Contains(cfg, "://") {
+		v, err = parseURL(cfg)
+	} else {
+		v, err = parseCompact(cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(errorMessage, cfg, err)
+	}
+	return Static{
+		protocol: v.Protocol,
+		host:     v.Host,
+		port:     v.Port,
+		path:     v.Path,
+	}, nil
+}
 
-	s := strings.Split(cfg, ":")
-	if len(s) < 2 {
-		return nil, fmt.Errorf(errorMessage, cfg, ErrInvalidConfig)
+// parseCompact will parse config string like "<protocol>:<host>[:<port>]".
+func parseCompact(cfg string) (v Value, err error) {
+	s := strings.SplitN(cfg, ":", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return v, ErrInvalidConfig
 	}
+	v.Protocol = s[0]
 
-	switch s[0] {
-	case ProtocolHTTPS:
-		port, err := strconv.ParseInt(s[2], 10, 64)
+	switch v.Protocol {
+	case ProtocolHTTPS, ProtocolHTTP:
+		v.Host, v.Port, err = parseHostPort(v.Protocol, s[1])
+		return v, err
+	case ProtocolUnix:
+		v.Path = s[1]
+		return v, nil
+	default:
+		return v, ErrUnsupportedProtocol
+	}
+}
+
+// parseURL will parse config string like "<protocol>://<host>[:<port>][<path>]".
+func parseURL(cfg string) (v Value, err error) {
+	u, err := url.Parse(cfg)
+	if err != nil {
+		return v, err
+	}
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return v, ErrInvalidConfig
+	}
+	v.Protocol = u.Scheme
+
+	switch v.Protocol {
+	case ProtocolHTTPS, ProtocolHTTP:
+		v.Host, v.Port, err = parseHostPort(v.Protocol, u.Host)
 		if err != nil {
-			return nil, fmt.Errorf(errorMessage, cfg, err)
+			return v, err
 		}
-		return NewHTTPS(s[1], int(port)), nil
-	case ProtocolHTTP:
-		port, err := strconv.ParseInt(s[2], 10, 64)
+		v.Path = strings.TrimSuffix(u.Path, "/")
+		return v, nil
+	case ProtocolUnix:
+		if u.Host != "" || u.Path == "" {
+			return v, ErrInvalidConfig
+		}
+		v.Path = u.Path
+		return v, nil
+	default:
+		return v, ErrUnsupportedProtocol
+	}
+}
+
+// parseHostPort will parse "<host>[:<port>]" with default port for protocol.
+func parseHostPort(protocol, s string) (host string, port int, err error) {
+	host, port = s, defaultPort(protocol)
+
+	// Port is given only if the last colon is after the bracketed IPv6 host.
+	if strings.LastIndex(s, ":") > strings.LastIndex(s, "]") {
+		if !strings.HasPrefix(s, "[") && strings.Count(s, ":") > 1 {
+			// Unbracketed IPv6 host is ambiguous with port.
+			return "", 0, ErrInvalidConfig
+		}
+
+		var p string
+		host, p, err = net.SplitHostPort(s)
+		if err != nil {
+			return "", 0, fmt.Errorf("%s: %w", err, ErrInvalidConfig)
+		}
+		port, err = strconv.Atoi(p)
 		if err != nil {
-			return nil, fmt.Errorf(errorMessage, cfg, err)
+			return "", 0, err
+		}
+		if port <= 0 || port > 65535 {
+			return "", 0, fmt.Errorf("port %d out of range: %w", port, ErrInvalidConfig)
+		}
+	} else if strings.HasPrefix(s, "[") {
+		if !strings.HasSuffix(s, "]") {
+			return "", 0, ErrInvalidConfig
 		}
-		return NewHTTP(s[1], int(port)), nil
+		host = s[1 : len(s)-1]
+	}
+
+	if host == "" {
+		return "", 0, ErrInvalidConfig
+	}
+	return host, port, nil
+}
+
+// defaultPort will return default port for protocol.
+func defaultPort(protocol string) int {
+	switch protocol {
+	case ProtocolHTTPS:
+		return 443
+	case ProtocolHTTP:
+		return 80
 	default:
-		return nil, fmt.Errorf(errorMessage, cfg, ErrUnsupportedProtocol)
+		return 0
 	}
 }
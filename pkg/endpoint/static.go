@@ -5,6 +5,8 @@ const (
 	ProtocolHTTPS = "https"
 	// ProtocolHTTP is the http credential protocol.
 	ProtocolHTTP = "http"
+	// ProtocolUnix is the unix socket protocol.
+	ProtocolUnix = "unix"
 )
 
 // Static is the static endpoint.
@@ -12,6 +14,7 @@ type Static struct {
 	protocol string
 	host     string
 	port     int
+	path     string
 }
 
 // Value implements Provider interface.
@@ -20,6 +23,7 @@ func (s Static) Value() Value {
 		Protocol: s.protocol,
 		Host:     s.host,
 		Port:     s.port,
+		Path:     s.path,
 	}
 }
 
@@ -40,3 +44,11 @@ func NewHTTP(host string, port int) Static {
 		port:     port,
 	}
 }
+
+// NewUnix will create a static endpoint for unix socket.
+func NewUnix(path string) Static {
+	return Static{
+		protocol: ProtocolUnix,
+		path:     path,
+	}
+}
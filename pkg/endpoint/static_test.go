@@ -25,6 +25,13 @@ func TestNewHTTP(t *testing.T) {
 	assert.Equal(t, port, s.port)
 }
 
+func TestNewUnix(t *testing.T) {
+	path := "/var/run/" + uuid.New().String()
+	s := NewUnix(path)
+	assert.Equal(t, ProtocolUnix, s.protocol)
+	assert.Equal(t, path, s.path)
+}
+
 func TestStatic_Value(t *testing.T) {
 	host := uuid.New().String()
 	port := 1024
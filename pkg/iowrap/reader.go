@@ -1,6 +1,7 @@
 package iowrap
 
 import (
+	"context"
 	"io"
 )
 
@@ -54,6 +55,50 @@ func (s *SectionedReadCloser) Close() error {
 	return s.r.Close()
 }
 
+// ContextReader will return a reader which stops reading once ctx is done.
+func ContextReader(ctx context.Context, r io.Reader) *ContextedReader {
+	return &ContextedReader{ctx, r}
+}
+
+// ContextedReader reads from underlying r and returns ctx's error once ctx is done.
+//
+// It's used to make long copies cancelable for services whose SDK doesn't support context.
+type ContextedReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read will check ctx before reading from underlying reader.
+func (c *ContextedReader) Read(p []byte) (n int, err error) {
+	select {
+	case <-c.ctx.Done():
+		return 0, c.ctx.Err()
+	default:
+		return c.r.Read(p)
+	}
+}
+
+// ContextReadCloser will return a read closer which stops reading once ctx is done.
+func ContextReadCloser(ctx context.Context, r io.ReadCloser) *ContextedReadCloser {
+	return &ContextedReadCloser{r, ContextReader(ctx, r)}
+}
+
+// ContextedReadCloser reads from underlying r and provide Close as well.
+type ContextedReadCloser struct {
+	r  io.ReadCloser
+	cr *ContextedReader
+}
+
+// Read will check ctx before reading from underlying reader.
+func (c *ContextedReadCloser) Read(p []byte) (n int, err error) {
+	return c.cr.Read(p)
+}
+
+// Close will close underlying reader.
+func (c *ContextedReadCloser) Close() error {
+	return c.r.Close()
+}
+
 // NewReadSeekCloser wraps a io.Reader returning a ReadSeekCloser. Allows the
 // SDK to accept an io.Reader that is not also an io.Seeker for unsigned
 // streaming payload API operations.
@@ -109,3 +154,26 @@ func (r ReadSeekCloser) Close() error {
 	}
 	return nil
 }
+
+// ReadBlocks will read all data from r into blocks of size and call fn with every block.
+//
+// Only the last block could be smaller than size, and no block will be passed for empty r.
+// The block passed to fn will be reused, so fn MUST NOT retain it.
+func ReadBlocks(r io.Reader, size int, fn func(block []byte) error) error {
+	buf := make([]byte, size)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n == 0 {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if err = fn(buf[:n]); err != nil {
+			return err
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package iowrap
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -195,6 +199,49 @@ func TestSectionedReadCloser_Close(t *testing.T) {
 	}
 }
 
+func TestContextedReader_Read(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("context not done", func(t *testing.T) {
+		r := NewMockReader(ctrl)
+		r.EXPECT().Read(gomock.Any()).Return(10, nil).Times(1)
+
+		x := ContextReader(context.Background(), r)
+		n, err := x.Read(make([]byte, 10))
+		assert.NoError(t, err)
+		assert.Equal(t, 10, n)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		r := NewMockReader(ctrl)
+		r.EXPECT().Read(gomock.Any()).Times(0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		x := ContextReader(ctx, r)
+		n, err := x.Read(make([]byte, 10))
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, 0, n)
+	})
+}
+
+func TestContextedReadCloser_Close(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := NewMockReader(ctrl)
+	c := NewMockCloser(ctrl)
+	c.EXPECT().Close().Times(1)
+
+	x := ContextReadCloser(context.Background(), struct {
+		io.Reader
+		io.Closer
+	}{r, c})
+	assert.NoError(t, x.Close())
+}
+
 func TestReadSeekCloser_Read(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -270,3 +317,34 @@ func TestReadSeekCloser_Close(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestReadBlocks(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		blocks []string
+	}{
+		{"empty", "", []string{}},
+		{"smaller than size", "ab", []string{"ab"}},
+		{"equal to size", "abc", []string{"abc"}},
+		{"multiple blocks", "abcdefg", []string{"abc", "def", "g"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := make([]string, 0)
+			err := ReadBlocks(strings.NewReader(tt.input), 3, func(block []byte) error {
+				blocks = append(blocks, string(block))
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.blocks, blocks)
+		})
+	}
+
+	expectErr := errors.New("test error")
+	err := ReadBlocks(bytes.NewReader(make([]byte, 10)), 3, func(block []byte) error {
+		return expectErr
+	})
+	assert.True(t, errors.Is(err, expectErr))
+}
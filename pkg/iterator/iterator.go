@@ -0,0 +1,124 @@
+/*
+Package iterator provided pull based iterator for list operations.
+
+Iterator fetches objects page by page via NextObjectPageFunc, and every page is fetched by the marker which returned
+by previous page. ContinuationToken is built from current page's marker and the count of objects consumed in this page,
+so caller could resume listing from where it stopped, even in services whose marker is opaque.
+*/
+package iterator
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Xuanwo/storage/types"
+)
+
+var (
+	// Done will be returned by Next while there are no more items.
+	Done = errors.New("no more items in iterator")
+
+	// ErrInvalidContinuationToken will be returned while input continuation token is malformed.
+	ErrInvalidContinuationToken = errors.New("invalid continuation token")
+)
+
+// NextObjectPageFunc will fetch a page of objects by marker.
+//
+// Implementer:
+//   - Empty marker means the first page.
+//   - MUST return empty next while this is the last page.
+type NextObjectPageFunc func(marker string) (objects []*types.Object, next string, err error)
+
+// ObjectIterator will iterate objects returned by NextObjectPageFunc.
+type ObjectIterator struct {
+	fn NextObjectPageFunc
+
+	marker  string // marker for current page
+	offset  int    // count of objects consumed in current page
+	next    string // marker for next page
+	objects []*types.Object
+	fetched bool
+}
+
+// NewObjectIterator will create a new object iterator, which will resume from token.
+//
+// Empty token means start from the beginning.
+func NewObjectIterator(fn NextObjectPageFunc, token string) (it *ObjectIterator, err error) {
+	it = &ObjectIterator{fn: fn}
+
+	it.marker, it.offset, err = parseContinuationToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+// Next will return next object, Done will be returned while there are no more objects.
+//
+// Caller could call Next again to retry after an error other than Done returned.
+func (it *ObjectIterator) Next() (o *types.Object, err error) {
+	for !it.fetched || it.offset >= len(it.objects) {
+		if it.fetched {
+			if it.next == "" {
+				return nil, Done
+			}
+			it.marker, it.offset = it.next, 0
+		}
+
+		objects, next, err := it.fn(it.marker)
+		if err != nil {
+			// Reset state so that we will fetch current page again.
+			it.fetched = false
+			return nil, err
+		}
+		it.objects, it.next, it.fetched = objects, next, true
+
+		// Objects in resumed page may be changed, don't overflow.
+		if it.offset > len(it.objects) {
+			it.offset = len(it.objects)
+		}
+	}
+
+	o = it.objects[it.offset]
+	it.offset++
+	return o, nil
+}
+
+// ContinuationToken will return an opaque token which points to the position after latest object returned by Next.
+//
+// Caller could pass it via pairs.WithContinuationToken to resume the listing.
+func (it *ObjectIterator) ContinuationToken() string {
+	return formatContinuationToken(it.marker, it.offset)
+}
+
+// formatContinuationToken will encode marker and offset into a token like base64("<offset>:<marker>").
+func formatContinuationToken(marker string, offset int) string {
+	if marker == "" && offset == 0 {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset) + ":" + marker))
+}
+
+func parseContinuationToken(token string) (marker string, offset int, err error) {
+	if token == "" {
+		return "", 0, nil
+	}
+
+	content, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", 0, fmt.Errorf("parse token %q: %w", token, ErrInvalidContinuationToken)
+	}
+
+	s := strings.SplitN(string(content), ":", 2)
+	if len(s) != 2 {
+		return "", 0, fmt.Errorf("parse token %q: %w", token, ErrInvalidContinuationToken)
+	}
+	offset, err = strconv.Atoi(s[0])
+	if err != nil || offset < 0 {
+		return "", 0, fmt.Errorf("parse token %q: %w", token, ErrInvalidContinuationToken)
+	}
+	return s[1], offset, nil
+}
@@ -0,0 +1,146 @@
+package iterator
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/types"
+)
+
+// pager will split 0..n-1 into pages with size, marker is the index of page's first object.
+func pager(n, size int) NextObjectPageFunc {
+	return func(marker string) ([]*types.Object, string, error) {
+		start := 0
+		if marker != "" {
+			start, _ = strconv.Atoi(marker)
+		}
+		end := start + size
+		if end >= n {
+			end = n
+		}
+
+		objects := make([]*types.Object, 0, end-start)
+		for i := start; i < end; i++ {
+			objects = append(objects, &types.Object{Name: strconv.Itoa(i)})
+		}
+		if end == n {
+			return objects, "", nil
+		}
+		return objects, strconv.Itoa(end), nil
+	}
+}
+
+func collect(t *testing.T, it *ObjectIterator, limit int) []string {
+	names := make([]string, 0)
+	for i := 0; limit < 0 || i < limit; i++ {
+		o, err := it.Next()
+		if errors.Is(err, Done) {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, o.Name)
+	}
+	return names
+}
+
+func TestObjectIterator_Next(t *testing.T) {
+	tests := []struct {
+		name   string
+		n      int
+		size   int
+		expect int
+	}{
+		{"empty", 0, 3, 0},
+		{"single page", 2, 3, 2},
+		{"full pages", 6, 3, 6},
+		{"multiple pages", 7, 3, 7},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			it, err := NewObjectIterator(pager(v.n, v.size), "")
+			assert.NoError(t, err)
+
+			names := collect(t, it, -1)
+			assert.Equal(t, v.expect, len(names))
+			for k, name := range names {
+				assert.Equal(t, strconv.Itoa(k), name)
+			}
+
+			// Done should be returned again.
+			_, err = it.Next()
+			assert.True(t, errors.Is(err, Done))
+		})
+	}
+}
+
+func TestObjectIterator_ContinuationToken(t *testing.T) {
+	for consumed := 0; consumed <= 7; consumed++ {
+		t.Run(strconv.Itoa(consumed), func(t *testing.T) {
+			it, err := NewObjectIterator(pager(7, 3), "")
+			assert.NoError(t, err)
+			first := collect(t, it, consumed)
+
+			it, err = NewObjectIterator(pager(7, 3), it.ContinuationToken())
+			assert.NoError(t, err)
+			rest := collect(t, it, -1)
+
+			assert.Equal(t, 7, len(first)+len(rest))
+			for k, name := range append(first, rest...) {
+				assert.Equal(t, strconv.Itoa(k), name)
+			}
+		})
+	}
+}
+
+func TestObjectIterator_Retry(t *testing.T) {
+	expectErr := errors.New("network error")
+	failed := false
+
+	fn := pager(5, 2)
+	it, err := NewObjectIterator(func(marker string) ([]*types.Object, string, error) {
+		if marker == "2" && !failed {
+			failed = true
+			return nil, "", expectErr
+		}
+		return fn(marker)
+	}, "")
+	assert.NoError(t, err)
+
+	names := collect(t, it, 2)
+	assert.Equal(t, []string{"0", "1"}, names)
+
+	_, err = it.Next()
+	assert.True(t, errors.Is(err, expectErr))
+
+	names = collect(t, it, -1)
+	assert.Equal(t, []string{"2", "3", "4"}, names)
+}
+
+func TestNewObjectIterator(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"empty token", "", nil},
+		{"valid token", formatContinuationToken("marker", 1), nil},
+		{"invalid base64", "!!!", ErrInvalidContinuationToken},
+		{"missing offset", "bWFya2Vy", ErrInvalidContinuationToken},
+		{"negative offset", formatContinuationToken("marker", -1), ErrInvalidContinuationToken},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := NewObjectIterator(pager(1, 1), v.token)
+			if v.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, v.err))
+			}
+		})
+	}
+}
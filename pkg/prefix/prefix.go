@@ -0,0 +1,89 @@
+/*
+Package prefix provided helpers for services which emulate dirs via key prefix.
+
+Services only need to implement a ListFunc which lists raw keys under a prefix page by page, checking whether a dir is
+empty and deleting a dir recursively are built on it, so all prefix based services behave the same.
+*/
+package prefix
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Xuanwo/storage/types"
+)
+
+// deleteAllPageSize is the count of keys listed and deleted in one page while DeleteAll.
+const deleteAllPageSize = 1000
+
+// ListFunc will list at most limit keys with prefix after marker, empty next means there are no more keys.
+type ListFunc func(ctx context.Context, prefix, marker string, limit int) (keys []string, next string, err error)
+
+// DeleteBatchFunc will delete multiple objects by path, like storage.BatchDeleter.
+type DeleteBatchFunc func(ctx context.Context, paths []string, pairs ...*types.Pair) (results []types.DeleteResult, err error)
+
+// CheckDirEmpty will return types.ErrDirNotEmpty if there are any keys with prefix dir except dir itself.
+func CheckDirEmpty(ctx context.Context, dir string, list ListFunc) error {
+	// Only the first two keys are needed, dir itself could be one of them.
+	keys, _, err := list(ctx, dir, "", 2)
+	if err != nil {
+		return err
+	}
+	for _, v := range keys {
+		if v != dir {
+			return types.ErrDirNotEmpty
+		}
+	}
+	return nil
+}
+
+/*
+DeleteAll will delete key and all keys under it.
+
+Keys are listed page by page via list, converted into paths via relPath and deleted via deleteBatch. The key itself is
+treated as a dir, and the file with the same name will be deleted too. Keys which don't exist will be ignored.
+
+Caller should make sure key is not the root, or all keys in the bucket will be deleted.
+*/
+func DeleteAll(ctx context.Context, key string, list ListFunc, relPath func(key string) string, deleteBatch DeleteBatchFunc) (err error) {
+	dir := key
+	if dir != "" && !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+
+	paths := make([]string, 0, deleteAllPageSize)
+	// key could also be a file which has the same name with dir.
+	if dir != key {
+		paths = append(paths, relPath(key))
+	}
+
+	marker := ""
+	for {
+		keys, next, err := list(ctx, dir, marker, deleteAllPageSize)
+		if err != nil {
+			return err
+		}
+		for _, v := range keys {
+			paths = append(paths, relPath(v))
+		}
+
+		if len(paths) > 0 {
+			results, err := deleteBatch(ctx, paths)
+			if err != nil {
+				return err
+			}
+			for _, v := range results {
+				if v.Err != nil && !errors.Is(v.Err, types.ErrObjectNotExist) {
+					return v.Err
+				}
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+		marker = next
+		paths = paths[:0]
+	}
+}
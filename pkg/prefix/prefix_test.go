@@ -0,0 +1,74 @@
+package prefix
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/types"
+)
+
+// newListFunc will create a ListFunc which lists keys in sorted order.
+func newListFunc(keys []string) ListFunc {
+	sort.Strings(keys)
+	return func(ctx context.Context, prefix, marker string, limit int) ([]string, string, error) {
+		page := make([]string, 0, limit)
+		for _, v := range keys {
+			if !strings.HasPrefix(v, prefix) || v <= marker {
+				continue
+			}
+			if len(page) == limit {
+				return page, page[len(page)-1], nil
+			}
+			page = append(page, v)
+		}
+		return page, "", nil
+	}
+}
+
+func TestCheckDirEmpty(t *testing.T) {
+	list := newListFunc([]string{"a/", "b/", "b/c", "d"})
+
+	assert.NoError(t, CheckDirEmpty(context.Background(), "a/", list))
+	assert.NoError(t, CheckDirEmpty(context.Background(), "e/", list))
+	assert.True(t, errors.Is(CheckDirEmpty(context.Background(), "b/", list), types.ErrDirNotEmpty))
+}
+
+func TestDeleteAll(t *testing.T) {
+	keys := []string{"prefix/a", "prefix/a/", "prefix/ab", "prefix/b"}
+	for i := 0; i < 2*deleteAllPageSize; i++ {
+		keys = append(keys, "prefix/a/"+strings.Repeat("x", i+1))
+	}
+
+	deleted := make([]string, 0)
+	err := DeleteAll(context.Background(), "prefix/a", newListFunc(keys), func(key string) string {
+		return strings.TrimPrefix(key, "prefix/")
+	}, func(ctx context.Context, paths []string, pairs ...*types.Pair) ([]types.DeleteResult, error) {
+		assert.LessOrEqual(t, len(paths), deleteAllPageSize+1)
+
+		results := make([]types.DeleteResult, len(paths))
+		for k, v := range paths {
+			results[k].Path = v
+			deleted = append(deleted, v)
+		}
+		// Deleting objects which don't exist should be ignored.
+		results[0].Err = types.ErrObjectNotExist
+		return results, nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, deleted, 2*deleteAllPageSize+2)
+	assert.NotContains(t, deleted, "ab")
+	assert.NotContains(t, deleted, "b")
+
+	expectErr := errors.New("delete error")
+	err = DeleteAll(context.Background(), "prefix/a", newListFunc(keys), func(key string) string {
+		return key
+	}, func(ctx context.Context, paths []string, pairs ...*types.Pair) ([]types.DeleteResult, error) {
+		return []types.DeleteResult{{Path: paths[0], Err: expectErr}}, nil
+	})
+	assert.True(t, errors.Is(err, expectErr))
+}
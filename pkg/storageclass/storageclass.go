@@ -23,6 +23,31 @@ const (
 	//   - Cold only support write operation
 	//   - Depends on services' implementations, `Cold` storage class may need extra time (minutes to hours,
 	//     except `gcs`) or extra API (`kodo`) even manual application (`cos`).
-	//     So we will not add extra support to read `Cold` data, just return the error.
+	//   - Read `Cold` data which is not restored will return `ErrObjectArchived`, services implement
+	//     `Restorer` could restore them temporarily.
 	Cold Type = "cold"
 )
+
+// RestoreTier is the tier for restoring Cold data, which decides how long restoring takes and how much it costs.
+type RestoreTier string
+
+const (
+	// RestoreTierExpedited is the fastest and most expensive tier, usually takes minutes.
+	RestoreTierExpedited RestoreTier = "expedited"
+	// RestoreTierStandard is the default tier for most services, usually takes hours.
+	RestoreTierStandard RestoreTier = "standard"
+	// RestoreTierBulk is the slowest and cheapest tier, usually takes more than 5 hours.
+	RestoreTierBulk RestoreTier = "bulk"
+)
+
+// RestoreStatus is the restore status of Cold data.
+type RestoreStatus string
+
+const (
+	// RestoreStatusArchived means data is archived and not restored, it can't be read.
+	RestoreStatusArchived RestoreStatus = "archived"
+	// RestoreStatusRestoring means data is being restored.
+	RestoreStatusRestoring RestoreStatus = "restoring"
+	// RestoreStatusRestored means data has been restored temporarily, it could be read now.
+	RestoreStatusRestored RestoreStatus = "restored"
+)
@@ -0,0 +1,32 @@
+/*
+Package tagging provided helpers for services which carry object tags as query string, like `a=1&b=x+y`.
+
+Tags are encoded with sorted keys, so the same tags always have the same string.
+*/
+package tagging
+
+import (
+	"net/url"
+)
+
+// Encode will encode tags into query string, empty tags will be encoded into an empty string.
+func Encode(tags map[string]string) string {
+	values := url.Values{}
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// Decode will decode query string into tags, only the first value will be kept if a key is duplicated.
+func Decode(s string) (map[string]string, error) {
+	values, err := url.ParseQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, len(values))
+	for k := range values {
+		tags[k] = values.Get(k)
+	}
+	return tags, nil
+}
@@ -0,0 +1,34 @@
+package tagging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, "", Encode(nil))
+	assert.Equal(t, "a=1&b=x+y", Encode(map[string]string{"b": "x y", "a": "1"}))
+}
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected map[string]string
+		hasErr   bool
+	}{
+		{"empty", "", map[string]string{}, false},
+		{"normal", "a=1&b=x+y", map[string]string{"a": "1", "b": "x y"}, false},
+		{"duplicated", "a=1&a=2", map[string]string{"a": "1"}, false},
+		{"invalid", "a=%zz", nil, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := Decode(tt.input)
+			assert.Equal(t, tt.hasErr, err != nil)
+			assert.Equal(t, tt.expected, tags)
+		})
+	}
+}
@@ -4,12 +4,14 @@ package azblob
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 
 	"github.com/Xuanwo/storage"
 	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/pkg/iterator"
 	"github.com/Xuanwo/storage/pkg/segment"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
@@ -19,9 +21,11 @@ import (
 
 var _ credential.Provider
 var _ endpoint.Provider
+var _ iterator.ObjectIterator
 var _ segment.Segment
 var _ storage.Storager
 var _ storageclass.Type
+var _ time.Duration
 
 // Type is the type for azblob
 const Type = "azblob"
@@ -162,7 +166,7 @@ type pairServiceNew struct {
 
 	// Meta-defined pairs
 	HasCredential bool
-	Credential    *credential.Provider
+	Credential    credential.Provider
 	HasEndpoint   bool
 	Endpoint      endpoint.Provider
 }
@@ -193,7 +197,7 @@ func parseServicePairNew(opts ...*types.Pair) (*pairServiceNew, error) {
 	}
 	if ok {
 		result.HasCredential = true
-		result.Credential = v.(*credential.Provider)
+		result.Credential = v.(credential.Provider)
 	}
 	v, ok = values[ps.Endpoint]
 	if !ok {
@@ -211,6 +215,16 @@ type pairStorageDelete struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasVersionID         bool
+	VersionID            string
 }
 
 func parseStoragePairDelete(opts ...*types.Pair) (*pairStorageDelete, error) {
@@ -233,6 +247,31 @@ func parseStoragePairDelete(opts ...*types.Pair) (*pairStorageDelete, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.VersionID]
+	if ok {
+		result.HasVersionID = true
+		result.VersionID = v.(string)
+	}
 	return result, nil
 }
 
@@ -241,8 +280,10 @@ type pairStorageInit struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasWorkDir bool
-	WorkDir    string
+	HasStorageClass bool
+	StorageClass    storageclass.Type
+	HasWorkDir      bool
+	WorkDir         string
 }
 
 func parseStoragePairInit(opts ...*types.Pair) (*pairStorageInit, error) {
@@ -265,6 +306,11 @@ func parseStoragePairInit(opts ...*types.Pair) (*pairStorageInit, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.StorageClass]
+	if ok {
+		result.HasStorageClass = true
+		result.StorageClass = v.(storageclass.Type)
+	}
 	v, ok = values[ps.WorkDir]
 	if ok {
 		result.HasWorkDir = true
@@ -273,6 +319,73 @@ func parseStoragePairInit(opts ...*types.Pair) (*pairStorageInit, error) {
 	return result, nil
 }
 
+type pairStorageInitAppend struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairInitAppend(opts ...*types.Pair) (*pairStorageInitAppend, error) {
+	result := &pairStorageInitAppend{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageIterate struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+	HasContinuationToken bool
+	ContinuationToken    string
+}
+
+func parseStoragePairIterate(opts ...*types.Pair) (*pairStorageIterate, error) {
+	result := &pairStorageIterate{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	v, ok = values[ps.ContinuationToken]
+	if ok {
+		result.HasContinuationToken = true
+		result.ContinuationToken = v.(string)
+	}
+	return result, nil
+}
+
 type pairStorageList struct {
 	// Pre-defined pairs
 	Context context.Context
@@ -313,6 +426,46 @@ func parseStoragePairList(opts ...*types.Pair) (*pairStorageList, error) {
 	return result, nil
 }
 
+type pairStorageListVersions struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+	HasFileFunc bool
+	FileFunc    types.ObjectFunc
+}
+
+func parseStoragePairListVersions(opts ...*types.Pair) (*pairStorageListVersions, error) {
+	result := &pairStorageListVersions{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	v, ok = values[ps.FileFunc]
+	if !ok {
+		return nil, types.NewErrPairRequired(ps.FileFunc)
+	}
+	if ok {
+		result.HasFileFunc = true
+		result.FileFunc = v.(types.ObjectFunc)
+	}
+	return result, nil
+}
+
 type pairStorageMetadata struct {
 	// Pre-defined pairs
 	Context context.Context
@@ -348,6 +501,18 @@ type pairStorageRead struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasVerifyChecksum    bool
+	VerifyChecksum       bool
+	HasVersionID         bool
+	VersionID            string
 }
 
 func parseStoragePairRead(opts ...*types.Pair) (*pairStorageRead, error) {
@@ -369,6 +534,66 @@ func parseStoragePairRead(opts ...*types.Pair) (*pairStorageRead, error) {
 		result.Context = context.Background()
 	}
 
+	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.VerifyChecksum]
+	if ok {
+		result.HasVerifyChecksum = true
+		result.VerifyChecksum = v.(bool)
+	}
+	v, ok = values[ps.VersionID]
+	if ok {
+		result.HasVersionID = true
+		result.VersionID = v.(string)
+	}
+	return result, nil
+}
+
+type pairStorageRestore struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairRestore(opts ...*types.Pair) (*pairStorageRestore, error) {
+	result := &pairStorageRestore{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
 	// Parse meta-defined pairs
 	return result, nil
 }
@@ -378,6 +603,16 @@ type pairStorageStat struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasVersionID         bool
+	VersionID            string
 }
 
 func parseStoragePairStat(opts ...*types.Pair) (*pairStorageStat, error) {
@@ -399,6 +634,61 @@ func parseStoragePairStat(opts ...*types.Pair) (*pairStorageStat, error) {
 		result.Context = context.Background()
 	}
 
+	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.VersionID]
+	if ok {
+		result.HasVersionID = true
+		result.VersionID = v.(string)
+	}
+	return result, nil
+}
+
+type pairStorageTransit struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairTransit(opts ...*types.Pair) (*pairStorageTransit, error) {
+	result := &pairStorageTransit{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
 	// Parse meta-defined pairs
 	return result, nil
 }
@@ -408,12 +698,30 @@ type pairStorageWrite struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasChecksum     bool
-	Checksum        string
-	HasSize         bool
-	Size            int64
-	HasStorageClass bool
-	StorageClass    storageclass.Type
+	HasCacheControl       bool
+	CacheControl          string
+	HasChecksum           bool
+	Checksum              string
+	HasContentDisposition bool
+	ContentDisposition    string
+	HasContentEncoding    bool
+	ContentEncoding       string
+	HasContentType        bool
+	ContentType           string
+	HasIfMatch            bool
+	IfMatch               string
+	HasIfModifiedSince    bool
+	IfModifiedSince       time.Time
+	HasIfNoneMatch        bool
+	IfNoneMatch           string
+	HasIfUnmodifiedSince  bool
+	IfUnmodifiedSince     time.Time
+	HasSize               bool
+	Size                  int64
+	HasStorageClass       bool
+	StorageClass          storageclass.Type
+	HasUserMetadata       bool
+	UserMetadata          map[string]string
 }
 
 func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
@@ -436,11 +744,51 @@ func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.CacheControl]
+	if ok {
+		result.HasCacheControl = true
+		result.CacheControl = v.(string)
+	}
 	v, ok = values[ps.Checksum]
 	if ok {
 		result.HasChecksum = true
 		result.Checksum = v.(string)
 	}
+	v, ok = values[ps.ContentDisposition]
+	if ok {
+		result.HasContentDisposition = true
+		result.ContentDisposition = v.(string)
+	}
+	v, ok = values[ps.ContentEncoding]
+	if ok {
+		result.HasContentEncoding = true
+		result.ContentEncoding = v.(string)
+	}
+	v, ok = values[ps.ContentType]
+	if ok {
+		result.HasContentType = true
+		result.ContentType = v.(string)
+	}
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
 	v, ok = values[ps.Size]
 	if !ok {
 		return nil, types.NewErrPairRequired(ps.Size)
@@ -454,6 +802,41 @@ func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
 		result.HasStorageClass = true
 		result.StorageClass = v.(storageclass.Type)
 	}
+	v, ok = values[ps.UserMetadata]
+	if ok {
+		result.HasUserMetadata = true
+		result.UserMetadata = v.(map[string]string)
+	}
+	return result, nil
+}
+
+type pairStorageWriteAppend struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairWriteAppend(opts ...*types.Pair) (*pairStorageWriteAppend, error) {
+	result := &pairStorageWriteAppend{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
 	return result, nil
 }
 
@@ -511,6 +894,24 @@ func (s *Storage) InitWithContext(ctx context.Context, pairs ...*types.Pair) (er
 	return s.Init(pairs...)
 }
 
+// InitAppendWithContext adds context support for InitAppend.
+func (s *Storage) InitAppendWithContext(ctx context.Context, path string, pairs ...*types.Pair) (offset int64, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/azblob.storage.InitAppend")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.InitAppend(path, pairs...)
+}
+
+// IterateWithContext adds context support for Iterate.
+func (s *Storage) IterateWithContext(ctx context.Context, path string, pairs ...*types.Pair) (it *iterator.ObjectIterator, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/azblob.storage.Iterate")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.Iterate(path, pairs...)
+}
+
 // ListWithContext adds context support for List.
 func (s *Storage) ListWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/azblob.storage.List")
@@ -520,6 +921,15 @@ func (s *Storage) ListWithContext(ctx context.Context, path string, pairs ...*ty
 	return s.List(path, pairs...)
 }
 
+// ListVersionsWithContext adds context support for ListVersions.
+func (s *Storage) ListVersionsWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/azblob.storage.ListVersions")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.ListVersions(path, pairs...)
+}
+
 // MetadataWithContext adds context support for Metadata.
 func (s *Storage) MetadataWithContext(ctx context.Context, pairs ...*types.Pair) (m metadata.StorageMeta, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/azblob.storage.Metadata")
@@ -538,6 +948,15 @@ func (s *Storage) ReadWithContext(ctx context.Context, path string, pairs ...*ty
 	return s.Read(path, pairs...)
 }
 
+// RestoreWithContext adds context support for Restore.
+func (s *Storage) RestoreWithContext(ctx context.Context, path string, days int, tier storageclass.RestoreTier, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/azblob.storage.Restore")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.Restore(path, days, tier, pairs...)
+}
+
 // StatWithContext adds context support for Stat.
 func (s *Storage) StatWithContext(ctx context.Context, path string, pairs ...*types.Pair) (o *types.Object, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/azblob.storage.Stat")
@@ -547,6 +966,15 @@ func (s *Storage) StatWithContext(ctx context.Context, path string, pairs ...*ty
 	return s.Stat(path, pairs...)
 }
 
+// TransitWithContext adds context support for Transit.
+func (s *Storage) TransitWithContext(ctx context.Context, path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/azblob.storage.Transit")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.Transit(path, storageClass, pairs...)
+}
+
 // WriteWithContext adds context support for Write.
 func (s *Storage) WriteWithContext(ctx context.Context, path string, r io.Reader, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/azblob.storage.Write")
@@ -555,3 +983,12 @@ func (s *Storage) WriteWithContext(ctx context.Context, path string, r io.Reader
 	pairs = append(pairs, ps.WithContext(ctx))
 	return s.Write(path, r, pairs...)
 }
+
+// WriteAppendWithContext adds context support for WriteAppend.
+func (s *Storage) WriteAppendWithContext(ctx context.Context, path string, offset int64, r io.Reader, pairs ...*types.Pair) (next int64, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/azblob.storage.WriteAppend")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.WriteAppend(path, offset, r, pairs...)
+}
@@ -0,0 +1,26 @@
+package azblob
+
+import (
+	"github.com/Xuanwo/storage"
+	"github.com/Xuanwo/storage/coreutils"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+func init() {
+	coreutils.Register(Type, open)
+}
+
+// open implements coreutils.OpenFunc
+func open(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
+	err = coreutils.CheckOptions(opt, pairs.Credential, pairs.Endpoint, pairs.StorageClass, pairs.WorkDir)
+	if err != nil {
+		return
+	}
+	srv, err = New(opt...)
+	if err != nil {
+		return
+	}
+	store, err = coreutils.OpenObjectStorage(srv, ns, opt...)
+	return
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/Azure/azure-storage-blob-go/azblob"
 
 	"github.com/Xuanwo/storage"
-	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/types"
 )
 
@@ -28,28 +27,32 @@ type Service struct {
 //
 // Our Service will store a ServiceURL for operation.
 func New(pairs ...*types.Pair) (s *Service, err error) {
-	const errorMessage = "%s New: %w"
-
 	s = &Service{}
 
 	opt, err := parseServicePairNew(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
-	primaryURL, _ := url.Parse(opt.Endpoint.Value().String())
-
-	credProtocol, credValue := opt.Credential.Protocol(), opt.Credential.Value()
-	if credProtocol != credential.ProtocolHmac {
-		return nil, fmt.Errorf(errorMessage, s, credential.ErrUnsupportedProtocol)
+	ep := opt.Endpoint.Value()
+	// Blob URLs are composed of account endpoint, container and blob name, so endpoint can't have base path.
+	if err = ep.CheckHostOnly(); err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	primaryURL, err := url.Parse(ep.String())
+	if err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
-	cred, err := azblob.NewSharedKeyCredential(credValue[0], credValue[1])
+	cred, err := newSharedKeyCredential(opt.Credential)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
-	p := azblob.NewPipeline(cred, azblob.PipelineOptions{})
+	p := azblob.NewPipeline(&credentialPolicyFactory{
+		SharedKeyCredential: cred,
+		provider:            opt.Credential,
+	}, azblob.PipelineOptions{})
 	s.service = azblob.NewServiceURL(*primaryURL, p)
 	return
 }
@@ -61,11 +64,9 @@ func (s *Service) String() string {
 
 // List implements Servicer.List
 func (s *Service) List(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List: %w"
-
 	opt, err := parseServicePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("List", s, "", pairs, err)
 	}
 
 	marker := azblob.Marker{}
@@ -74,7 +75,8 @@ func (s *Service) List(pairs ...*types.Pair) (err error) {
 		output, err = s.service.ListContainersSegment(opt.Context,
 			marker, azblob.ListContainersSegmentOptions{})
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, err)
+			err = handleAzblobError(err)
+			return types.NewError("List", s, "", pairs, err)
 		}
 
 		for _, v := range output.ContainerItems {
@@ -100,34 +102,32 @@ func (s *Service) Get(name string, pairs ...*types.Pair) (storage.Storager, erro
 
 // Create implements Servicer.Create
 func (s *Service) Create(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Create [%s]: %w"
-
 	opt, err := parseServicePairCreate(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 
 	bucket := s.service.NewContainerURL(name)
 	_, err = bucket.Create(opt.Context, azblob.Metadata{}, azblob.PublicAccessNone)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		err = handleAzblobError(err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 	return newStorage(bucket, name), nil
 }
 
 // Delete implements Servicer.Delete
 func (s *Service) Delete(name string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
-
 	opt, err := parseServicePairDelete(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 
 	bucket := s.service.NewContainerURL(name)
 	_, err = bucket.Delete(opt.Context, azblob.ContainerAccessConditions{})
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		err = handleAzblobError(err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 	return nil
 }
@@ -0,0 +1,35 @@
+package azblob
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(
+		pairs.WithCredential(credential.MustNewHmac("ak", "c2VjcmV0")),
+		pairs.WithEndpoint(endpoint.NewHTTPS("account.blob.core.windows.net", 443)),
+	)
+	assert.NoError(t, err)
+
+	// Endpoint which azblob SDK could not use.
+	for v, expected := range map[string]error{
+		"unix:/tmp/azblob.sock":        endpoint.ErrUnsupportedProtocol,
+		"http://127.0.0.1:8080/prefix": endpoint.ErrInvalidConfig,
+	} {
+		ep, err := endpoint.Parse(v)
+		assert.NoError(t, err)
+
+		_, err = New(
+			pairs.WithCredential(credential.MustNewHmac("ak", "c2VjcmV0")),
+			pairs.WithEndpoint(ep),
+		)
+		assert.True(t, errors.Is(err, expected), v)
+	}
+}
@@ -1,13 +1,19 @@
 package azblob
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 
 	"github.com/Azure/azure-storage-blob-go/azblob"
 
+	"github.com/Xuanwo/storage/pkg/checksum"
 	"github.com/Xuanwo/storage/pkg/iowrap"
+	"github.com/Xuanwo/storage/pkg/iterator"
+	"github.com/Xuanwo/storage/pkg/prefix"
+	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/metadata"
 )
@@ -18,8 +24,9 @@ import (
 type Storage struct {
 	bucket azblob.ContainerURL
 
-	name    string
-	workDir string
+	name         string
+	workDir      string
+	storageClass storageclass.Type
 }
 
 // newStorage will create a new client.
@@ -41,17 +48,22 @@ func (s *Storage) String() string {
 
 // Init implements Storager.Init
 func (s *Storage) Init(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Init: %w"
-
 	opt, err := parseStoragePairInit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("Init", s, "", pairs, err)
 	}
 
 	if opt.HasWorkDir {
 		// TODO: we should validate workDir
 		s.workDir = strings.TrimLeft(opt.WorkDir, "/")
 	}
+	if opt.HasStorageClass {
+		// Storage class will be used as default for Write, validate it here to fail fast.
+		if _, err = parseStorageClass(opt.StorageClass); err != nil {
+			return types.NewError("Init", s, "", pairs, err)
+		}
+		s.storageClass = opt.StorageClass
+	}
 
 	return nil
 }
@@ -61,112 +73,159 @@ func (s *Storage) Metadata(pairs ...*types.Pair) (m metadata.StorageMeta, err er
 	m = metadata.NewStorageMeta()
 	m.Name = s.name
 	m.WorkDir = s.workDir
+	m.SetFlatList(true)
 	return m, nil
 }
 
 // List implements Storager.List
 func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List [%s]: %w"
-
 	opt, err := parseStoragePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("List", s, path, pairs, err)
 	}
 
+	marker := ""
 	rp := s.getAbsPath(path)
 
-	marker := azblob.Marker{}
-	var output *azblob.ListBlobsFlatSegmentResponse
 	for {
-		output, err = s.bucket.ListBlobsFlatSegment(opt.Context, marker, azblob.ListBlobsSegmentOptions{
-			Prefix: rp,
-		})
+		objects, next, err := s.listObjects(opt.Context, rp, marker)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			return types.NewError("List", s, path, pairs, err)
 		}
 
-		for _, v := range output.Segment.BlobItems {
-			o := &types.Object{
-				ID:         v.Name,
-				Name:       s.getRelPath(v.Name),
-				Type:       types.ObjectTypeDir,
-				Size:       *v.Properties.ContentLength,
-				UpdatedAt:  v.Properties.LastModified,
-				ObjectMeta: metadata.NewObjectMeta(),
+		for _, o := range objects {
+			if opt.HasFileFunc {
+				opt.FileFunc(o)
 			}
-			o.SetContentType(*v.Properties.ContentType)
-			o.SetContentMD5(string(v.Properties.ContentMD5))
-
-			storageClass, err := formatStorageClass(v.Properties.AccessTier)
-			if err != nil {
-				return fmt.Errorf(errorMessage, s, path, err)
-			}
-			o.SetStorageClass(storageClass)
-
-			opt.FileFunc(o)
 		}
 
-		marker = output.NextMarker
-		if !marker.NotDone() {
-			break
+		if next == "" {
+			return nil
 		}
+		marker = next
+	}
+}
+
+// Iterate implements Storager.Iterate
+func (s *Storage) Iterate(path string, pairs ...*types.Pair) (it *iterator.ObjectIterator, err error) {
+	opt, err := parseStoragePairIterate(pairs...)
+	if err != nil {
+		return nil, types.NewError("Iterate", s, path, pairs, err)
 	}
 
-	return
+	rp := s.getAbsPath(path)
+
+	it, err = iterator.NewObjectIterator(func(marker string) ([]*types.Object, string, error) {
+		objects, next, err := s.listObjects(opt.Context, rp, marker)
+		if err != nil {
+			return nil, "", types.NewError("Iterate", s, path, pairs, err)
+		}
+		return objects, next, nil
+	}, opt.ContinuationToken)
+	if err != nil {
+		return nil, types.NewError("Iterate", s, path, pairs, err)
+	}
+	return it, nil
 }
 
 // Read implements Storager.Read
 func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err error) {
-	const errorMessage = "%s Read [%s]: %w"
-
 	opt, err := parseStoragePairRead(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Read", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
-	output, err := s.bucket.NewBlockBlobURL(rp).Download(opt.Context, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false)
+	output, err := s.bucket.NewBlockBlobURL(rp).WithSnapshot(opt.VersionID).Download(opt.Context, 0, azblob.CountToEnd,
+		parseAccessConditions(opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince), false)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleAzblobError(err)
+		return nil, types.NewError("Read", s, path, pairs, err)
 	}
-	return output.Body(azblob.RetryReaderOptions{}), nil
+
+	r = output.Body(azblob.RetryReaderOptions{})
+	if opt.HasVerifyChecksum && opt.VerifyChecksum {
+		m := metadata.NewObjectMeta()
+		if md5 := output.ContentMD5(); len(md5) > 0 {
+			m.SetContentMD5(checksum.FormatBytes(md5))
+		}
+
+		vr, err := checksum.NewVerifyReadCloser(r, m)
+		if err != nil {
+			r.Close()
+			return nil, types.NewError("Read", s, path, pairs, err)
+		}
+		r = vr
+	}
+	return r, nil
 }
 
 // Write implements Storager.Write
 func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Write [%s]: %w"
-
 	opt, err := parseStoragePairWrite(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	if !opt.HasStorageClass && s.storageClass != "" {
+		// Storage class set in Init will be used as default.
+		opt.HasStorageClass, opt.StorageClass = true, s.storageClass
 	}
 
 	rp := s.getAbsPath(path)
 
-	// TODO: add checksum and storage class support.
-	_, err = s.bucket.NewBlockBlobURL(rp).Upload(opt.Context, iowrap.NewReadSeekCloser(r),
-		azblob.BlobHTTPHeaders{}, azblob.Metadata{}, azblob.BlobAccessConditions{})
+	meta := azblob.Metadata{}
+	if opt.HasUserMetadata {
+		meta = opt.UserMetadata
+	}
+	headers := azblob.BlobHTTPHeaders{
+		ContentType:        opt.ContentType,
+		ContentEncoding:    opt.ContentEncoding,
+		ContentDisposition: opt.ContentDisposition,
+		CacheControl:       opt.CacheControl,
+	}
+
+	var tier azblob.AccessTierType
+	if opt.HasStorageClass {
+		tier, err = parseStorageClass(opt.StorageClass)
+		if err != nil {
+			return types.NewError("Write", s, path, pairs, err)
+		}
+	}
+
+	// TODO: add checksum support.
+	blob := s.bucket.NewBlockBlobURL(rp)
+	_, err = blob.Upload(opt.Context, iowrap.NewReadSeekCloser(r),
+		headers, meta, parseAccessConditions(opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince))
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleAzblobError(err)
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	// Upload in this SDK version can't set access tier, so we have to set it after upload.
+	if opt.HasStorageClass {
+		_, err = blob.SetTier(opt.Context, tier, azblob.LeaseAccessConditions{})
+		if err != nil {
+			err = handleAzblobError(err)
+			return types.NewError("Write", s, path, pairs, err)
+		}
 	}
 	return nil
 }
 
 // Stat implements Storager.Stat
 func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err error) {
-	const errorMessage = "%s Stat [%s]: %w"
-
 	opt, err := parseStoragePairStat(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
-	output, err := s.bucket.NewBlockBlobURL(rp).GetProperties(opt.Context, azblob.BlobAccessConditions{})
+	output, err := s.bucket.NewBlockBlobURL(rp).WithSnapshot(opt.VersionID).GetProperties(opt.Context,
+		parseAccessConditions(opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince))
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleAzblobError(err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	o = &types.Object{
@@ -177,30 +236,253 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 		UpdatedAt:  output.LastModified(),
 		ObjectMeta: metadata.NewObjectMeta(),
 	}
+	o.SetETag(string(output.ETag()))
+	if md5 := output.ContentMD5(); len(md5) > 0 {
+		o.SetContentMD5(checksum.FormatBytes(md5))
+	}
+	if v := output.ContentType(); v != "" {
+		o.SetContentType(v)
+	}
+	if v := output.ContentEncoding(); v != "" {
+		o.SetContentEncoding(v)
+	}
+	if v := output.ContentDisposition(); v != "" {
+		o.SetContentDisposition(v)
+	}
+	if v := output.CacheControl(); v != "" {
+		o.SetCacheControl(v)
+	}
 
 	storageClass, err := formatStorageClass(azblob.AccessTierType(output.AccessTier()))
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 	o.SetStorageClass(storageClass)
+	if storageClass == storageclass.Cold {
+		o.SetRestoreStatus(formatRestoreStatus(azblob.ArchiveStatusType(output.ArchiveStatus())))
+	}
+	if meta := output.NewMetadata(); len(meta) > 0 {
+		o.SetUserMetadata(meta)
+	}
+	if opt.HasVersionID {
+		o.SetVersionID(opt.VersionID)
+	}
 	return o, nil
 }
 
+// ListVersions implements Storager.ListVersions
+//
+// azblob uses snapshots as versions, the current blob will be returned first with an empty version id.
+func (s *Storage) ListVersions(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairListVersions(pairs...)
+	if err != nil {
+		return types.NewError("ListVersions", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	objects := make([]*types.Object, 0)
+	marker := azblob.Marker{}
+	for marker.NotDone() {
+		output, err := s.bucket.ListBlobsFlatSegment(opt.Context, marker, azblob.ListBlobsSegmentOptions{
+			Prefix:  rp,
+			Details: azblob.BlobListingDetails{Snapshots: true},
+		})
+		if err != nil {
+			err = handleAzblobError(err)
+			return types.NewError("ListVersions", s, path, pairs, err)
+		}
+		marker = output.NextMarker
+
+		done := false
+		for _, v := range output.Segment.BlobItems {
+			// Blobs are sorted by name, so snapshots of rp always come before other blobs under prefix rp.
+			if v.Name != rp {
+				done = true
+				break
+			}
+
+			o := &types.Object{
+				ID:         v.Name,
+				Name:       path,
+				Type:       types.ObjectTypeFile,
+				Size:       *v.Properties.ContentLength,
+				UpdatedAt:  v.Properties.LastModified,
+				ObjectMeta: metadata.NewObjectMeta(),
+			}
+			o.SetVersionID(v.Snapshot)
+			o.SetETag(string(v.Properties.Etag))
+
+			objects = append(objects, o)
+		}
+		if done {
+			break
+		}
+	}
+
+	// Snapshots are returned from the oldest to the newest, and the current blob is the last one.
+	for i := len(objects) - 1; i >= 0; i-- {
+		opt.FileFunc(objects[i])
+	}
+	return nil
+}
+
 // Delete implements Storager.Delete
+//
+// Without version_id, the blob will be deleted together with all its snapshots, because azblob refuses to delete a
+// blob which has snapshots. With version_id, only the specified snapshot will be deleted.
 func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
+	opt, err := parseStoragePairDelete(pairs...)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
 
-	opt, err := parseStoragePairStat(pairs...)
+	rp := s.getAbsPath(path)
+
+	// Key ends with "/" is a dir, azblob will delete it even if there are keys under it.
+	if strings.HasSuffix(rp, "/") {
+		err = prefix.CheckDirEmpty(opt.Context, rp, s.listKeys)
+		if err != nil {
+			return types.NewError("Delete", s, path, pairs, err)
+		}
+	}
+
+	snapshotsOption := azblob.DeleteSnapshotsOptionInclude
+	if opt.VersionID != "" {
+		snapshotsOption = azblob.DeleteSnapshotsOptionNone
+	}
+
+	_, err = s.bucket.NewBlockBlobURL(rp).WithSnapshot(opt.VersionID).Delete(opt.Context,
+		snapshotsOption, parseAccessConditions(opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince))
+	if err != nil {
+		err = handleAzblobError(err)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	return nil
+}
+
+// Restore implements Storager.Restore
+//
+// azblob restores Archive blobs by rehydrating them into Hot tier permanently, so days and tier are ignored.
+func (s *Storage) Restore(path string, days int, tier storageclass.RestoreTier, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairRestore(pairs...)
+	if err != nil {
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+	url := s.bucket.NewBlockBlobURL(rp)
+
+	// Only Archive blobs need to be rehydrated, SetTier on others will change their tier.
+	output, err := url.GetProperties(opt.Context, azblob.BlobAccessConditions{})
+	if err != nil {
+		err = handleAzblobError(err)
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+	if azblob.AccessTierType(output.AccessTier()) != azblob.AccessTierArchive {
+		return nil
+	}
+	// Blob is being rehydrated already.
+	if output.ArchiveStatus() != "" {
+		return nil
+	}
+
+	_, err = url.SetTier(opt.Context, azblob.AccessTierHot, azblob.LeaseAccessConditions{})
+	var e azblob.StorageError
+	if errors.As(err, &e) && e.ServiceCode() == azblob.ServiceCodeBlobBeingRehydrated {
+		return nil
+	}
+	if err != nil {
+		err = handleAzblobError(err)
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+	return nil
+}
+
+// Transit implements Storager.Transit
+func (s *Storage) Transit(path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairTransit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Transit", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
-	_, err = s.bucket.NewBlockBlobURL(rp).Delete(opt.Context,
-		azblob.DeleteSnapshotsOptionNone, azblob.BlobAccessConditions{})
+	tier, err := parseStorageClass(storageClass)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	_, err = s.bucket.NewBlockBlobURL(rp).SetTier(opt.Context, tier, azblob.LeaseAccessConditions{})
+	if err != nil {
+		err = handleAzblobError(err)
+		return types.NewError("Transit", s, path, pairs, err)
 	}
 	return nil
 }
+
+// InitAppend implements Storager.InitAppend
+func (s *Storage) InitAppend(path string, pairs ...*types.Pair) (offset int64, err error) {
+	opt, err := parseStoragePairInitAppend(pairs...)
+	if err != nil {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+	url := s.bucket.NewAppendBlobURL(rp)
+
+	output, err := url.GetProperties(opt.Context, azblob.BlobAccessConditions{})
+	if err == nil {
+		if output.BlobType() != azblob.BlobAppendBlob {
+			err = fmt.Errorf("%w: blob type is %s", types.ErrObjectAlreadyExist, output.BlobType())
+			return 0, types.NewError("InitAppend", s, path, pairs, err)
+		}
+		return output.ContentLength(), nil
+	}
+	if err = handleAzblobError(err); !errors.Is(err, types.ErrObjectNotExist) {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+
+	// Use if-none-match "*" so that we will not overwrite a blob created concurrently.
+	_, err = url.Create(opt.Context, azblob.BlobHTTPHeaders{}, azblob.Metadata{}, azblob.BlobAccessConditions{
+		ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfNoneMatch: azblob.ETagAny},
+	})
+	if err != nil {
+		err = handleAzblobError(err)
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+	return 0, nil
+}
+
+// WriteAppend implements Storager.WriteAppend
+//
+// Data will be split into blocks of azblob.AppendBlobMaxAppendBlockBytes.
+func (s *Storage) WriteAppend(path string, offset int64, r io.Reader, pairs ...*types.Pair) (next int64, err error) {
+	opt, err := parseStoragePairWriteAppend(pairs...)
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+	url := s.bucket.NewAppendBlobURL(rp)
+
+	err = iowrap.ReadBlocks(r, azblob.AppendBlobMaxAppendBlockBytes, func(block []byte) error {
+		// IfAppendPositionEqual 0 means no condition, azblob SDK uses -1 for position 0.
+		position := offset
+		if position == 0 {
+			position = -1
+		}
+		_, err := url.AppendBlock(opt.Context, bytes.NewReader(block), azblob.AppendBlobAccessConditions{
+			AppendPositionAccessConditions: azblob.AppendPositionAccessConditions{IfAppendPositionEqual: position},
+		}, nil)
+		if err != nil {
+			return handleAzblobError(err)
+		}
+		offset += int64(len(block))
+		return nil
+	})
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, err)
+	}
+	return offset, nil
+}
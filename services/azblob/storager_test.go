@@ -0,0 +1,173 @@
+package azblob
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/types"
+	ps "github.com/Xuanwo/storage/types/pairs"
+)
+
+// newTestStorage will create a Storage which sends requests to endpoint.
+func newTestStorage(t *testing.T, endpoint string) *Storage {
+	u, err := url.Parse(endpoint + "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := azblob.NewPipeline(azblob.NewAnonymousCredential(), azblob.PipelineOptions{})
+	return newStorage(azblob.NewContainerURL(*u, p), "test")
+}
+
+func TestStorage_WriteAppend(t *testing.T) {
+	var content []byte
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/test/object", r.URL.Path)
+		assert.Equal(t, "appendblock", r.URL.Query().Get("comp"))
+		requests++
+
+		position, err := strconv.Atoi(r.Header.Get("x-ms-blob-condition-appendpos"))
+		assert.NoError(t, err)
+		if position != len(content) {
+			w.Header().Set("x-ms-error-code", string(azblob.ServiceCodeAppendPositionConditionNotMet))
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = w.Write([]byte("<Error><Code>AppendPositionConditionNotMet</Code><Message>mismatch</Message></Error>"))
+			return
+		}
+
+		data, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		content = append(content, data...)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	data := bytes.Repeat([]byte("a"), azblob.AppendBlobMaxAppendBlockBytes+1)
+	next, err := s.WriteAppend("object", 0, bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), next)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, data, content)
+
+	_, err = s.WriteAppend("object", 0, strings.NewReader("content"))
+	assert.True(t, errors.Is(err, types.ErrPreconditionFailed))
+	assert.Equal(t, len(data), len(content))
+}
+
+func TestStorage_Restore(t *testing.T) {
+	cases := []struct {
+		name          string
+		tier          azblob.AccessTierType
+		archiveStatus string
+		restored      bool
+	}{
+		{"archive", azblob.AccessTierArchive, "", true},
+		{"rehydrating", azblob.AccessTierArchive, "rehydrate-pending-to-hot", false},
+		{"cool", azblob.AccessTierCool, "", false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/test/object", r.URL.Path)
+
+				switch r.Method {
+				case http.MethodHead:
+					w.Header().Set("x-ms-access-tier", string(tt.tier))
+					if tt.archiveStatus != "" {
+						w.Header().Set("x-ms-archive-status", tt.archiveStatus)
+					}
+				case http.MethodPut:
+					restored = true
+					assert.Equal(t, "tier", r.URL.Query().Get("comp"))
+					assert.Equal(t, string(azblob.AccessTierHot), r.Header.Get("x-ms-access-tier"))
+					w.WriteHeader(http.StatusAccepted)
+				}
+			}))
+			defer server.Close()
+
+			s := newTestStorage(t, server.URL)
+
+			err := s.Restore("object", 1, storageclass.RestoreTierStandard)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.restored, restored)
+		})
+	}
+}
+
+func TestStorage_DeleteSnapshotted(t *testing.T) {
+	cases := []struct {
+		name      string
+		versionID string
+		snapshots string
+	}{
+		{"blob", "", "include"},
+		{"snapshot", "2020-01-01T00:00:00.0000000Z", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				assert.Equal(t, "/test/object", r.URL.Path)
+				assert.Equal(t, tt.versionID, r.URL.Query().Get("snapshot"))
+
+				// The blob has snapshots, so azblob refuses to delete it alone.
+				if tt.versionID == "" && r.Header.Get("x-ms-delete-snapshots") != "include" {
+					w.Header().Set("x-ms-error-code", string(azblob.ServiceCodeSnapshotsPresent))
+					w.Header().Set("Content-Type", "application/xml")
+					w.WriteHeader(http.StatusConflict)
+					_, _ = w.Write([]byte("<Error><Code>SnapshotsPresent</Code><Message>snapshots</Message></Error>"))
+					return
+				}
+				assert.Equal(t, tt.snapshots, r.Header.Get("x-ms-delete-snapshots"))
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			s := newTestStorage(t, server.URL)
+
+			var err error
+			if tt.versionID == "" {
+				err = s.Delete("object")
+			} else {
+				err = s.Delete("object", ps.WithVersionID(tt.versionID))
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStorage_DeleteConflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ms-error-code", string(azblob.ServiceCodeLeaseIDMissing))
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte("<Error><Code>LeaseIdMissing</Code><Message>leased</Message></Error>"))
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	// Conflicts other than already exists should not be reported as ErrObjectAlreadyExist.
+	err := s.Delete("object")
+	assert.False(t, errors.Is(err, types.ErrObjectAlreadyExist))
+	assert.True(t, errors.Is(err, types.ErrUnhandledError))
+}
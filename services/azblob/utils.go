@@ -1,13 +1,76 @@
 package azblob
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/Azure/azure-pipeline-go/pipeline"
 	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Xuanwo/storage/pkg/checksum"
+	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/metadata"
 )
 
+// credentialPolicyFactory will refresh shared key credential while provider expired.
+//
+// azblob.SharedKeyCredential is embedded so that azblob.Credential could be implemented.
+type credentialPolicyFactory struct {
+	*azblob.SharedKeyCredential
+
+	provider credential.Provider
+	lock     sync.Mutex
+}
+
+// New implements pipeline.Factory
+func (f *credentialPolicyFactory) New(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.Policy {
+	return pipeline.PolicyFunc(func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+		cred, err := f.current()
+		if err != nil {
+			return nil, err
+		}
+		return cred.New(next, po).Do(ctx, request)
+	})
+}
+
+func (f *credentialPolicyFactory) current() (*azblob.SharedKeyCredential, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.provider.IsExpired() {
+		cred, err := newSharedKeyCredential(f.provider)
+		if err != nil {
+			return nil, err
+		}
+		f.SharedKeyCredential = cred
+	}
+	return f.SharedKeyCredential, nil
+}
+
+// newSharedKeyCredential will create shared key credential via value retrieved from provider.
+func newSharedKeyCredential(p credential.Provider) (*azblob.SharedKeyCredential, error) {
+	v, err := p.Retrieve()
+	if err != nil {
+		return nil, err
+	}
+	if v.Protocol == credential.ProtocolFile {
+		v, err = loadCredentialFile(v.File())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if v.Protocol != credential.ProtocolHmac {
+		return nil, credential.ErrUnsupportedProtocol
+	}
+	return azblob.NewSharedKeyCredential(v.Args[0], v.Args[1])
+}
+
 func (s *Storage) getAbsPath(path string) string {
 	return strings.TrimPrefix(s.workDir+"/"+path, "/")
 }
@@ -16,6 +79,26 @@ func (s *Storage) getRelPath(path string) string {
 	return strings.TrimPrefix(path, s.workDir+"/")
 }
 
+// listKeys implements prefix.ListFunc
+func (s *Storage) listKeys(ctx context.Context, prefix, marker string, limit int) (keys []string, next string, err error) {
+	output, err := s.bucket.ListBlobsFlatSegment(ctx, azblob.Marker{Val: &marker}, azblob.ListBlobsSegmentOptions{
+		Prefix:     prefix,
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		return nil, "", handleAzblobError(err)
+	}
+
+	keys = make([]string, 0, len(output.Segment.BlobItems))
+	for _, v := range output.Segment.BlobItems {
+		keys = append(keys, v.Name)
+	}
+	if output.NextMarker.NotDone() {
+		next = *output.NextMarker.Val
+	}
+	return keys, next, nil
+}
+
 // parseStorageClass will parse storageclass.Type into service independent storage class type.
 func parseStorageClass(in storageclass.Type) (azblob.AccessTierType, error) {
 	switch in {
@@ -43,3 +126,165 @@ func formatStorageClass(in azblob.AccessTierType) (storageclass.Type, error) {
 		return "", types.ErrStorageClassNotSupported
 	}
 }
+
+// formatRestoreStatus will format archive status of an Archive blob into storageclass.RestoreStatus.
+//
+// Blob will be moved out of Archive tier after rehydrated, so it could only be archived or restoring.
+func formatRestoreStatus(in azblob.ArchiveStatusType) storageclass.RestoreStatus {
+	switch in {
+	case azblob.ArchiveStatusRehydratePendingToHot, azblob.ArchiveStatusRehydratePendingToCool:
+		return storageclass.RestoreStatusRestoring
+	default:
+		return storageclass.RestoreStatusArchived
+	}
+}
+
+// loadCredentialFile will load hmac credential from file which contains azure storage connection string,
+// connection string doesn't have profiles.
+//
+// ref: https://docs.microsoft.com/en-us/azure/storage/common/storage-configure-connection-string
+func loadCredentialFile(path, profile string) (credential.Value, error) {
+	if profile != "" {
+		return credential.Value{}, credential.ErrInvalidConfig
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return credential.Value{}, err
+	}
+
+	var name, key string
+	for _, kv := range strings.Split(strings.TrimSpace(string(content)), ";") {
+		// Account key is base64 encoded which could contain "=".
+		x := strings.SplitN(strings.TrimSpace(kv), "=", 2)
+		if len(x) != 2 {
+			continue
+		}
+		switch x[0] {
+		case "AccountName":
+			name = x[1]
+		case "AccountKey":
+			key = x[1]
+		}
+	}
+	if name == "" || key == "" {
+		return credential.Value{}, credential.ErrCredentialNotFound
+	}
+	return credential.Value{
+		Protocol: credential.ProtocolHmac,
+		Args:     []string{name, key},
+	}, nil
+}
+
+// handleAzblobError will map azblob service codes and http status codes onto sentinel errors.
+//
+// ref: https://docs.microsoft.com/en-us/rest/api/storageservices/blob-service-error-codes
+func handleAzblobError(err error) error {
+	if err == nil {
+		panic("error must not be nil")
+	}
+	// Context errors will be returned as is, so caller could check them via errors.Is.
+	if types.IsContextError(err) {
+		return err
+	}
+
+	var e azblob.StorageError
+	if !errors.As(err, &e) {
+		if types.IsTimeout(err) {
+			return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+	}
+
+	switch e.ServiceCode() {
+	case azblob.ServiceCodeBlobNotFound, azblob.ServiceCodeContainerNotFound, azblob.ServiceCodeResourceNotFound:
+		return fmt.Errorf("%w: %v", types.ErrObjectNotExist, err)
+	case azblob.ServiceCodeBlobAlreadyExists, azblob.ServiceCodeContainerAlreadyExists:
+		return fmt.Errorf("%w: %v", types.ErrObjectAlreadyExist, err)
+	case azblob.ServiceCodeAuthenticationFailed:
+		return fmt.Errorf("%w: %v", types.ErrConfigIncorrect, err)
+	case azblob.ServiceCodeInsufficientAccountPermissions, azblob.ServiceCodeAccountIsDisabled:
+		return fmt.Errorf("%w: %v", types.ErrPermissionDenied, err)
+	case azblob.ServiceCodeConditionNotMet, azblob.ServiceCodeTargetConditionNotMet, azblob.ServiceCodeAppendPositionConditionNotMet:
+		return fmt.Errorf("%w: %v", types.ErrPreconditionFailed, err)
+	case azblob.ServiceCodeBlobArchived:
+		return fmt.Errorf("%w: %v", types.ErrObjectArchived, err)
+	case azblob.ServiceCodeServerBusy:
+		return fmt.Errorf("%w: %v", types.ErrRateLimited, err)
+	case azblob.ServiceCodeOperationTimedOut:
+		return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+	case azblob.ServiceCodeInternalError:
+		return fmt.Errorf("%w: %v", types.ErrServiceUnavailable, err)
+	case azblob.ServiceCodeUnsupportedHeader, azblob.ServiceCodeUnsupportedHTTPVerb,
+		azblob.ServiceCodeUnsupportedQueryParameter:
+		return fmt.Errorf("%w: %v", types.ErrNotSupported, err)
+	}
+
+	if resp := e.Response(); resp != nil {
+		if se := types.ErrFromStatusCode(resp.StatusCode); se != nil {
+			return fmt.Errorf("%w: %v", se, err)
+		}
+	}
+	return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+}
+
+// parseAccessConditions will convert preconditions into azblob's access conditions, zero values will be ignored.
+func parseAccessConditions(ifMatch, ifNoneMatch string, ifModifiedSince, ifUnmodifiedSince time.Time) azblob.BlobAccessConditions {
+	return azblob.BlobAccessConditions{
+		ModifiedAccessConditions: azblob.ModifiedAccessConditions{
+			IfModifiedSince:   ifModifiedSince,
+			IfUnmodifiedSince: ifUnmodifiedSince,
+			IfMatch:           azblob.ETag(ifMatch),
+			IfNoneMatch:       azblob.ETag(ifNoneMatch),
+		},
+	}
+}
+
+// listObjects will list a page of blobs under rp, marker returned by azblob is used as the marker.
+func (s *Storage) listObjects(ctx context.Context, rp, marker string) (objects []*types.Object, next string, err error) {
+	m := azblob.Marker{}
+	if marker != "" {
+		m.Val = &marker
+	}
+
+	output, err := s.bucket.ListBlobsFlatSegment(ctx, m, azblob.ListBlobsSegmentOptions{
+		Prefix:  rp,
+		Details: azblob.BlobListingDetails{Metadata: true},
+	})
+	if err != nil {
+		return nil, "", handleAzblobError(err)
+	}
+
+	objects = make([]*types.Object, 0, len(output.Segment.BlobItems))
+	for _, v := range output.Segment.BlobItems {
+		o := &types.Object{
+			ID:         v.Name,
+			Name:       s.getRelPath(v.Name),
+			Type:       types.ObjectTypeFile,
+			Size:       *v.Properties.ContentLength,
+			UpdatedAt:  v.Properties.LastModified,
+			ObjectMeta: metadata.NewObjectMeta(),
+		}
+		o.SetContentType(*v.Properties.ContentType)
+		o.SetETag(string(v.Properties.Etag))
+		if len(v.Properties.ContentMD5) > 0 {
+			o.SetContentMD5(checksum.FormatBytes(v.Properties.ContentMD5))
+		}
+
+		storageClass, err := formatStorageClass(v.Properties.AccessTier)
+		if err != nil {
+			return nil, "", err
+		}
+		o.SetStorageClass(storageClass)
+		if len(v.Metadata) > 0 {
+			o.SetUserMetadata(v.Metadata)
+		}
+
+		objects = append(objects, o)
+	}
+
+	// Empty NextMarker means this is the last page.
+	if output.NextMarker.Val != nil {
+		next = *output.NextMarker.Val
+	}
+	return objects, next, nil
+}
@@ -0,0 +1,93 @@
+package azblob
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/pkg/storageclass"
+)
+
+func TestLoadCredentialFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "azblob")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "connection")
+	err = ioutil.WriteFile(path, []byte("DefaultEndpointsProtocol=https;AccountName=name;AccountKey=a2V5==;EndpointSuffix=core.windows.net\n"), 0600)
+	assert.NoError(t, err)
+
+	v, err := loadCredentialFile(path, "")
+	assert.NoError(t, err)
+	assert.Equal(t, credential.Value{Protocol: credential.ProtocolHmac, Args: []string{"name", "a2V5=="}}, v)
+
+	_, err = loadCredentialFile(path, "profile")
+	assert.True(t, errors.Is(err, credential.ErrInvalidConfig))
+}
+
+func TestParseAccessConditions(t *testing.T) {
+	now := time.Now()
+
+	ac := parseAccessConditions("", "", time.Time{}, time.Time{})
+	assert.Equal(t, azblob.BlobAccessConditions{}, ac)
+
+	ac = parseAccessConditions("etag", "*", now, now)
+	assert.Equal(t, azblob.ETag("etag"), ac.IfMatch)
+	assert.Equal(t, azblob.ETagAny, ac.IfNoneMatch)
+	assert.Equal(t, now, ac.IfModifiedSince)
+	assert.Equal(t, now, ac.IfUnmodifiedSince)
+}
+
+func TestFormatRestoreStatus(t *testing.T) {
+	assert.Equal(t, storageclass.RestoreStatusArchived, formatRestoreStatus(azblob.ArchiveStatusNone))
+	assert.Equal(t, storageclass.RestoreStatusRestoring, formatRestoreStatus(azblob.ArchiveStatusRehydratePendingToHot))
+}
+
+func TestCredentialPolicyFactory(t *testing.T) {
+	// Base64 encoded "key1" and "key2".
+	keys := []string{"a2V5MQ==", "a2V5Mg=="}
+	retrieved := 0
+	provider := credential.NewRefreshable(func() (credential.Value, error) {
+		v := credential.Value{
+			Protocol: credential.ProtocolHmac,
+			Args:     []string{"account", keys[retrieved%2]},
+			// Expire immediately so that every request will refresh it.
+			Expiration: time.Now(),
+		}
+		retrieved++
+		return v, nil
+	}, 0)
+
+	auths := make([]string, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	cred, err := newSharedKeyCredential(provider)
+	assert.NoError(t, err)
+	u, _ := url.Parse(srv.URL)
+	service := azblob.NewServiceURL(*u, azblob.NewPipeline(&credentialPolicyFactory{
+		SharedKeyCredential: cred,
+		provider:            provider,
+	}, azblob.PipelineOptions{}))
+
+	for i := 0; i < 2; i++ {
+		// Response is not valid, we only care about the signature.
+		_, _ = service.GetProperties(context.Background())
+	}
+	assert.Equal(t, 3, retrieved)
+	assert.Len(t, auths, 2)
+	assert.NotEqual(t, auths[0], auths[1])
+}
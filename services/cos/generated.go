@@ -4,12 +4,14 @@ package cos
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 
 	"github.com/Xuanwo/storage"
 	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/pkg/iterator"
 	"github.com/Xuanwo/storage/pkg/segment"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
@@ -19,9 +21,11 @@ import (
 
 var _ credential.Provider
 var _ endpoint.Provider
+var _ iterator.ObjectIterator
 var _ segment.Segment
 var _ storage.Storager
 var _ storageclass.Type
+var _ time.Duration
 
 // Type is the type for cos
 const Type = "cos"
@@ -31,8 +35,10 @@ type pairServiceCreate struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasLocation bool
-	Location    string
+	HasLocation   bool
+	Location      string
+	HasVersioning bool
+	Versioning    bool
 }
 
 func parseServicePairCreate(opts ...*types.Pair) (*pairServiceCreate, error) {
@@ -63,6 +69,11 @@ func parseServicePairCreate(opts ...*types.Pair) (*pairServiceCreate, error) {
 		result.HasLocation = true
 		result.Location = v.(string)
 	}
+	v, ok = values[ps.Versioning]
+	if ok {
+		result.HasVersioning = true
+		result.Versioning = v.(bool)
+	}
 	return result, nil
 }
 
@@ -192,7 +203,7 @@ type pairServiceNew struct {
 
 	// Meta-defined pairs
 	HasCredential bool
-	Credential    *credential.Provider
+	Credential    credential.Provider
 }
 
 func parseServicePairNew(opts ...*types.Pair) (*pairServiceNew, error) {
@@ -221,7 +232,7 @@ func parseServicePairNew(opts ...*types.Pair) (*pairServiceNew, error) {
 	}
 	if ok {
 		result.HasCredential = true
-		result.Credential = v.(*credential.Provider)
+		result.Credential = v.(credential.Provider)
 	}
 	return result, nil
 }
@@ -231,6 +242,16 @@ type pairStorageDelete struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasVersionID         bool
+	VersionID            string
 }
 
 func parseStoragePairDelete(opts ...*types.Pair) (*pairStorageDelete, error) {
@@ -252,6 +273,151 @@ func parseStoragePairDelete(opts ...*types.Pair) (*pairStorageDelete, error) {
 		result.Context = context.Background()
 	}
 
+	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.VersionID]
+	if ok {
+		result.HasVersionID = true
+		result.VersionID = v.(string)
+	}
+	return result, nil
+}
+
+type pairStorageDeleteLifecycle struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairDeleteLifecycle(opts ...*types.Pair) (*pairStorageDeleteLifecycle, error) {
+	result := &pairStorageDeleteLifecycle{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageDeleteTags struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairDeleteTags(opts ...*types.Pair) (*pairStorageDeleteTags, error) {
+	result := &pairStorageDeleteTags{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageGetLifecycle struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairGetLifecycle(opts ...*types.Pair) (*pairStorageGetLifecycle, error) {
+	result := &pairStorageGetLifecycle{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageGetTags struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairGetTags(opts ...*types.Pair) (*pairStorageGetTags, error) {
+	result := &pairStorageGetTags{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
 	// Parse meta-defined pairs
 	return result, nil
 }
@@ -261,8 +427,10 @@ type pairStorageInit struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasWorkDir bool
-	WorkDir    string
+	HasStorageClass bool
+	StorageClass    storageclass.Type
+	HasWorkDir      bool
+	WorkDir         string
 }
 
 func parseStoragePairInit(opts ...*types.Pair) (*pairStorageInit, error) {
@@ -285,6 +453,11 @@ func parseStoragePairInit(opts ...*types.Pair) (*pairStorageInit, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.StorageClass]
+	if ok {
+		result.HasStorageClass = true
+		result.StorageClass = v.(storageclass.Type)
+	}
 	v, ok = values[ps.WorkDir]
 	if ok {
 		result.HasWorkDir = true
@@ -293,6 +466,36 @@ func parseStoragePairInit(opts ...*types.Pair) (*pairStorageInit, error) {
 	return result, nil
 }
 
+type pairStorageInitAppend struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairInitAppend(opts ...*types.Pair) (*pairStorageInitAppend, error) {
+	result := &pairStorageInitAppend{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
 type pairStorageList struct {
 	// Pre-defined pairs
 	Context context.Context
@@ -333,6 +536,46 @@ func parseStoragePairList(opts ...*types.Pair) (*pairStorageList, error) {
 	return result, nil
 }
 
+type pairStorageListVersions struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+	HasFileFunc bool
+	FileFunc    types.ObjectFunc
+}
+
+func parseStoragePairListVersions(opts ...*types.Pair) (*pairStorageListVersions, error) {
+	result := &pairStorageListVersions{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	v, ok = values[ps.FileFunc]
+	if !ok {
+		return nil, types.NewErrPairRequired(ps.FileFunc)
+	}
+	if ok {
+		result.HasFileFunc = true
+		result.FileFunc = v.(types.ObjectFunc)
+	}
+	return result, nil
+}
+
 type pairStorageMetadata struct {
 	// Pre-defined pairs
 	Context context.Context
@@ -368,6 +611,18 @@ type pairStorageRead struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasVerifyChecksum    bool
+	VerifyChecksum       bool
+	HasVersionID         bool
+	VersionID            string
 }
 
 func parseStoragePairRead(opts ...*types.Pair) (*pairStorageRead, error) {
@@ -389,6 +644,126 @@ func parseStoragePairRead(opts ...*types.Pair) (*pairStorageRead, error) {
 		result.Context = context.Background()
 	}
 
+	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.VerifyChecksum]
+	if ok {
+		result.HasVerifyChecksum = true
+		result.VerifyChecksum = v.(bool)
+	}
+	v, ok = values[ps.VersionID]
+	if ok {
+		result.HasVersionID = true
+		result.VersionID = v.(string)
+	}
+	return result, nil
+}
+
+type pairStorageRestore struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairRestore(opts ...*types.Pair) (*pairStorageRestore, error) {
+	result := &pairStorageRestore{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageSetLifecycle struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairSetLifecycle(opts ...*types.Pair) (*pairStorageSetLifecycle, error) {
+	result := &pairStorageSetLifecycle{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageSetTags struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairSetTags(opts ...*types.Pair) (*pairStorageSetTags, error) {
+	result := &pairStorageSetTags{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
 	// Parse meta-defined pairs
 	return result, nil
 }
@@ -398,6 +773,16 @@ type pairStorageStat struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasVersionID         bool
+	VersionID            string
 }
 
 func parseStoragePairStat(opts ...*types.Pair) (*pairStorageStat, error) {
@@ -419,6 +804,61 @@ func parseStoragePairStat(opts ...*types.Pair) (*pairStorageStat, error) {
 		result.Context = context.Background()
 	}
 
+	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.VersionID]
+	if ok {
+		result.HasVersionID = true
+		result.VersionID = v.(string)
+	}
+	return result, nil
+}
+
+type pairStorageTransit struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairTransit(opts ...*types.Pair) (*pairStorageTransit, error) {
+	result := &pairStorageTransit{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
 	// Parse meta-defined pairs
 	return result, nil
 }
@@ -428,12 +868,34 @@ type pairStorageWrite struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasChecksum     bool
-	Checksum        string
-	HasSize         bool
-	Size            int64
-	HasStorageClass bool
-	StorageClass    storageclass.Type
+	HasCacheControl       bool
+	CacheControl          string
+	HasChecksum           bool
+	Checksum              string
+	HasContentDisposition bool
+	ContentDisposition    string
+	HasContentEncoding    bool
+	ContentEncoding       string
+	HasContentType        bool
+	ContentType           string
+	HasExpires            bool
+	Expires               time.Time
+	HasIfMatch            bool
+	IfMatch               string
+	HasIfModifiedSince    bool
+	IfModifiedSince       time.Time
+	HasIfNoneMatch        bool
+	IfNoneMatch           string
+	HasIfUnmodifiedSince  bool
+	IfUnmodifiedSince     time.Time
+	HasSize               bool
+	Size                  int64
+	HasStorageClass       bool
+	StorageClass          storageclass.Type
+	HasTags               bool
+	Tags                  map[string]string
+	HasUserMetadata       bool
+	UserMetadata          map[string]string
 }
 
 func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
@@ -456,11 +918,56 @@ func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.CacheControl]
+	if ok {
+		result.HasCacheControl = true
+		result.CacheControl = v.(string)
+	}
 	v, ok = values[ps.Checksum]
 	if ok {
 		result.HasChecksum = true
 		result.Checksum = v.(string)
 	}
+	v, ok = values[ps.ContentDisposition]
+	if ok {
+		result.HasContentDisposition = true
+		result.ContentDisposition = v.(string)
+	}
+	v, ok = values[ps.ContentEncoding]
+	if ok {
+		result.HasContentEncoding = true
+		result.ContentEncoding = v.(string)
+	}
+	v, ok = values[ps.ContentType]
+	if ok {
+		result.HasContentType = true
+		result.ContentType = v.(string)
+	}
+	v, ok = values[ps.Expires]
+	if ok {
+		result.HasExpires = true
+		result.Expires = v.(time.Time)
+	}
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
 	v, ok = values[ps.Size]
 	if !ok {
 		return nil, types.NewErrPairRequired(ps.Size)
@@ -474,6 +981,46 @@ func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
 		result.HasStorageClass = true
 		result.StorageClass = v.(storageclass.Type)
 	}
+	v, ok = values[ps.Tags]
+	if ok {
+		result.HasTags = true
+		result.Tags = v.(map[string]string)
+	}
+	v, ok = values[ps.UserMetadata]
+	if ok {
+		result.HasUserMetadata = true
+		result.UserMetadata = v.(map[string]string)
+	}
+	return result, nil
+}
+
+type pairStorageWriteAppend struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairWriteAppend(opts ...*types.Pair) (*pairStorageWriteAppend, error) {
+	result := &pairStorageWriteAppend{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
 	return result, nil
 }
 
@@ -522,6 +1069,42 @@ func (s *Storage) DeleteWithContext(ctx context.Context, path string, pairs ...*
 	return s.Delete(path, pairs...)
 }
 
+// DeleteLifecycleWithContext adds context support for DeleteLifecycle.
+func (s *Storage) DeleteLifecycleWithContext(ctx context.Context, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.DeleteLifecycle")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.DeleteLifecycle(pairs...)
+}
+
+// DeleteTagsWithContext adds context support for DeleteTags.
+func (s *Storage) DeleteTagsWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.DeleteTags")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.DeleteTags(path, pairs...)
+}
+
+// GetLifecycleWithContext adds context support for GetLifecycle.
+func (s *Storage) GetLifecycleWithContext(ctx context.Context, pairs ...*types.Pair) (rules []types.LifecycleRule, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.GetLifecycle")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.GetLifecycle(pairs...)
+}
+
+// GetTagsWithContext adds context support for GetTags.
+func (s *Storage) GetTagsWithContext(ctx context.Context, path string, pairs ...*types.Pair) (tags map[string]string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.GetTags")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.GetTags(path, pairs...)
+}
+
 // InitWithContext adds context support for Init.
 func (s *Storage) InitWithContext(ctx context.Context, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.Init")
@@ -531,6 +1114,15 @@ func (s *Storage) InitWithContext(ctx context.Context, pairs ...*types.Pair) (er
 	return s.Init(pairs...)
 }
 
+// InitAppendWithContext adds context support for InitAppend.
+func (s *Storage) InitAppendWithContext(ctx context.Context, path string, pairs ...*types.Pair) (offset int64, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.InitAppend")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.InitAppend(path, pairs...)
+}
+
 // ListWithContext adds context support for List.
 func (s *Storage) ListWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.List")
@@ -540,6 +1132,15 @@ func (s *Storage) ListWithContext(ctx context.Context, path string, pairs ...*ty
 	return s.List(path, pairs...)
 }
 
+// ListVersionsWithContext adds context support for ListVersions.
+func (s *Storage) ListVersionsWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.ListVersions")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.ListVersions(path, pairs...)
+}
+
 // MetadataWithContext adds context support for Metadata.
 func (s *Storage) MetadataWithContext(ctx context.Context, pairs ...*types.Pair) (m metadata.StorageMeta, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.Metadata")
@@ -558,6 +1159,33 @@ func (s *Storage) ReadWithContext(ctx context.Context, path string, pairs ...*ty
 	return s.Read(path, pairs...)
 }
 
+// RestoreWithContext adds context support for Restore.
+func (s *Storage) RestoreWithContext(ctx context.Context, path string, days int, tier storageclass.RestoreTier, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.Restore")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.Restore(path, days, tier, pairs...)
+}
+
+// SetLifecycleWithContext adds context support for SetLifecycle.
+func (s *Storage) SetLifecycleWithContext(ctx context.Context, rules []types.LifecycleRule, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.SetLifecycle")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.SetLifecycle(rules, pairs...)
+}
+
+// SetTagsWithContext adds context support for SetTags.
+func (s *Storage) SetTagsWithContext(ctx context.Context, path string, tags map[string]string, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.SetTags")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.SetTags(path, tags, pairs...)
+}
+
 // StatWithContext adds context support for Stat.
 func (s *Storage) StatWithContext(ctx context.Context, path string, pairs ...*types.Pair) (o *types.Object, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.Stat")
@@ -567,6 +1195,15 @@ func (s *Storage) StatWithContext(ctx context.Context, path string, pairs ...*ty
 	return s.Stat(path, pairs...)
 }
 
+// TransitWithContext adds context support for Transit.
+func (s *Storage) TransitWithContext(ctx context.Context, path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.Transit")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.Transit(path, storageClass, pairs...)
+}
+
 // WriteWithContext adds context support for Write.
 func (s *Storage) WriteWithContext(ctx context.Context, path string, r io.Reader, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.Write")
@@ -575,3 +1212,12 @@ func (s *Storage) WriteWithContext(ctx context.Context, path string, r io.Reader
 	pairs = append(pairs, ps.WithContext(ctx))
 	return s.Write(path, r, pairs...)
 }
+
+// WriteAppendWithContext adds context support for WriteAppend.
+func (s *Storage) WriteAppendWithContext(ctx context.Context, path string, offset int64, r io.Reader, pairs ...*types.Pair) (next int64, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/cos.storage.WriteAppend")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.WriteAppend(path, offset, r, pairs...)
+}
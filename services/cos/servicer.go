@@ -20,24 +20,34 @@ type Service struct {
 
 // New will create a new Tencent oss service.
 func New(pairs ...*types.Pair) (s *Service, err error) {
-	const errorMessage = "%s New: %w"
-
 	s = &Service{}
 
 	opt, err := parseServicePairNew(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
-	credProtocol, cred := opt.Credential.Protocol(), opt.Credential.Value()
-	if credProtocol != credential.ProtocolHmac {
-		return nil, fmt.Errorf(errorMessage, s, credential.ErrUnsupportedProtocol)
+	cred, err := opt.Credential.Retrieve()
+	if err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	if cred.Protocol == credential.ProtocolFile {
+		cred, err = loadCredentialFile(cred.File())
+		if err != nil {
+			return nil, types.NewError("New", s, "", pairs, err)
+		}
 	}
+	if cred.Protocol != credential.ProtocolHmac && cred.Protocol != credential.ProtocolSTS {
+		return nil, types.NewError("New", s, "", pairs, credential.ErrUnsupportedProtocol)
+	}
+
+	t := &cos.AuthorizationTransport{}
+	setCredential(t, cred)
 
 	s.client = &http.Client{
-		Transport: &cos.AuthorizationTransport{
-			SecretID:  cred[0],
-			SecretKey: cred[1],
+		Transport: &credentialTransport{
+			provider:  opt.Credential,
+			transport: t,
 		},
 		Timeout: 100 * time.Second,
 	}
@@ -52,16 +62,15 @@ func (s *Service) String() string {
 
 // List implements Servicer.List
 func (s *Service) List(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List: %w"
-
 	opt, err := parseServicePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("List", s, "", pairs, err)
 	}
 
 	output, _, err := s.service.Service.Get(opt.Context)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		err = handleCosError(err)
+		return types.NewError("List", s, "", pairs, err)
 	}
 	for _, v := range output.Buckets {
 		store := newStorage(v.Name, v.Region, s.client)
@@ -72,11 +81,9 @@ func (s *Service) List(pairs ...*types.Pair) (err error) {
 
 // Get implements Servicer.Get
 func (s *Service) Get(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Get [%s]: %w"
-
 	opt, err := parseServicePairGet(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Get", s, name, pairs, err)
 	}
 
 	store := newStorage(name, opt.Location, s.client)
@@ -85,34 +92,44 @@ func (s *Service) Get(name string, pairs ...*types.Pair) (storage.Storager, erro
 
 // Create implements Servicer.Create
 func (s *Service) Create(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Create [%s]: %w"
-
 	opt, err := parseServicePairCreate(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 
 	store := newStorage(name, opt.Location, s.client)
 	_, err = store.bucket.Put(opt.Context, nil)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		err = handleCosError(err)
+		return nil, types.NewError("Create", s, name, pairs, err)
+	}
+
+	if opt.HasVersioning {
+		status := versioningSuspended
+		if opt.Versioning {
+			status = versioningEnabled
+		}
+		_, err = store.bucket.PutVersioning(opt.Context, &cos.BucketPutVersionOptions{Status: status})
+		if err != nil {
+			err = handleCosError(err)
+			return nil, types.NewError("Create", s, name, pairs, err)
+		}
 	}
 	return store, nil
 }
 
 // Delete implements Servicer.Delete
 func (s *Service) Delete(name string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
-
 	opt, err := parseServicePairDelete(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 
 	store := newStorage(name, opt.Location, s.client)
 	_, err = store.bucket.Delete(opt.Context)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		err = handleCosError(err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 	return
 }
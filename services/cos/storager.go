@@ -1,28 +1,45 @@
 package cos
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/Xuanwo/storage/pkg/checksum"
+	"github.com/Xuanwo/storage/pkg/iowrap"
+	"github.com/Xuanwo/storage/pkg/prefix"
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/pkg/tagging"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/metadata"
+	ps "github.com/Xuanwo/storage/types/pairs"
 
 	"github.com/tencentyun/cos-go-sdk-v5"
 )
 
+// copyObjectSizeLimit is the max size of object which could be copied in one PUT Object - Copy request.
+//
+// ref: https://cloud.tencent.com/document/product/436/10881
+const copyObjectSizeLimit = 5 * 1024 * 1024 * 1024
+
 // Storage is the cos object storage service.
 //
 //go:generate ../../internal/bin/service
 type Storage struct {
 	bucket *cos.BucketService
 	object *cos.ObjectService
-
-	name     string
-	location string
-	workDir  string
+	// client and bucketURL are used to send requests which SDK doesn't support.
+	client    *http.Client
+	bucketURL *url.URL
+
+	name         string
+	location     string
+	workDir      string
+	storageClass storageclass.Type
 }
 
 // newStorage will create a new client.
@@ -33,6 +50,8 @@ func newStorage(bucketName, region string, client *http.Client) *Storage {
 	c := cos.NewClient(&cos.BaseURL{BucketURL: url}, client)
 	s.bucket = c.Bucket
 	s.object = c.Object
+	s.client = client
+	s.bucketURL = url
 	s.name = bucketName
 	s.location = region
 	return s
@@ -48,17 +67,22 @@ func (s *Storage) String() string {
 
 // Init implements Storager.Init
 func (s *Storage) Init(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Init: %w"
-
 	opt, err := parseStoragePairInit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("Init", s, "", pairs, err)
 	}
 
 	if opt.HasWorkDir {
 		// TODO: we should validate workDir
 		s.workDir = strings.TrimLeft(opt.WorkDir, "/")
 	}
+	if opt.HasStorageClass {
+		// Storage class will be used as default for Write, validate it here to fail fast.
+		if _, err = parseStorageClass(opt.StorageClass); err != nil {
+			return types.NewError("Init", s, "", pairs, err)
+		}
+		s.storageClass = opt.StorageClass
+	}
 
 	return nil
 }
@@ -68,16 +92,15 @@ func (s *Storage) Metadata(pairs ...*types.Pair) (m metadata.StorageMeta, err er
 	m = metadata.NewStorageMeta()
 	m.Name = s.name
 	m.WorkDir = s.workDir
+	m.SetFlatList(true)
 	return m, nil
 }
 
 // List implements Storager.List
 func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List [%s]: %w"
-
 	opt, err := parseStoragePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("List", s, path, pairs, err)
 	}
 
 	marker := ""
@@ -94,14 +117,15 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 
 		resp, _, err := s.bucket.Get(opt.Context, req)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			err = handleCosError(err)
+			return types.NewError("List", s, path, pairs, err)
 		}
 
 		for _, v := range resp.Contents {
 			// COS use ISO8601 format: 2019-05-27T11:26:14.000Z
 			t, err := time.Parse("2006-01-02T15:04:05.999Z", v.LastModified)
 			if err != nil {
-				return fmt.Errorf(errorMessage, s, path, err)
+				return types.NewError("List", s, path, pairs, err)
 			}
 
 			o := &types.Object{
@@ -113,10 +137,11 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 				ObjectMeta: metadata.NewObjectMeta(),
 			}
 			o.SetETag(v.ETag)
+			checksum.FromETag(o.ObjectMeta, v.ETag)
 
 			storageClass, err := formatStorageClass(v.StorageClass)
 			if err != nil {
-				return fmt.Errorf(errorMessage, s, path, err)
+				return types.NewError("List", s, path, pairs, err)
 			}
 			o.SetStorageClass(storageClass)
 
@@ -132,33 +157,174 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 	return
 }
 
+// ListVersions implements Storager.ListVersions
+//
+// cos SDK doesn't support list object versions, so versions are listed via raw requests.
+func (s *Storage) ListVersions(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairListVersions(pairs...)
+	if err != nil {
+		return types.NewError("ListVersions", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	keyMarker, versionIDMarker := "", ""
+	for {
+		output, err := s.listVersions(opt.Context, rp, keyMarker, versionIDMarker, 1000)
+		if err != nil {
+			err = handleCosError(err)
+			return types.NewError("ListVersions", s, path, pairs, err)
+		}
+
+		for _, v := range output.Versions {
+			// Versions are sorted by key, so versions of rp always come before other keys under prefix rp.
+			if v.Key != rp {
+				return nil
+			}
+
+			// COS use ISO8601 format: 2019-05-27T11:26:14.000Z
+			t, err := time.Parse("2006-01-02T15:04:05.999Z", v.LastModified)
+			if err != nil {
+				return types.NewError("ListVersions", s, path, pairs, err)
+			}
+
+			o := &types.Object{
+				ID:         rp,
+				Name:       path,
+				Type:       types.ObjectTypeFile,
+				Size:       v.Size,
+				UpdatedAt:  t,
+				ObjectMeta: metadata.NewObjectMeta(),
+			}
+			o.SetVersionID(v.VersionID)
+			o.SetETag(v.ETag)
+
+			storageClass, err := formatStorageClass(v.StorageClass)
+			if err != nil {
+				return types.NewError("ListVersions", s, path, pairs, err)
+			}
+			o.SetStorageClass(storageClass)
+
+			opt.FileFunc(o)
+		}
+
+		if !output.IsTruncated {
+			return nil
+		}
+		keyMarker, versionIDMarker = output.NextKeyMarker, output.NextVersionIDMarker
+	}
+}
+
+// GetTags implements Storager.GetTags
+func (s *Storage) GetTags(path string, pairs ...*types.Pair) (tags map[string]string, err error) {
+	opt, err := parseStoragePairGetTags(pairs...)
+	if err != nil {
+		return nil, types.NewError("GetTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	// cos SDK doesn't support object tagging, so tags are got via raw requests.
+	tags, err = s.getTagging(opt.Context, rp)
+	if err != nil {
+		err = handleCosError(err)
+		return nil, types.NewError("GetTags", s, path, pairs, err)
+	}
+	return tags, nil
+}
+
+// SetTags implements Storager.SetTags
+func (s *Storage) SetTags(path string, tags map[string]string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairSetTags(pairs...)
+	if err != nil {
+		return types.NewError("SetTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	err = s.putTagging(opt.Context, rp, tags)
+	if err != nil {
+		err = handleCosError(err)
+		return types.NewError("SetTags", s, path, pairs, err)
+	}
+	return nil
+}
+
+// DeleteTags implements Storager.DeleteTags
+func (s *Storage) DeleteTags(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteTags(pairs...)
+	if err != nil {
+		return types.NewError("DeleteTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	err = s.deleteTagging(opt.Context, rp)
+	if err != nil {
+		err = handleCosError(err)
+		return types.NewError("DeleteTags", s, path, pairs, err)
+	}
+	return nil
+}
+
 // Read implements Storager.Read
 func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err error) {
-	const errorMessage = "%s Read [%s]: %w"
-
 	opt, err := parseStoragePairRead(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfUnmodifiedSince)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+
+	getOptions := &cos.ObjectGetOptions{}
+	if opt.HasIfModifiedSince {
+		getOptions.IfModifiedSince = opt.IfModifiedSince.UTC().Format(http.TimeFormat)
+	}
+
+	var versionID []string
+	if opt.HasVersionID {
+		versionID = append(versionID, opt.VersionID)
 	}
 
 	rp := s.getAbsPath(path)
 
-	resp, err := s.object.Get(opt.Context, rp, nil)
+	resp, err := s.object.Get(opt.Context, rp, getOptions, versionID...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleCosError(err)
+		return nil, types.NewError("Read", s, path, pairs, err)
 	}
 
 	r = resp.Body
+	if opt.HasVerifyChecksum && opt.VerifyChecksum {
+		m := metadata.NewObjectMeta()
+		if !isEncrypted(resp.Header) {
+			checksum.FromETag(m, resp.Header.Get("ETag"))
+		}
+
+		r, err = checksum.NewVerifyReadCloser(r, m)
+		if err != nil {
+			resp.Body.Close()
+			return nil, types.NewError("Read", s, path, pairs, err)
+		}
+	}
 	return
 }
 
 // Write implements Storager.Write
 func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Write [%s]: %w"
-
 	opt, err := parseStoragePairWrite(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	if !opt.HasStorageClass && s.storageClass != "" {
+		// Storage class set in Init will be used as default.
+		opt.HasStorageClass, opt.StorageClass = true, s.storageClass
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
@@ -175,37 +341,77 @@ func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err err
 	if opt.HasStorageClass {
 		storageClass, err := parseStorageClass(opt.StorageClass)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			return types.NewError("Write", s, path, pairs, err)
 		}
 		putOptions.XCosStorageClass = storageClass
 	}
+	if opt.HasUserMetadata {
+		header := http.Header{}
+		for k, v := range opt.UserMetadata {
+			header.Set(userMetadataPrefix+k, v)
+		}
+		putOptions.XCosMetaXXX = &header
+	}
+	if opt.HasTags {
+		putOptions.XOptionHeader = &http.Header{}
+		putOptions.XOptionHeader.Set(taggingHeader, tagging.Encode(opt.Tags))
+	}
+	if opt.HasContentType {
+		putOptions.ContentType = opt.ContentType
+	}
+	if opt.HasContentEncoding {
+		putOptions.ContentEncoding = opt.ContentEncoding
+	}
+	if opt.HasContentDisposition {
+		putOptions.ContentDisposition = opt.ContentDisposition
+	}
+	if opt.HasCacheControl {
+		putOptions.CacheControl = opt.CacheControl
+	}
+	if opt.HasExpires {
+		putOptions.Expires = opt.Expires.UTC().Format(http.TimeFormat)
+	}
 
 	_, err = s.object.Put(opt.Context, rp, r, putOptions)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleCosError(err)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 	return
 }
 
 // Stat implements Storager.Stat
 func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err error) {
-	const errorMessage = "%s Stat [%s]: %w"
-
 	opt, err := parseStoragePairStat(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfUnmodifiedSince)
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+
+	headOptions := &cos.ObjectHeadOptions{}
+	if opt.HasIfModifiedSince {
+		headOptions.IfModifiedSince = opt.IfModifiedSince.UTC().Format(http.TimeFormat)
+	}
+
+	var versionID []string
+	if opt.HasVersionID {
+		versionID = append(versionID, opt.VersionID)
 	}
 
 	rp := s.getAbsPath(path)
 
-	output, err := s.object.Head(opt.Context, rp, nil)
+	output, err := s.object.Head(opt.Context, rp, headOptions, versionID...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleCosError(err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	lastModified, err := time.Parse(time.RFC822, output.Header.Get("Last-Modified"))
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	o = &types.Object{
@@ -216,30 +422,261 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 		UpdatedAt:  lastModified,
 		ObjectMeta: metadata.NewObjectMeta(),
 	}
+	if v := output.Header.Get("ETag"); v != "" {
+		o.SetETag(v)
+		if !isEncrypted(output.Header) {
+			checksum.FromETag(o.ObjectMeta, v)
+		}
+	}
+	setObjectHeaders(o, output.Header)
 
 	storageClass, err := formatStorageClass(output.Header.Get(storageClassHeader))
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 	o.SetStorageClass(storageClass)
+	if storageClass == storageclass.Cold {
+		o.SetRestoreStatus(formatRestoreStatus(output.Header.Get(restoreHeader)))
+	}
+	if meta := formatUserMetadata(output.Header); len(meta) > 0 {
+		o.SetUserMetadata(meta)
+	}
+	if v := output.Header.Get(versionIDHeader); v != "" {
+		o.SetVersionID(v)
+	}
 
 	return o, nil
 }
 
 // Delete implements Storager.Delete
 func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
-
 	opt, err := parseStoragePairDelete(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Delete", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
-	_, err = s.object.Delete(opt.Context, rp)
+	// Key ends with "/" is a dir, cos will delete it even if there are keys under it.
+	if strings.HasSuffix(rp, "/") {
+		err = prefix.CheckDirEmpty(opt.Context, rp, s.listKeys)
+		if err != nil {
+			return types.NewError("Delete", s, path, pairs, err)
+		}
+	}
+
+	if opt.HasVersionID {
+		// cos SDK doesn't support delete with version id.
+		err = s.deleteVersion(opt.Context, rp, opt.VersionID)
+	} else {
+		_, err = s.object.Delete(opt.Context, rp)
+	}
+	if err != nil {
+		err = handleCosError(err)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	return nil
+}
+
+// GetLifecycle implements Storager.GetLifecycle
+func (s *Storage) GetLifecycle(pairs ...*types.Pair) (rules []types.LifecycleRule, err error) {
+	opt, err := parseStoragePairGetLifecycle(pairs...)
+	if err != nil {
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+
+	output, _, err := s.bucket.GetLifecycle(opt.Context)
+	var e *cos.ErrorResponse
+	if errors.As(err, &e) && e.Code == "NoSuchLifecycleConfiguration" {
+		return []types.LifecycleRule{}, nil
+	}
+	if err != nil {
+		err = handleCosError(err)
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+
+	rules = make([]types.LifecycleRule, 0, len(output.Rules))
+	for _, v := range output.Rules {
+		rule, err := formatLifecycleRule(v)
+		if err != nil {
+			return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SetLifecycle implements Storager.SetLifecycle
+func (s *Storage) SetLifecycle(rules []types.LifecycleRule, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairSetLifecycle(pairs...)
+	if err != nil {
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+
+	// cos requires at least one rule in lifecycle configuration.
+	if len(rules) == 0 {
+		return s.DeleteLifecycleWithContext(opt.Context, pairs...)
+	}
+
+	input := &cos.BucketPutLifecycleOptions{}
+	for _, v := range rules {
+		rule, err := parseLifecycleRule(v)
+		if err != nil {
+			return types.NewError("SetLifecycle", s, "", pairs, err)
+		}
+		input.Rules = append(input.Rules, rule)
+	}
+
+	_, err = s.bucket.PutLifecycle(opt.Context, input)
+	if err != nil {
+		err = handleCosError(err)
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+	return nil
+}
+
+// DeleteLifecycle implements Storager.DeleteLifecycle
+func (s *Storage) DeleteLifecycle(pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteLifecycle(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+
+	_, err = s.bucket.DeleteLifecycle(opt.Context)
+	if err != nil {
+		err = handleCosError(err)
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
 	}
 	return nil
 }
+
+// Restore implements Storager.Restore
+func (s *Storage) Restore(path string, days int, tier storageclass.RestoreTier, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairRestore(pairs...)
+	if err != nil {
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	input := &cos.ObjectRestoreOptions{Days: days}
+	if v := parseRestoreTier(tier); v != "" {
+		input.Tier = &cos.CASJobParameters{Tier: v}
+	}
+
+	_, err = s.object.PostRestore(opt.Context, rp, input)
+	var e *cos.ErrorResponse
+	if errors.As(err, &e) && e.Code == "RestoreAlreadyInProgress" {
+		return nil
+	}
+	if err != nil {
+		err = handleCosError(err)
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+	return nil
+}
+
+// Transit implements Storager.Transit
+func (s *Storage) Transit(path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairTransit(pairs...)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	class, err := parseStorageClass(storageClass)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	// PUT Object - Copy has a size limit, so we need to stat it first.
+	output, err := s.object.Head(opt.Context, rp, nil)
+	if err != nil {
+		err = handleCosError(err)
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+	// cos will omit storage class for STANDARD objects.
+	current := output.Header.Get(storageClassHeader)
+	if current == "" {
+		current = storageClassStandard
+	}
+	if current == class {
+		return nil
+	}
+	if size := output.ContentLength; size > copyObjectSizeLimit {
+		err = fmt.Errorf("%w: object size %d exceeds copy limit %d", types.ErrNotSupported, size, int64(copyObjectSizeLimit))
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	// ref: https://cloud.tencent.com/document/product/436/10881
+	source := fmt.Sprintf("%s.cos.%s.myqcloud.com/%s", s.name, s.location, rp)
+	_, _, err = s.object.Copy(opt.Context, rp, source, &cos.ObjectCopyOptions{
+		ObjectCopyHeaderOptions: &cos.ObjectCopyHeaderOptions{XCosStorageClass: class},
+	})
+	if err != nil {
+		err = handleCosError(err)
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+	return nil
+}
+
+// InitAppend implements Storager.InitAppend
+func (s *Storage) InitAppend(path string, pairs ...*types.Pair) (offset int64, err error) {
+	opt, err := parseStoragePairInitAppend(pairs...)
+	if err != nil {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	output, err := s.object.Head(opt.Context, rp, nil)
+	if err == nil {
+		if output.Header.Get(objectTypeHeader) != objectTypeAppendable {
+			err = fmt.Errorf("%w: object is not appendable", types.ErrObjectAlreadyExist)
+			return 0, types.NewError("InitAppend", s, path, pairs, err)
+		}
+		return output.ContentLength, nil
+	}
+	if err = handleCosError(err); !errors.Is(err, types.ErrObjectNotExist) {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+
+	// Appending empty content at position 0 will create an empty appendable object.
+	_, err = s.appendObject(opt.Context, rp, 0, nil)
+	if err != nil {
+		err = handleCosError(err)
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+	return 0, nil
+}
+
+// WriteAppend implements Storager.WriteAppend
+//
+// Data will be split into blocks of appendBlockSize.
+func (s *Storage) WriteAppend(path string, offset int64, r io.Reader, pairs ...*types.Pair) (next int64, err error) {
+	opt, err := parseStoragePairWriteAppend(pairs...)
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	next = offset
+	err = iowrap.ReadBlocks(r, appendBlockSize, func(block []byte) error {
+		n, err := s.appendObject(opt.Context, rp, next, block)
+		if err != nil {
+			return handleCosError(err)
+		}
+		next = n
+		return nil
+	})
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, err)
+	}
+	return next, nil
+}
@@ -0,0 +1,266 @@
+package cos
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencentyun/cos-go-sdk-v5"
+
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/types"
+	ps "github.com/Xuanwo/storage/types/pairs"
+)
+
+// newTestStorage will create a Storage which sends requests to endpoint.
+func newTestStorage(t *testing.T, endpoint string) *Storage {
+	client := &http.Client{
+		Transport: &cos.AuthorizationTransport{SecretID: "secret_id", SecretKey: "secret_key"},
+	}
+	s := newStorage("test-1250000000", "ap-guangzhou", client)
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cos.NewClient(&cos.BaseURL{BucketURL: u}, client)
+	s.bucket, s.object, s.bucketURL = c.Bucket, c.Object, u
+	return s
+}
+
+func TestStorage_WriteAppend(t *testing.T) {
+	var content []byte
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/object", r.URL.Path)
+		assert.Contains(t, r.Header.Get("Authorization"), "q-url-param-list=append;position")
+		requests++
+
+		position, err := strconv.Atoi(r.URL.Query().Get("position"))
+		assert.NoError(t, err)
+		if position != len(content) {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte("<Error><Code>PositionNotEqualToLength</Code><Message>mismatch</Message></Error>"))
+			return
+		}
+
+		data, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		content = append(content, data...)
+		w.Header().Set(nextAppendPositionHeader, strconv.Itoa(len(content)))
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	data := bytes.Repeat([]byte("a"), appendBlockSize+1)
+	next, err := s.WriteAppend("object", 0, bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), next)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, data, content)
+
+	_, err = s.WriteAppend("object", 0, strings.NewReader("content"))
+	assert.True(t, errors.Is(err, types.ErrPreconditionFailed))
+	assert.Equal(t, len(data), len(content))
+}
+
+func TestStorage_Transit(t *testing.T) {
+	cases := []struct {
+		name   string
+		class  string
+		size   int64
+		copied bool
+		err    error
+	}{
+		{"transit", "", 1024, true, nil},
+		{"already in class", storageClassArchive, 1024, false, nil},
+		{"too large", storageClassStandard, copyObjectSizeLimit + 1, false, types.ErrNotSupported},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			copied := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/object", r.URL.Path)
+
+				switch r.Method {
+				case http.MethodHead:
+					if tt.class != "" {
+						w.Header().Set(storageClassHeader, tt.class)
+					}
+					w.Header().Set("Content-Length", strconv.FormatInt(tt.size, 10))
+				case http.MethodPut:
+					copied = true
+					assert.Equal(t, storageClassArchive, r.Header.Get(storageClassHeader))
+					_, _ = w.Write([]byte("<CopyObjectResult><ETag>\"etag\"</ETag></CopyObjectResult>"))
+				}
+			}))
+			defer server.Close()
+
+			s := newTestStorage(t, server.URL)
+
+			err := s.Transit("object", storageclass.Cold)
+			assert.Equal(t, tt.copied, copied)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+		})
+	}
+}
+
+func TestStorage_ListVersions(t *testing.T) {
+	pages := []string{
+		`<ListVersionsResult>
+  <IsTruncated>true</IsTruncated>
+  <NextKeyMarker>object</NextKeyMarker>
+  <NextVersionIdMarker>v2</NextVersionIdMarker>
+  <Version><Key>object</Key><VersionId>v2</VersionId><LastModified>2020-01-02T00:00:00.000Z</LastModified><ETag>"etag2"</ETag><Size>2</Size></Version>
+  <DeleteMarker><Key>object</Key><VersionId>marker</VersionId></DeleteMarker>
+</ListVersionsResult>`,
+		`<ListVersionsResult>
+  <IsTruncated>false</IsTruncated>
+  <Version><Key>object</Key><VersionId>v1</VersionId><LastModified>2020-01-01T00:00:00.000Z</LastModified><ETag>"etag1"</ETag><Size>1</Size><StorageClass>ARCHIVE</StorageClass></Version>
+  <Version><Key>object2</Key><VersionId>v3</VersionId><LastModified>2020-01-03T00:00:00.000Z</LastModified><ETag>"etag3"</ETag><Size>3</Size></Version>
+</ListVersionsResult>`,
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/", r.URL.Path)
+		assert.Contains(t, r.Header.Get("Authorization"), "versions")
+
+		q := r.URL.Query()
+		assert.Equal(t, "object", q.Get("prefix"))
+		if requests == 0 {
+			assert.Equal(t, "", q.Get("key-marker"))
+		} else {
+			assert.Equal(t, "object", q.Get("key-marker"))
+			assert.Equal(t, "v2", q.Get("version-id-marker"))
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(pages[requests]))
+		requests++
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	objects := make([]*types.Object, 0)
+	err := s.ListVersions("object", ps.WithFileFunc(func(o *types.Object) {
+		objects = append(objects, o)
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 2, len(objects))
+
+	versionID, _ := objects[0].GetVersionID()
+	assert.Equal(t, "v2", versionID)
+	assert.Equal(t, int64(2), objects[0].Size)
+
+	versionID, _ = objects[1].GetVersionID()
+	assert.Equal(t, "v1", versionID)
+	storageClass, _ := objects[1].GetStorageClass()
+	assert.Equal(t, storageclass.Cold, storageClass)
+}
+
+func TestStorage_DeleteVersion(t *testing.T) {
+	cases := []struct {
+		name      string
+		versionID string
+		status    int
+		body      string
+		err       error
+	}{
+		{"delete", "v1", http.StatusNoContent, "", nil},
+		{"not exist", "v2", http.StatusNotFound, "<Error><Code>NoSuchVersion</Code><Message>no such version</Message></Error>", types.ErrObjectNotExist},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				assert.Equal(t, "/object", r.URL.Path)
+				assert.Equal(t, tt.versionID, r.URL.Query().Get("versionId"))
+				assert.Contains(t, r.Header.Get("Authorization"), "versionid")
+
+				if tt.body != "" {
+					w.Header().Set("Content-Type", "application/xml")
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			s := newTestStorage(t, server.URL)
+
+			err := s.Delete("object", ps.WithVersionID(tt.versionID))
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+		})
+	}
+}
+
+func TestStorage_Tags(t *testing.T) {
+	var stored []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/object", r.URL.Path)
+		assert.Equal(t, "tagging", r.URL.RawQuery)
+		assert.Contains(t, r.Header.Get("Authorization"), "q-url-param-list=tagging")
+
+		switch r.Method {
+		case http.MethodPut:
+			content, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, r.Header.Get("Content-MD5"))
+			stored = content
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/xml")
+			if stored == nil {
+				_, _ = w.Write([]byte("<Tagging><TagSet></TagSet></Tagging>"))
+				return
+			}
+			_, _ = w.Write(stored)
+		case http.MethodDelete:
+			stored = nil
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	tags, err := s.GetTags("object")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{}, tags)
+
+	err = s.SetTags("object", map[string]string{"a": "1", "b": "x y"})
+	assert.NoError(t, err)
+
+	tags, err = s.GetTags("object")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "x y"}, tags)
+
+	err = s.DeleteTags("object")
+	assert.NoError(t, err)
+
+	tags, err = s.GetTags("object")
+	assert.NoError(t, err)
+	assert.Empty(t, tags)
+}
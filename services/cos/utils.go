@@ -1,12 +1,231 @@
 package cos
 
 import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
+	"github.com/tencentyun/cos-go-sdk-v5"
+
+	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
 )
 
+// credentialTransport will refresh credential in cos.AuthorizationTransport while expired.
+type credentialTransport struct {
+	provider  credential.Provider
+	transport *cos.AuthorizationTransport
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *credentialTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.provider.IsExpired() {
+		v, err := t.provider.Retrieve()
+		if err != nil {
+			return nil, err
+		}
+		setCredential(t.transport, v)
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// setCredential will set hmac or sts credential value into cos.AuthorizationTransport.
+func setCredential(t *cos.AuthorizationTransport, v credential.Value) {
+	var token string
+	if v.Protocol == credential.ProtocolSTS {
+		token = v.Args[2]
+	}
+	t.SetCredential(v.Args[0], v.Args[1], token)
+}
+
+// isEncrypted will check whether object is encrypted by SSE-KMS or SSE-C, whose etag is not md5 of content.
+func isEncrypted(header http.Header) bool {
+	return header.Get(serverSideEncryptionHeader) == serverSideEncryptionKMS ||
+		header.Get(serverSideEncryptionCustomerHeader) != ""
+}
+
+// sendRequest will send a request which cos SDK doesn't support via client directly, and the request will be
+// signed by client's transport.
+//
+// Error response will be returned as *cos.ErrorResponse, so that it could be handled by handleCosError.
+func (s *Storage) sendRequest(ctx context.Context, method, key, query string, body io.Reader, header http.Header) (*http.Response, error) {
+	u := s.bucketURL.ResolveReference(&url.URL{
+		Path:     "/" + key,
+		RawQuery: query,
+	})
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+
+		e := &cos.ErrorResponse{Response: resp}
+		if content, err := ioutil.ReadAll(resp.Body); err == nil {
+			_ = xml.Unmarshal(content, e)
+		}
+		return nil, e
+	}
+	return resp, nil
+}
+
+// appendObject will append data to object at position and return the next append position.
+//
+// ref: https://cloud.tencent.com/document/product/436/7741
+func (s *Storage) appendObject(ctx context.Context, key string, position int64, data []byte) (next int64, err error) {
+	resp, err := s.sendRequest(ctx, http.MethodPost, key,
+		fmt.Sprintf("append&position=%d", position), bytes.NewReader(data), nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return strconv.ParseInt(resp.Header.Get(nextAppendPositionHeader), 10, 64)
+}
+
+// listVersionsResult is the response of GET Bucket Object Versions.
+//
+// ref: https://cloud.tencent.com/document/product/436/35521
+type listVersionsResult struct {
+	IsTruncated         bool
+	NextKeyMarker       string
+	NextVersionIDMarker string `xml:"NextVersionIdMarker"`
+	Versions            []struct {
+		Key          string
+		VersionID    string `xml:"VersionId"`
+		LastModified string
+		ETag         string
+		Size         int64
+		StorageClass string
+	} `xml:"Version"`
+}
+
+// listVersions will list at most limit versions of keys with prefix after keyMarker and versionIDMarker.
+//
+// Delete markers are not included.
+func (s *Storage) listVersions(ctx context.Context, prefix, keyMarker, versionIDMarker string, limit int) (*listVersionsResult, error) {
+	query := url.Values{}
+	query.Set("prefix", prefix)
+	query.Set("max-keys", strconv.Itoa(limit))
+	if keyMarker != "" {
+		query.Set("key-marker", keyMarker)
+	}
+	if versionIDMarker != "" {
+		query.Set("version-id-marker", versionIDMarker)
+	}
+
+	// query.Encode will never be empty, so "versions" could be prepended directly.
+	resp, err := s.sendRequest(ctx, http.MethodGet, "", "versions&"+query.Encode(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &listVersionsResult{}
+	err = xml.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// deleteVersion will delete the specified version of object.
+//
+// ref: https://cloud.tencent.com/document/product/436/7743
+func (s *Storage) deleteVersion(ctx context.Context, key, versionID string) error {
+	resp, err := s.sendRequest(ctx, http.MethodDelete, key, "versionId="+url.QueryEscape(versionID), nil, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// taggingBody is the request and response body of object tagging APIs.
+//
+// ref: https://cloud.tencent.com/document/product/436/42997
+type taggingBody struct {
+	XMLName xml.Name `xml:"Tagging"`
+	TagSet  []tag    `xml:"TagSet>Tag"`
+}
+
+type tag struct {
+	Key   string
+	Value string
+}
+
+// getTagging will get tags of object.
+func (s *Storage) getTagging(ctx context.Context, key string) (map[string]string, error) {
+	resp, err := s.sendRequest(ctx, http.MethodGet, key, "tagging", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &taggingBody{}
+	err = xml.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(result.TagSet))
+	for _, v := range result.TagSet {
+		tags[v.Key] = v.Value
+	}
+	return tags, nil
+}
+
+// putTagging will replace all tags of object with tags.
+func (s *Storage) putTagging(ctx context.Context, key string, tags map[string]string) error {
+	input := &taggingBody{TagSet: make([]tag, 0, len(tags))}
+	for k, v := range tags {
+		input.TagSet = append(input.TagSet, tag{Key: k, Value: v})
+	}
+	content, err := xml.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	sum := md5.Sum(content)
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+
+	resp, err := s.sendRequest(ctx, http.MethodPut, key, "tagging", bytes.NewReader(content), header)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// deleteTagging will delete all tags of object.
+func (s *Storage) deleteTagging(ctx context.Context, key string) error {
+	resp, err := s.sendRequest(ctx, http.MethodDelete, key, "tagging", nil, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
 func (s *Storage) getAbsPath(path string) string {
 	return strings.TrimPrefix(s.workDir+"/"+path, "/")
 }
@@ -15,15 +234,93 @@ func (s *Storage) getRelPath(path string) string {
 	return strings.TrimPrefix(path, s.workDir+"/")
 }
 
+// listKeys implements prefix.ListFunc
+func (s *Storage) listKeys(ctx context.Context, prefix, marker string, limit int) (keys []string, next string, err error) {
+	resp, _, err := s.bucket.Get(ctx, &cos.BucketGetOptions{
+		Prefix:  prefix,
+		Marker:  marker,
+		MaxKeys: limit,
+	})
+	if err != nil {
+		return nil, "", handleCosError(err)
+	}
+
+	keys = make([]string, 0, len(resp.Contents))
+	for _, v := range resp.Contents {
+		keys = append(keys, v.Key)
+	}
+	if resp.IsTruncated {
+		next = resp.NextMarker
+	}
+	return keys, next, nil
+}
+
 const (
 	// ref: https://cloud.tencent.com/document/product/436/7745
 	storageClassHeader = "x-cos-storage-class"
+	// ref: https://cloud.tencent.com/document/product/436/18145
+	serverSideEncryptionHeader         = "x-cos-server-side-encryption"
+	serverSideEncryptionKMS            = "cos/kms"
+	serverSideEncryptionCustomerHeader = "x-cos-server-side-encryption-customer-algorithm"
+	// userMetadataPrefix is the header prefix for user metadata.
+	userMetadataPrefix = "X-Cos-Meta-"
+	// ref: https://cloud.tencent.com/document/product/436/19889
+	versionIDHeader     = "x-cos-version-id"
+	versioningEnabled   = "Enabled"
+	versioningSuspended = "Suspended"
+	// ref: https://cloud.tencent.com/document/product/436/42993
+	taggingHeader = "x-cos-tagging"
+	// ref: https://cloud.tencent.com/document/product/436/12633
+	restoreHeader        = "x-cos-restore"
+	restoreTierExpedited = "Expedited"
+	restoreTierStandard  = "Standard"
+	restoreTierBulk      = "Bulk"
+	// ref: https://cloud.tencent.com/document/product/436/7741
+	objectTypeHeader         = "x-cos-object-type"
+	objectTypeAppendable     = "appendable"
+	nextAppendPositionHeader = "x-cos-next-append-position"
+	// appendBlockSize is the max size of data appended in one request.
+	appendBlockSize = 4 * 1024 * 1024
+	// ref: https://cloud.tencent.com/document/product/436/8280
+	lifecycleStatusEnabled  = "Enabled"
+	lifecycleStatusDisabled = "Disabled"
 
 	storageClassStandard   = "STANDARD"
 	storageClassStandardIA = "STANDARD_IA"
 	storageClassArchive    = "ARCHIVE"
 )
 
+// formatUserMetadata will get user metadata from cos response headers, keys will be converted into lower case.
+func formatUserMetadata(header http.Header) map[string]string {
+	m := make(map[string]string)
+	for k, v := range header {
+		if !strings.HasPrefix(k, userMetadataPrefix) || len(v) == 0 {
+			continue
+		}
+		m[strings.ToLower(strings.TrimPrefix(k, userMetadataPrefix))] = v[0]
+	}
+	return m
+}
+
+// setObjectHeaders will set object's http content headers from cos response headers.
+func setObjectHeaders(o *types.Object, header http.Header) {
+	if v := header.Get("Content-Type"); v != "" {
+		o.SetContentType(v)
+	}
+	if v := header.Get("Content-Encoding"); v != "" {
+		o.SetContentEncoding(v)
+	}
+	if v := header.Get("Content-Disposition"); v != "" {
+		o.SetContentDisposition(v)
+	}
+	if v := header.Get("Cache-Control"); v != "" {
+		o.SetCacheControl(v)
+	}
+	if expires, err := http.ParseTime(header.Get("Expires")); err == nil {
+		o.SetExpires(expires)
+	}
+}
+
 // parseStorageClass will parse storageclass.Type into service independent storage class type.
 func parseStorageClass(in storageclass.Type) (string, error) {
 	switch in {
@@ -52,3 +349,206 @@ func formatStorageClass(in string) (storageclass.Type, error) {
 		return "", types.ErrStorageClassNotSupported
 	}
 }
+
+// defaultCredentialSection is the section used by coscmd.
+const defaultCredentialSection = "common"
+
+// loadCredentialFile will load hmac credential from coscmd's config file, which is "~/.cos.conf" by default.
+//
+// Section "common" will be used if profile is empty.
+//
+// ref: https://cloud.tencent.com/document/product/436/10976
+func loadCredentialFile(path, profile string) (credential.Value, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return credential.Value{}, err
+	}
+	if profile == "" {
+		profile = defaultCredentialSection
+	}
+
+	var section string
+	var found bool
+	var secretID, secretKey string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != profile {
+			continue
+		}
+		found = true
+
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case "secret_id":
+			secretID = strings.TrimSpace(kv[1])
+		case "secret_key":
+			secretKey = strings.TrimSpace(kv[1])
+		}
+	}
+	if !found || secretID == "" || secretKey == "" {
+		return credential.Value{}, credential.ErrCredentialNotFound
+	}
+	return credential.Value{
+		Protocol: credential.ProtocolHmac,
+		Args:     []string{secretID, secretKey},
+	}, nil
+}
+
+// handleCosError will map cos error codes and http status codes onto sentinel errors.
+//
+// ref: https://cloud.tencent.com/document/product/436/7730
+func handleCosError(err error) error {
+	if err == nil {
+		panic("error must not be nil")
+	}
+	// Context errors will be returned as is, so caller could check them via errors.Is.
+	if types.IsContextError(err) {
+		return err
+	}
+
+	var e *cos.ErrorResponse
+	if !errors.As(err, &e) {
+		if types.IsTimeout(err) {
+			return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+	}
+
+	switch e.Code {
+	case "AccessDenied":
+		return fmt.Errorf("%w: %v", types.ErrPermissionDenied, err)
+	case "NoSuchKey", "NoSuchBucket", "NoSuchUpload":
+		return fmt.Errorf("%w: %v", types.ErrObjectNotExist, err)
+	case "InvalidAccessKeyId", "SignatureDoesNotMatch", "RequestTimeTooSkewed":
+		return fmt.Errorf("%w: %v", types.ErrConfigIncorrect, err)
+	case "BucketAlreadyExists", "BucketAlreadyOwnedByYou":
+		return fmt.Errorf("%w: %v", types.ErrObjectAlreadyExist, err)
+	case "BucketNotEmpty":
+		return fmt.Errorf("%w: %v", types.ErrDirNotEmpty, err)
+	case "InvalidStorageClass":
+		return fmt.Errorf("%w: %v", types.ErrStorageClassNotSupported, err)
+	case "PreconditionFailed", "PositionNotEqualToLength":
+		return fmt.Errorf("%w: %v", types.ErrPreconditionFailed, err)
+	case "ObjectNotAppendable":
+		return fmt.Errorf("%w: %v", types.ErrObjectAlreadyExist, err)
+	case "InvalidObjectState":
+		return fmt.Errorf("%w: %v", types.ErrObjectArchived, err)
+	case "TooManyBuckets", "QuotaExceeded":
+		return fmt.Errorf("%w: %v", types.ErrQuotaExceeded, err)
+	case "SlowDown", "TooManyRequests":
+		return fmt.Errorf("%w: %v", types.ErrRateLimited, err)
+	case "RequestTimeout":
+		return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+	case "InternalError", "ServiceUnavailable":
+		return fmt.Errorf("%w: %v", types.ErrServiceUnavailable, err)
+	case "NotImplemented", "MethodNotAllowed":
+		return fmt.Errorf("%w: %v", types.ErrNotSupported, err)
+	}
+
+	// HEAD request doesn't have error body, fallback to status code.
+	if e.Response != nil {
+		if se := types.ErrFromStatusCode(e.Response.StatusCode); se != nil {
+			return fmt.Errorf("%w: %v", se, err)
+		}
+	}
+	return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+}
+
+// parseLifecycleRule will parse types.LifecycleRule into cos lifecycle rule.
+//
+// cos SDK only supports one transition in a rule, and its AbortIncompleteMultipartUpload is marshaled with a
+// misspelled tag which will be ignored by cos, so they are not supported.
+func parseLifecycleRule(in types.LifecycleRule) (cos.BucketLifecycleRule, error) {
+	rule := cos.BucketLifecycleRule{
+		ID:     in.ID,
+		Status: lifecycleStatusDisabled,
+		Filter: &cos.BucketLifecycleFilter{Prefix: in.Prefix},
+	}
+	if in.Enabled {
+		rule.Status = lifecycleStatusEnabled
+	}
+	if len(in.Transitions) > 1 || in.AbortIncompleteSegmentDays > 0 {
+		return rule, fmt.Errorf("%w: rule %s has multiple transitions or aborts segments", types.ErrNotSupported, in.ID)
+	}
+	if in.ExpirationDays > 0 {
+		rule.Expiration = &cos.BucketLifecycleExpiration{Days: in.ExpirationDays}
+	}
+	for _, v := range in.Transitions {
+		storageClass, err := parseStorageClass(v.StorageClass)
+		if err != nil {
+			return rule, err
+		}
+		rule.Transition = &cos.BucketLifecycleTransition{
+			Days:         v.Days,
+			StorageClass: storageClass,
+		}
+	}
+	return rule, nil
+}
+
+// formatLifecycleRule will format cos lifecycle rule into types.LifecycleRule.
+func formatLifecycleRule(in cos.BucketLifecycleRule) (rule types.LifecycleRule, err error) {
+	if in.AbortIncompleteMultipartUpload != nil {
+		return rule, fmt.Errorf("%w: rule %s aborts segments", types.ErrNotSupported, in.ID)
+	}
+
+	rule.ID = in.ID
+	rule.Enabled = in.Status == lifecycleStatusEnabled
+	if in.Filter != nil {
+		rule.Prefix = in.Filter.Prefix
+	}
+
+	if v := in.Expiration; v != nil {
+		if v.Days == 0 {
+			return rule, fmt.Errorf("%w: rule %s is not expired by days", types.ErrNotSupported, in.ID)
+		}
+		rule.ExpirationDays = v.Days
+	}
+	if v := in.Transition; v != nil {
+		if v.Days == 0 {
+			return rule, fmt.Errorf("%w: rule %s is not transited by days", types.ErrNotSupported, in.ID)
+		}
+		storageClass, err := formatStorageClass(v.StorageClass)
+		if err != nil {
+			return rule, err
+		}
+		rule.Transitions = []types.LifecycleTransition{{Days: v.Days, StorageClass: storageClass}}
+	}
+	return rule, nil
+}
+
+// parseRestoreTier will parse storageclass.RestoreTier into cos tier, empty means cos's default tier.
+func parseRestoreTier(in storageclass.RestoreTier) string {
+	switch in {
+	case storageclass.RestoreTierExpedited:
+		return restoreTierExpedited
+	case storageclass.RestoreTierStandard:
+		return restoreTierStandard
+	case storageclass.RestoreTierBulk:
+		return restoreTierBulk
+	default:
+		return ""
+	}
+}
+
+// formatRestoreStatus will format x-cos-restore header of an Archive object into storageclass.RestoreStatus.
+func formatRestoreStatus(in string) storageclass.RestoreStatus {
+	switch {
+	case in == "":
+		return storageclass.RestoreStatusArchived
+	case strings.Contains(in, `ongoing-request="true"`):
+		return storageclass.RestoreStatusRestoring
+	default:
+		return storageclass.RestoreStatusRestored
+	}
+}
@@ -0,0 +1,114 @@
+package cos
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencentyun/cos-go-sdk-v5"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/metadata"
+)
+
+func TestLoadCredentialFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cos")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".cos.conf")
+	err = ioutil.WriteFile(path, []byte(`[common]
+secret_id = ak
+secret_key = sk
+region = ap-beijing
+
+# another account
+[test]
+secret_id = test-ak
+secret_key = test-sk
+`), 0600)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		profile string
+		value   credential.Value
+		err     error
+	}{
+		{"default", "", credential.Value{Protocol: credential.ProtocolHmac, Args: []string{"ak", "sk"}}, nil},
+		{"profile", "test", credential.Value{Protocol: credential.ProtocolHmac, Args: []string{"test-ak", "test-sk"}}, nil},
+		{"not found", "not-exist", credential.Value{}, credential.ErrCredentialNotFound},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := loadCredentialFile(path, tt.profile)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+			assert.Equal(t, tt.value, v)
+		})
+	}
+}
+
+func TestHandleCosError(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    error
+		expected error
+	}{
+		{"non-cos error", errors.New("test"), types.ErrUnhandledError},
+		{"no such key", &cos.ErrorResponse{Code: "NoSuchKey"}, types.ErrObjectNotExist},
+		{"slow down", &cos.ErrorResponse{Code: "SlowDown"}, types.ErrRateLimited},
+		{"object archived", &cos.ErrorResponse{Code: "InvalidObjectState"}, types.ErrObjectArchived},
+		{
+			"head without code",
+			&cos.ErrorResponse{Response: &http.Response{StatusCode: 412}},
+			types.ErrPreconditionFailed,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, errors.Is(handleCosError(tt.input), tt.expected))
+		})
+	}
+}
+
+func TestFormatUserMetadata(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-cos-meta-job-id", "123")
+	header.Set("x-cos-storage-class", "STANDARD")
+
+	assert.Equal(t, map[string]string{"job-id": "123"}, formatUserMetadata(header))
+}
+
+func TestSetObjectHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "text/html")
+	header.Set("Cache-Control", "max-age=3600")
+	header.Set("Expires", "Thu, 01 Dec 1994 16:00:00 GMT")
+
+	o := &types.Object{ObjectMeta: metadata.NewObjectMeta()}
+	setObjectHeaders(o, header)
+	assert.Equal(t, "text/html", o.MustGetContentType())
+	assert.Equal(t, "max-age=3600", o.MustGetCacheControl())
+	assert.Equal(t, time.Date(1994, 12, 1, 16, 0, 0, 0, time.UTC), o.MustGetExpires())
+	_, ok := o.GetContentEncoding()
+	assert.False(t, ok)
+
+	// Invalid Expires should be ignored.
+	header.Set("Expires", "0")
+	o = &types.Object{ObjectMeta: metadata.NewObjectMeta()}
+	setObjectHeaders(o, header)
+	_, ok = o.GetExpires()
+	assert.False(t, ok)
+}
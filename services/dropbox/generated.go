@@ -4,12 +4,14 @@ package dropbox
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 
 	"github.com/Xuanwo/storage"
 	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/pkg/iterator"
 	"github.com/Xuanwo/storage/pkg/segment"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
@@ -19,9 +21,11 @@ import (
 
 var _ credential.Provider
 var _ endpoint.Provider
+var _ iterator.ObjectIterator
 var _ segment.Segment
 var _ storage.Storager
 var _ storageclass.Type
+var _ time.Duration
 
 // Type is the type for dropbox
 const Type = "dropbox"
@@ -31,6 +35,14 @@ type pairStorageDelete struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
 }
 
 func parseStoragePairDelete(opts ...*types.Pair) (*pairStorageDelete, error) {
@@ -52,6 +64,56 @@ func parseStoragePairDelete(opts ...*types.Pair) (*pairStorageDelete, error) {
 		result.Context = context.Background()
 	}
 
+	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	return result, nil
+}
+
+type pairStorageDeleteAll struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairDeleteAll(opts ...*types.Pair) (*pairStorageDeleteAll, error) {
+	result := &pairStorageDeleteAll{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
 	// Parse meta-defined pairs
 	return result, nil
 }
@@ -93,6 +155,43 @@ func parseStoragePairInit(opts ...*types.Pair) (*pairStorageInit, error) {
 	return result, nil
 }
 
+type pairStorageIterate struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+	HasContinuationToken bool
+	ContinuationToken    string
+}
+
+func parseStoragePairIterate(opts ...*types.Pair) (*pairStorageIterate, error) {
+	result := &pairStorageIterate{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	v, ok = values[ps.ContinuationToken]
+	if ok {
+		result.HasContinuationToken = true
+		result.ContinuationToken = v.(string)
+	}
+	return result, nil
+}
+
 type pairStorageList struct {
 	// Pre-defined pairs
 	Context context.Context
@@ -173,7 +272,7 @@ type pairStorageNew struct {
 
 	// Meta-defined pairs
 	HasCredential bool
-	Credential    *credential.Provider
+	Credential    credential.Provider
 }
 
 func parseStoragePairNew(opts ...*types.Pair) (*pairStorageNew, error) {
@@ -202,7 +301,7 @@ func parseStoragePairNew(opts ...*types.Pair) (*pairStorageNew, error) {
 	}
 	if ok {
 		result.HasCredential = true
-		result.Credential = v.(*credential.Provider)
+		result.Credential = v.(credential.Provider)
 	}
 	return result, nil
 }
@@ -212,8 +311,18 @@ type pairStorageRead struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasSize bool
-	Size    int64
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasSize              bool
+	Size                 int64
+	HasVerifyChecksum    bool
+	VerifyChecksum       bool
 }
 
 func parseStoragePairRead(opts ...*types.Pair) (*pairStorageRead, error) {
@@ -236,11 +345,36 @@ func parseStoragePairRead(opts ...*types.Pair) (*pairStorageRead, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
 	v, ok = values[ps.Size]
 	if ok {
 		result.HasSize = true
 		result.Size = v.(int64)
 	}
+	v, ok = values[ps.VerifyChecksum]
+	if ok {
+		result.HasVerifyChecksum = true
+		result.VerifyChecksum = v.(bool)
+	}
 	return result, nil
 }
 
@@ -249,6 +383,14 @@ type pairStorageStat struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
 }
 
 func parseStoragePairStat(opts ...*types.Pair) (*pairStorageStat, error) {
@@ -271,6 +413,26 @@ func parseStoragePairStat(opts ...*types.Pair) (*pairStorageStat, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
 	return result, nil
 }
 
@@ -279,8 +441,16 @@ type pairStorageWrite struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasSize bool
-	Size    int64
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasSize              bool
+	Size                 int64
 }
 
 func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
@@ -303,6 +473,26 @@ func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
 	v, ok = values[ps.Size]
 	if ok {
 		result.HasSize = true
@@ -320,6 +510,15 @@ func (s *Storage) DeleteWithContext(ctx context.Context, path string, pairs ...*
 	return s.Delete(path, pairs...)
 }
 
+// DeleteAllWithContext adds context support for DeleteAll.
+func (s *Storage) DeleteAllWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/dropbox.storage.DeleteAll")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.DeleteAll(path, pairs...)
+}
+
 // InitWithContext adds context support for Init.
 func (s *Storage) InitWithContext(ctx context.Context, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/dropbox.storage.Init")
@@ -329,6 +528,15 @@ func (s *Storage) InitWithContext(ctx context.Context, pairs ...*types.Pair) (er
 	return s.Init(pairs...)
 }
 
+// IterateWithContext adds context support for Iterate.
+func (s *Storage) IterateWithContext(ctx context.Context, path string, pairs ...*types.Pair) (it *iterator.ObjectIterator, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/dropbox.storage.Iterate")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.Iterate(path, pairs...)
+}
+
 // ListWithContext adds context support for List.
 func (s *Storage) ListWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/dropbox.storage.List")
@@ -0,0 +1,34 @@
+package dropbox
+
+import (
+	"github.com/Xuanwo/storage"
+	"github.com/Xuanwo/storage/coreutils"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+func init() {
+	coreutils.Register(Type, open)
+}
+
+// open implements coreutils.OpenFunc
+func open(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
+	err = coreutils.CheckOptions(opt, pairs.Credential, pairs.WorkDir)
+	if err != nil {
+		return
+	}
+
+	store, err = New(opt...)
+	if err != nil {
+		return
+	}
+
+	if ns != "" {
+		opt = append(opt, pairs.WithWorkDir(ns))
+	}
+	err = store.Init(opt...)
+	if err != nil {
+		return
+	}
+	return
+}
@@ -1,6 +1,7 @@
 package dropbox
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -9,10 +10,13 @@ import (
 	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox"
 	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
 
+	"github.com/Xuanwo/storage/pkg/checksum"
 	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/iowrap"
+	"github.com/Xuanwo/storage/pkg/iterator"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/metadata"
+	ps "github.com/Xuanwo/storage/types/pairs"
 )
 
 // Storage is the dropbox client.
@@ -25,22 +29,31 @@ type Storage struct {
 }
 
 // New will create a new client.
+//
+// Credential will only be retrieved once, because dropbox SDK sets access token in client
+// config which can't be replaced.
 func New(pairs ...*types.Pair) (s *Storage, err error) {
-	const errorMessage = "%s New: %w"
-
 	opt, err := parseStoragePairNew(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
 	cfg := dropbox.Config{}
 
-	credProtocol, cred := opt.Credential.Protocol(), opt.Credential.Value()
-	switch credProtocol {
+	cred, err := opt.Credential.Retrieve()
+	if err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	switch cred.Protocol {
 	case credential.ProtocolAPIKey:
-		cfg.Token = cred[0]
+		cfg.Token = cred.Args[0]
+	case credential.ProtocolFile:
+		cfg.Token, err = loadTokenFile(cred.File())
+		if err != nil {
+			return nil, types.NewError("New", s, "", pairs, err)
+		}
 	default:
-		return nil, fmt.Errorf(errorMessage, s, credential.ErrUnsupportedProtocol)
+		return nil, types.NewError("New", s, "", pairs, credential.ErrUnsupportedProtocol)
 	}
 
 	c := &Storage{
@@ -52,11 +65,9 @@ func New(pairs ...*types.Pair) (s *Storage, err error) {
 
 // Init implements Storager.Init
 func (s *Storage) Init(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Init: %w"
-
 	opt, err := parseStoragePairInit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("Init", s, "", pairs, err)
 	}
 
 	if opt.HasWorkDir {
@@ -86,75 +97,77 @@ func (s *Storage) Metadata(pairs ...*types.Pair) (m metadata.StorageMeta, err er
 
 // List implements Storager.List
 func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List [%s]: %w"
-
 	opt, err := parseStoragePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("List", s, path, pairs, err)
 	}
 
+	cursor := ""
 	rp := s.getAbsPath(path)
 
-	result, err := s.client.ListFolder(&files.ListFolderArg{
-		Path: rp,
-	})
-	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
-	}
-
 	for {
-		for _, v := range result.Entries {
-			switch meta := v.(type) {
-			case *files.FileMetadata:
-				o := &types.Object{
-					ID:         meta.Id,
-					Type:       types.ObjectTypeFile,
-					Name:       filepath.Join(path, meta.Name),
-					Size:       int64(meta.Size),
-					UpdatedAt:  meta.ServerModified,
-					ObjectMeta: metadata.NewObjectMeta(),
-				}
-				// TODO: manage ContentHash
-
-				if opt.HasFileFunc {
-					opt.FileFunc(o)
-				}
-			case *files.FolderMetadata:
-				o := &types.Object{
-					ID:         meta.Id,
-					Type:       types.ObjectTypeDir,
-					Name:       filepath.Join(path, meta.Name),
-					ObjectMeta: metadata.NewObjectMeta(),
-				}
+		objects, next, err := s.listObjects(opt.Context, path, rp, cursor)
+		if err != nil {
+			return types.NewError("List", s, path, pairs, err)
+		}
 
+		for _, o := range objects {
+			if o.Type == types.ObjectTypeDir {
 				if opt.HasDirFunc {
 					opt.DirFunc(o)
 				}
-			default:
-				return fmt.Errorf(errorMessage, s, path, ErrUnexpectedEntry)
+				continue
+			}
+			if opt.HasFileFunc {
+				opt.FileFunc(o)
 			}
 		}
-		if !result.HasMore {
-			break
+
+		if next == "" {
+			return nil
 		}
+		cursor = next
+	}
+}
 
-		result, err = s.client.ListFolderContinue(&files.ListFolderContinueArg{
-			Cursor: result.Cursor,
-		})
+// Iterate implements Storager.Iterate
+func (s *Storage) Iterate(path string, pairs ...*types.Pair) (it *iterator.ObjectIterator, err error) {
+	opt, err := parseStoragePairIterate(pairs...)
+	if err != nil {
+		return nil, types.NewError("Iterate", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	it, err = iterator.NewObjectIterator(func(cursor string) ([]*types.Object, string, error) {
+		objects, next, err := s.listObjects(opt.Context, path, rp, cursor)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			return nil, "", types.NewError("Iterate", s, path, pairs, err)
 		}
+		return objects, next, nil
+	}, opt.ContinuationToken)
+	if err != nil {
+		return nil, types.NewError("Iterate", s, path, pairs, err)
 	}
-	return
+	return it, nil
 }
 
 // Read implements Storager.Read
+//
+// Content hash only covers the whole file, so verify_checksum could not be used with size,
+// and types.ErrChecksumNotAvailable will be returned.
 func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err error) {
-	const errorMessage = "%s Read [%s]: %w"
-
 	opt, err := parseStoragePairRead(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+	if opt.HasVerifyChecksum && opt.VerifyChecksum && opt.HasSize {
+		err = fmt.Errorf("%w: content hash could not verify partial content", types.ErrChecksumNotAvailable)
+		return nil, types.NewError("Read", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
@@ -163,9 +176,27 @@ func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err
 		Path: rp,
 	}
 
-	_, r, err = s.client.Download(input)
+	if err = opt.Context.Err(); err != nil {
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+
+	meta, r, err := s.client.Download(input)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleDropboxError(err)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+	r = iowrap.ContextReadCloser(opt.Context, r)
+
+	if opt.HasVerifyChecksum && opt.VerifyChecksum {
+		m := metadata.NewObjectMeta()
+		m.SetDropboxContentHash(meta.ContentHash)
+
+		vr, err := checksum.NewVerifyReadCloser(r, m)
+		if err != nil {
+			r.Close()
+			return nil, types.NewError("Read", s, path, pairs, err)
+		}
+		r = vr
 	}
 
 	if opt.HasSize {
@@ -177,15 +208,18 @@ func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err
 
 // Write implements Storager.Write
 func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Write [%s]: %w"
-
 	opt, err := parseStoragePairWrite(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
+	r = iowrap.ContextReader(opt.Context, r)
 	if opt.HasSize {
 		r = io.LimitReader(r, opt.Size)
 	}
@@ -201,7 +235,8 @@ func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err err
 
 	_, err = s.client.Upload(input, r)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleDropboxError(err)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 
 	return nil
@@ -209,7 +244,17 @@ func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err err
 
 // Stat implements Storager.Stat
 func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err error) {
-	const errorMessage = "%s Stat [%s]: %w"
+	opt, err := parseStoragePairStat(pairs...)
+	if err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
@@ -219,7 +264,8 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 
 	output, err := s.client.GetMetadata(input)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleDropboxError(err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	switch meta := output.(type) {
@@ -232,7 +278,7 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 			UpdatedAt:  meta.ServerModified,
 			ObjectMeta: metadata.NewObjectMeta(),
 		}
-		// TODO: manage ContentHash
+		o.SetDropboxContentHash(meta.ContentHash)
 
 		return o, nil
 	case *files.FolderMetadata:
@@ -255,18 +301,63 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 
 // Delete implements Storager.Delete
 func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
+	opt, err := parseStoragePairDelete(pairs...)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
+	err = s.checkDirEmpty(rp)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+
 	input := &files.DeleteArg{
 		Path: rp,
 	}
 
 	_, err = s.client.DeleteV2(input)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleDropboxError(err)
+		return types.NewError("Delete", s, path, pairs, err)
 	}
 
 	return nil
 }
+
+// DeleteAll implements Storager.DeleteAll
+func (s *Storage) DeleteAll(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteAll(pairs...)
+	if err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+	if err = types.CheckNotRoot(path); err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	// DeleteV2 will delete a folder with all its contents.
+	_, err = s.client.DeleteV2(&files.DeleteArg{
+		Path: rp,
+	})
+	if err != nil {
+		err = handleDropboxError(err)
+		if errors.Is(err, types.ErrObjectNotExist) {
+			return nil
+		}
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+	return nil
+}
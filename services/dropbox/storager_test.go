@@ -0,0 +1,18 @@
+package dropbox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+func TestStorage_ReadVerifyChecksumWithSize(t *testing.T) {
+	s := &Storage{}
+
+	_, err := s.Read("test", pairs.WithVerifyChecksum(true), pairs.WithSize(1))
+	assert.True(t, errors.Is(err, types.ErrChecksumNotAvailable))
+}
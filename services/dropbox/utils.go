@@ -1,9 +1,160 @@
 package dropbox
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/auth"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/metadata"
 )
 
 func (s *Storage) getAbsPath(path string) string {
 	return strings.TrimPrefix(s.workDir+"/"+path, "/")
 }
+
+// loadTokenFile will load access token from file, dropbox's token file doesn't have profiles.
+func loadTokenFile(path, profile string) (string, error) {
+	if profile != "" {
+		return "", credential.ErrInvalidConfig
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", credential.ErrCredentialNotFound
+	}
+	return token, nil
+}
+
+// handleDropboxError will map dropbox errors onto sentinel errors.
+//
+// dropbox returns different error types for different endpoints, but all of them carry
+// an error summary like "path/not_found/..", so we check the tags in summary.
+//
+// ref: https://www.dropbox.com/developers/documentation/http/documentation#error-handling
+func handleDropboxError(err error) error {
+	if err == nil {
+		panic("error must not be nil")
+	}
+	// Context errors will be returned as is, so caller could check them via errors.Is.
+	if types.IsContextError(err) {
+		return err
+	}
+
+	switch err.(type) {
+	case auth.AuthAPIError:
+		return fmt.Errorf("%w: %v", types.ErrConfigIncorrect, err)
+	case auth.AccessAPIError:
+		return fmt.Errorf("%w: %v", types.ErrPermissionDenied, err)
+	case auth.RateLimitAPIError:
+		return fmt.Errorf("%w: %v", types.ErrRateLimited, err)
+	}
+
+	summary := err.Error()
+	switch {
+	case strings.Contains(summary, "not_found"):
+		return fmt.Errorf("%w: %v", types.ErrObjectNotExist, err)
+	case strings.Contains(summary, "conflict"):
+		return fmt.Errorf("%w: %v", types.ErrObjectAlreadyExist, err)
+	case strings.Contains(summary, "insufficient_space"), strings.Contains(summary, "insufficient_quota"):
+		return fmt.Errorf("%w: %v", types.ErrQuotaExceeded, err)
+	case strings.Contains(summary, "no_write_permission"), strings.Contains(summary, "restricted_content"):
+		return fmt.Errorf("%w: %v", types.ErrPermissionDenied, err)
+	case strings.Contains(summary, "too_many_write_operations"), strings.Contains(summary, "too_many_requests"):
+		return fmt.Errorf("%w: %v", types.ErrRateLimited, err)
+	case strings.Contains(summary, "internal_error"):
+		return fmt.Errorf("%w: %v", types.ErrServiceUnavailable, err)
+	}
+
+	if types.IsTimeout(err) {
+		return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+	}
+	return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+}
+
+// checkDirEmpty will return ErrDirNotEmpty if rp is a folder with any entries.
+//
+// dropbox's DeleteV2 will delete a folder with all its contents, so we need to check it before Delete.
+func (s *Storage) checkDirEmpty(rp string) error {
+	result, err := s.client.ListFolder(&files.ListFolderArg{
+		Path:  rp,
+		Limit: 1,
+	})
+	if err != nil {
+		// rp is a file or doesn't exist, leave them to DeleteV2.
+		summary := err.Error()
+		if strings.Contains(summary, files.LookupErrorNotFolder) || strings.Contains(summary, files.LookupErrorNotFound) {
+			return nil
+		}
+		return handleDropboxError(err)
+	}
+	if len(result.Entries) > 0 {
+		return types.ErrDirNotEmpty
+	}
+	return nil
+}
+
+// listObjects will list a page of objects under rp, cursor returned by dropbox is used as the marker.
+func (s *Storage) listObjects(ctx context.Context, path, rp, cursor string) (objects []*types.Object, next string, err error) {
+	// dropbox sdk doesn't support context, check it before every request.
+	if err = ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var result *files.ListFolderResult
+	if cursor == "" {
+		result, err = s.client.ListFolder(&files.ListFolderArg{
+			Path: rp,
+		})
+	} else {
+		result, err = s.client.ListFolderContinue(&files.ListFolderContinueArg{
+			Cursor: cursor,
+		})
+	}
+	if err != nil {
+		return nil, "", handleDropboxError(err)
+	}
+
+	objects = make([]*types.Object, 0, len(result.Entries))
+	for _, v := range result.Entries {
+		switch meta := v.(type) {
+		case *files.FileMetadata:
+			o := &types.Object{
+				ID:         meta.Id,
+				Type:       types.ObjectTypeFile,
+				Name:       filepath.Join(path, meta.Name),
+				Size:       int64(meta.Size),
+				UpdatedAt:  meta.ServerModified,
+				ObjectMeta: metadata.NewObjectMeta(),
+			}
+			o.SetDropboxContentHash(meta.ContentHash)
+
+			objects = append(objects, o)
+		case *files.FolderMetadata:
+			o := &types.Object{
+				ID:         meta.Id,
+				Type:       types.ObjectTypeDir,
+				Name:       filepath.Join(path, meta.Name),
+				ObjectMeta: metadata.NewObjectMeta(),
+			}
+
+			objects = append(objects, o)
+		default:
+			return nil, "", ErrUnexpectedEntry
+		}
+	}
+
+	if result.HasMore {
+		next = result.Cursor
+	}
+	return objects, next, nil
+}
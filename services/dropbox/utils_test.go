@@ -0,0 +1,41 @@
+package dropbox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/auth"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/types"
+)
+
+func TestHandleDropboxError(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    error
+		expected error
+	}{
+		{"auth", auth.AuthAPIError{}, types.ErrConfigIncorrect},
+		{"rate limit", auth.RateLimitAPIError{}, types.ErrRateLimited},
+		{
+			"not found",
+			files.DownloadAPIError{APIError: dropbox.APIError{ErrorSummary: "path/not_found/.."}},
+			types.ErrObjectNotExist,
+		},
+		{
+			"insufficient space",
+			files.UploadAPIError{APIError: dropbox.APIError{ErrorSummary: "path/insufficient_space/.."}},
+			types.ErrQuotaExceeded,
+		},
+		{"not handled", errors.New("test"), types.ErrUnhandledError},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, errors.Is(handleDropboxError(tt.input), tt.expected))
+		})
+	}
+}
@@ -4,12 +4,14 @@ package fs
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 
 	"github.com/Xuanwo/storage"
 	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/pkg/iterator"
 	"github.com/Xuanwo/storage/pkg/segment"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
@@ -19,9 +21,11 @@ import (
 
 var _ credential.Provider
 var _ endpoint.Provider
+var _ iterator.ObjectIterator
 var _ segment.Segment
 var _ storage.Storager
 var _ storageclass.Type
+var _ time.Duration
 
 // Type is the type for fs
 const Type = "fs"
@@ -61,6 +65,14 @@ type pairStorageDelete struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
 }
 
 func parseStoragePairDelete(opts ...*types.Pair) (*pairStorageDelete, error) {
@@ -82,6 +94,56 @@ func parseStoragePairDelete(opts ...*types.Pair) (*pairStorageDelete, error) {
 		result.Context = context.Background()
 	}
 
+	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	return result, nil
+}
+
+type pairStorageDeleteAll struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairDeleteAll(opts ...*types.Pair) (*pairStorageDeleteAll, error) {
+	result := &pairStorageDeleteAll{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
 	// Parse meta-defined pairs
 	return result, nil
 }
@@ -126,6 +188,36 @@ func parseStoragePairInit(opts ...*types.Pair) (*pairStorageInit, error) {
 	return result, nil
 }
 
+type pairStorageInitAppend struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairInitAppend(opts ...*types.Pair) (*pairStorageInitAppend, error) {
+	result := &pairStorageInitAppend{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
 type pairStorageList struct {
 	// Pre-defined pairs
 	Context context.Context
@@ -265,10 +357,18 @@ type pairStorageRead struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasOffset bool
-	Offset    int64
-	HasSize   bool
-	Size      int64
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasOffset            bool
+	Offset               int64
+	HasSize              bool
+	Size                 int64
 }
 
 func parseStoragePairRead(opts ...*types.Pair) (*pairStorageRead, error) {
@@ -291,6 +391,26 @@ func parseStoragePairRead(opts ...*types.Pair) (*pairStorageRead, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
 	v, ok = values[ps.Offset]
 	if ok {
 		result.HasOffset = true
@@ -309,6 +429,14 @@ type pairStorageStat struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
 }
 
 func parseStoragePairStat(opts ...*types.Pair) (*pairStorageStat, error) {
@@ -331,6 +459,26 @@ func parseStoragePairStat(opts ...*types.Pair) (*pairStorageStat, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
 	return result, nil
 }
 
@@ -339,8 +487,16 @@ type pairStorageWrite struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasSize bool
-	Size    int64
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasSize              bool
+	Size                 int64
 }
 
 func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
@@ -363,6 +519,26 @@ func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
 	v, ok = values[ps.Size]
 	if ok {
 		result.HasSize = true
@@ -371,6 +547,36 @@ func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
 	return result, nil
 }
 
+type pairStorageWriteAppend struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairWriteAppend(opts ...*types.Pair) (*pairStorageWriteAppend, error) {
+	result := &pairStorageWriteAppend{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
 // CopyWithContext adds context support for Copy.
 func (s *Storage) CopyWithContext(ctx context.Context, src, dst string, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/fs.storage.Copy")
@@ -389,6 +595,15 @@ func (s *Storage) DeleteWithContext(ctx context.Context, path string, pairs ...*
 	return s.Delete(path, pairs...)
 }
 
+// DeleteAllWithContext adds context support for DeleteAll.
+func (s *Storage) DeleteAllWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/fs.storage.DeleteAll")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.DeleteAll(path, pairs...)
+}
+
 // InitWithContext adds context support for Init.
 func (s *Storage) InitWithContext(ctx context.Context, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/fs.storage.Init")
@@ -398,6 +613,15 @@ func (s *Storage) InitWithContext(ctx context.Context, pairs ...*types.Pair) (er
 	return s.Init(pairs...)
 }
 
+// InitAppendWithContext adds context support for InitAppend.
+func (s *Storage) InitAppendWithContext(ctx context.Context, path string, pairs ...*types.Pair) (offset int64, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/fs.storage.InitAppend")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.InitAppend(path, pairs...)
+}
+
 // ListWithContext adds context support for List.
 func (s *Storage) ListWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/fs.storage.List")
@@ -451,3 +675,12 @@ func (s *Storage) WriteWithContext(ctx context.Context, path string, r io.Reader
 	pairs = append(pairs, ps.WithContext(ctx))
 	return s.Write(path, r, pairs...)
 }
+
+// WriteAppendWithContext adds context support for WriteAppend.
+func (s *Storage) WriteAppendWithContext(ctx context.Context, path string, offset int64, r io.Reader, pairs ...*types.Pair) (next int64, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/fs.storage.WriteAppend")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.WriteAppend(path, offset, r, pairs...)
+}
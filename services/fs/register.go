@@ -0,0 +1,30 @@
+package fs
+
+import (
+	"github.com/Xuanwo/storage"
+	"github.com/Xuanwo/storage/coreutils"
+	"github.com/Xuanwo/storage/pkg/namespace"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+func init() {
+	coreutils.Register(Type, open)
+}
+
+// open implements coreutils.OpenFunc
+func open(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
+	// Namespace is always the work dir of fs, so no option could be applied.
+	err = coreutils.CheckOptions(opt)
+	if err != nil {
+		return
+	}
+
+	store = New()
+	path := namespace.ParseLocalFS(ns)
+	err = store.Init(pairs.WithWorkDir(path))
+	if err != nil {
+		return
+	}
+	return
+}
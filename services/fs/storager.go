@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/Xuanwo/storage/pkg/iowrap"
 	"github.com/Xuanwo/storage/types"
@@ -22,6 +23,12 @@ type Storage struct {
 	// options for this storager.
 	workDir string // workDir dir for all operation.
 
+	// lock makes preconditions check and operation atomic, only held by operations with preconditions.
+	//
+	// lock only works within one Storage in one process, preconditions are NOT atomic among multiple
+	// processes or Storages on the same dir.
+	lock sync.Mutex
+
 	// All stdlib call will be added here for better unit test.
 	ioCopyBuffer  func(dst io.Writer, src io.Reader, buf []byte) (written int64, err error)
 	ioCopyN       func(dst io.Writer, src io.Reader, n int64) (written int64, err error)
@@ -29,7 +36,9 @@ type Storage struct {
 	osCreate      func(name string) (*os.File, error)
 	osMkdirAll    func(path string, perm os.FileMode) error
 	osOpen        func(name string) (*os.File, error)
+	osOpenFile    func(name string, flag int, perm os.FileMode) (*os.File, error)
 	osRemove      func(name string) error
+	osRemoveAll   func(path string) error
 	osRename      func(oldpath, newpath string) error
 	osStat        func(name string) (os.FileInfo, error)
 }
@@ -43,7 +52,9 @@ func New() *Storage {
 		osCreate:      os.Create,
 		osMkdirAll:    os.MkdirAll,
 		osOpen:        os.Open,
+		osOpenFile:    os.OpenFile,
 		osRemove:      os.Remove,
+		osRemoveAll:   os.RemoveAll,
 		osRename:      os.Rename,
 		osStat:        os.Stat,
 	}
@@ -56,11 +67,9 @@ func (s *Storage) String() string {
 
 // Init implements Storager.Init
 func (s *Storage) Init(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Init: %w"
-
 	opt, err := parseStoragePairInit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("Init", s, "", pairs, err)
 	}
 
 	if opt.HasWorkDir {
@@ -79,7 +88,10 @@ func (s *Storage) Metadata(pairs ...*types.Pair) (m metadata.StorageMeta, err er
 
 // Stat implements Storager.Stat
 func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err error) {
-	const errorMessage = "%s Stat [%s]: %w"
+	opt, err := parseStoragePairStat(pairs...)
+	if err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
 
 	if path == "-" {
 		return &types.Object{
@@ -95,7 +107,11 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 
 	fi, err := s.osStat(rp)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, handleOsError(err))
+		return nil, types.NewError("Stat", s, path, pairs, handleOsError(err))
+	}
+	err = checkPreconditions(fi, opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince)
+	if err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	o = &types.Object{
@@ -125,20 +141,62 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 
 // Delete implements Storager.Delete
 func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
+	opt, err := parseStoragePairDelete(pairs...)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
+	if hasPreconditions(opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		fi, err := s.statForPreconditions(rp)
+		if err != nil {
+			return types.NewError("Delete", s, path, pairs, handleOsError(err))
+		}
+		err = checkPreconditions(fi, opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince)
+		if err != nil {
+			return types.NewError("Delete", s, path, pairs, err)
+		}
+	}
+
 	err = s.osRemove(rp)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, handleOsError(err))
+		return types.NewError("Delete", s, path, pairs, handleOsError(err))
+	}
+	return nil
+}
+
+// DeleteAll implements Storager.DeleteAll
+func (s *Storage) DeleteAll(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteAll(pairs...)
+	if err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+	if err = types.CheckNotRoot(path); err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	err = s.osRemoveAll(rp)
+	if err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, handleOsError(err))
 	}
 	return nil
 }
 
 // Copy implements Storager.Copy
 func (s *Storage) Copy(src, dst string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Copy from [%s] to [%s]: %w"
+	opt, err := parseStoragePairCopy(pairs...)
+	if err != nil {
+		return types.NewErrorWithDst("Copy", s, src, dst, pairs, err)
+	}
 
 	rs := s.getAbsPath(src)
 	rd := s.getAbsPath(dst)
@@ -146,65 +204,66 @@ func (s *Storage) Copy(src, dst string, pairs ...*types.Pair) (err error) {
 	// Create dir for dst.
 	err = s.createDir(dst)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, src, dst, err)
+		return types.NewErrorWithDst("Copy", s, src, dst, pairs, err)
 	}
 
 	srcFile, err := s.osOpen(rs)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, src, dst, handleOsError(err))
+		return types.NewErrorWithDst("Copy", s, src, dst, pairs, handleOsError(err))
 	}
 	defer srcFile.Close()
 
 	dstFile, err := s.osCreate(rd)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, src, dst, handleOsError(err))
+		return types.NewErrorWithDst("Copy", s, src, dst, pairs, handleOsError(err))
 	}
 	defer dstFile.Close()
 
-	_, err = s.ioCopyBuffer(dstFile, srcFile, make([]byte, 1024*1024))
+	// Copy could take a long time, wrap src to stop copying once context is done.
+	_, err = s.ioCopyBuffer(dstFile, iowrap.ContextReader(opt.Context, srcFile), make([]byte, 1024*1024))
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, src, dst, handleOsError(err))
+		return types.NewErrorWithDst("Copy", s, src, dst, pairs, handleOsError(err))
 	}
 	return
 }
 
 // Move implements Storager.Move
 func (s *Storage) Move(src, dst string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Move from [%s] to [%s]: %w"
-
 	rs := s.getAbsPath(src)
 	rd := s.getAbsPath(dst)
 
 	// Create dir for dst path.
 	err = s.createDir(dst)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, src, dst, err)
+		return types.NewErrorWithDst("Move", s, src, dst, pairs, err)
 	}
 
 	err = s.osRename(rs, rd)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, src, dst, handleOsError(err))
+		return types.NewErrorWithDst("Move", s, src, dst, pairs, handleOsError(err))
 	}
 	return
 }
 
 // List implements Storager.List
 func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List [%s]: %w"
-
 	opt, err := parseStoragePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("List", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
 	fi, err := s.ioutilReadDir(rp)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, handleOsError(err))
+		return types.NewError("List", s, path, pairs, handleOsError(err))
 	}
 
 	for _, v := range fi {
+		if err = opt.Context.Err(); err != nil {
+			return types.NewError("List", s, path, pairs, err)
+		}
+
 		o := &types.Object{
 			ID:         filepath.Join(rp, v.Name()),
 			Name:       filepath.Join(path, v.Name()),
@@ -231,11 +290,9 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 
 // Read implements Storager.Read
 func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err error) {
-	const errorMessage = "%s Read [%s]: %w"
-
 	opt, err := parseStoragePairRead(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Read", s, path, pairs, err)
 	}
 
 	// If path is "-", return stdin directly.
@@ -249,9 +306,26 @@ func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err
 
 	rp := s.getAbsPath(path)
 
+	if hasPreconditions(opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+	}
+
 	f, err := s.osOpen(rp)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, handleOsError(err))
+		return nil, types.NewError("Read", s, path, pairs, handleOsError(err))
+	}
+	if hasPreconditions(opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince) {
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, types.NewError("Read", s, path, pairs, handleOsError(err))
+		}
+		err = checkPreconditions(fi, opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince)
+		if err != nil {
+			f.Close()
+			return nil, types.NewError("Read", s, path, pairs, err)
+		}
 	}
 	if opt.HasSize && opt.HasOffset {
 		return iowrap.SectionReadCloser(f, opt.Offset, opt.Size), nil
@@ -262,7 +336,7 @@ func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err
 	if opt.HasOffset {
 		_, err = f.Seek(opt.Offset, 0)
 		if err != nil {
-			return nil, fmt.Errorf(errorMessage, s, path, handleOsError(err))
+			return nil, types.NewError("Read", s, path, pairs, handleOsError(err))
 		}
 	}
 	return f, nil
@@ -270,11 +344,9 @@ func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err
 
 // WriteFile implements Storager.WriteFile
 func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Write [%s]: %w"
-
 	opt, err := parseStoragePairWrite(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 
 	var f io.WriteCloser
@@ -285,24 +357,100 @@ func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err err
 		// Create dir for path.
 		err = s.createDir(path)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			return types.NewError("Write", s, path, pairs, err)
 		}
 
 		rp := s.getAbsPath(path)
 
+		if hasPreconditions(opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince) {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+
+			fi, err := s.statForPreconditions(rp)
+			if err != nil {
+				return types.NewError("Write", s, path, pairs, handleOsError(err))
+			}
+			err = checkPreconditions(fi, opt.IfMatch, opt.IfNoneMatch, opt.IfModifiedSince, opt.IfUnmodifiedSince)
+			if err != nil {
+				return types.NewError("Write", s, path, pairs, err)
+			}
+		}
+
 		f, err = s.osCreate(rp)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, handleOsError(err))
+			return types.NewError("Write", s, path, pairs, handleOsError(err))
 		}
 	}
 
+	r = iowrap.ContextReader(opt.Context, r)
 	if opt.HasSize {
 		_, err = s.ioCopyN(f, r, opt.Size)
 	} else {
 		_, err = s.ioCopyBuffer(f, r, make([]byte, 1024*1024))
 	}
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, handleOsError(err))
+		return types.NewError("Write", s, path, pairs, handleOsError(err))
 	}
 	return
 }
+
+// InitAppend implements Storager.InitAppend
+func (s *Storage) InitAppend(path string, pairs ...*types.Pair) (offset int64, err error) {
+	_, err = parseStoragePairInitAppend(pairs...)
+	if err != nil {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+
+	err = s.createDir(path)
+	if err != nil {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	f, err := s.osOpenFile(rp, os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return 0, types.NewError("InitAppend", s, path, pairs, handleOsError(err))
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, types.NewError("InitAppend", s, path, pairs, handleOsError(err))
+	}
+	return fi.Size(), nil
+}
+
+// WriteAppend implements Storager.WriteAppend
+func (s *Storage) WriteAppend(path string, offset int64, r io.Reader, pairs ...*types.Pair) (next int64, err error) {
+	opt, err := parseStoragePairWriteAppend(pairs...)
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	f, err := s.osOpenFile(rp, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, handleOsError(err))
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, handleOsError(err))
+	}
+	if fi.Size() != offset {
+		err = fmt.Errorf("%w: offset %d doesn't match file size %d", types.ErrPreconditionFailed, offset, fi.Size())
+		return 0, types.NewError("WriteAppend", s, path, pairs, err)
+	}
+
+	n, err := s.ioCopyBuffer(f, iowrap.ContextReader(opt.Context, r), make([]byte, 1024*1024))
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, handleOsError(err))
+	}
+	return offset + n, nil
+}
@@ -1,11 +1,14 @@
 package fs
 
 import (
+	"context"
 	"errors"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -258,6 +261,49 @@ func TestStorage_Delete(t *testing.T) {
 	}
 }
 
+func TestStorage_DeleteAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.MkdirAll(filepath.Join(dir, "a", "b"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "a", "b", "c"), []byte("content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := New()
+	err = client.Init(pairs.WithWorkDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.Delete("a")
+	assert.True(t, errors.Is(err, types.ErrDirNotEmpty))
+
+	err = client.DeleteAll("a")
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "a"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Delete a not exist path should be ok.
+	err = client.DeleteAll("a")
+	assert.NoError(t, err)
+
+	// Delete the work dir should be rejected.
+	for _, v := range []string{"", "/"} {
+		err = client.DeleteAll(v)
+		assert.True(t, errors.Is(err, types.ErrRootNotAllowed))
+	}
+	_, err = os.Stat(dir)
+	assert.NoError(t, err)
+}
+
 func TestStorage_Copy(t *testing.T) {
 	t.Run("Failed at open source file", func(t *testing.T) {
 		srcName := uuid.New().String()
@@ -534,6 +580,28 @@ func TestStorage_List(t *testing.T) {
 	}
 }
 
+func TestStorage_ListWithCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := Storage{
+		ioutilReadDir: func(dirname string) (infos []os.FileInfo, e error) {
+			return []os.FileInfo{
+				fileInfo{name: "a", size: 1, mode: 0644},
+				fileInfo{name: "b", size: 1, mode: 0644},
+			}, nil
+		},
+	}
+
+	count := 0
+	err := client.List("test", pairs.WithContext(ctx), pairs.WithFileFunc(func(object *types.Object) {
+		count++
+		cancel()
+	}))
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 1, count)
+}
+
 func TestStorage_Read(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -743,3 +811,94 @@ func TestStorage_Write(t *testing.T) {
 		})
 	}
 }
+
+func TestStorage_WriteWithCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := New()
+	path := filepath.Join(os.TempDir(), uuid.New().String())
+	defer os.Remove(path)
+
+	err := client.Write(path, strings.NewReader("content"), pairs.WithContext(ctx))
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestStorage_Preconditions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := New()
+	err = client.Init(pairs.WithWorkDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the first write with if_none_match "*" should succeed.
+	err = client.Write("state", strings.NewReader("first"), pairs.WithIfNoneMatch("*"))
+	assert.NoError(t, err)
+	err = client.Write("state", strings.NewReader("second"), pairs.WithIfNoneMatch("*"))
+	assert.True(t, errors.Is(err, types.ErrPreconditionFailed))
+
+	o, err := client.Stat("state")
+	assert.NoError(t, err)
+
+	_, err = client.Stat("state", pairs.WithIfModifiedSince(o.UpdatedAt))
+	assert.True(t, errors.Is(err, types.ErrPreconditionFailed))
+
+	r, err := client.Read("state", pairs.WithIfUnmodifiedSince(o.UpdatedAt))
+	assert.NoError(t, err)
+	content, _ := ioutil.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "first", string(content))
+
+	_, err = client.Read("state", pairs.WithIfUnmodifiedSince(o.UpdatedAt.Add(-time.Hour)))
+	assert.True(t, errors.Is(err, types.ErrPreconditionFailed))
+
+	// fs doesn't have ETag, so concrete etag can't be evaluated.
+	err = client.Delete("state", pairs.WithIfMatch("etag"))
+	assert.True(t, errors.Is(err, types.ErrNotSupported))
+	err = client.Delete("state", pairs.WithIfUnmodifiedSince(o.UpdatedAt))
+	assert.NoError(t, err)
+}
+
+func TestStorage_Append(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := New()
+	err = client.Init(pairs.WithWorkDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offset, err := client.InitAppend("dir/log")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), offset)
+
+	offset, err = client.WriteAppend("dir/log", offset, strings.NewReader("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), offset)
+
+	// Resuming should return the current size.
+	offset, err = client.InitAppend("dir/log")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), offset)
+
+	_, err = client.WriteAppend("dir/log", 0, strings.NewReader("world"))
+	assert.True(t, errors.Is(err, types.ErrPreconditionFailed))
+
+	offset, err = client.WriteAppend("dir/log", offset, strings.NewReader(", world"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), offset)
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "dir/log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello, world", string(content))
+}
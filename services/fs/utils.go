@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/Xuanwo/storage/types"
+	ps "github.com/Xuanwo/storage/types/pairs"
 )
 
 func (s *Storage) createDir(path string) (err error) {
@@ -36,15 +39,76 @@ func (s *Storage) getDirPath(path string) string {
 	return filepath.Join(s.workDir, filepath.Dir(path))
 }
 
+// hasPreconditions will check whether any precondition is set.
+func hasPreconditions(ifMatch, ifNoneMatch string, ifModifiedSince, ifUnmodifiedSince time.Time) bool {
+	return ifMatch != "" || ifNoneMatch != "" || !ifModifiedSince.IsZero() || !ifUnmodifiedSince.IsZero()
+}
+
+// checkPreconditions will check preconditions against fi, fi should be nil if file doesn't exist.
+//
+// fs doesn't have ETag, so if_match and if_none_match only accept "*", other values can't be evaluated
+// and will return ErrNotSupported. Time based preconditions are ignored if file doesn't exist.
+func checkPreconditions(fi os.FileInfo, ifMatch, ifNoneMatch string, ifModifiedSince, ifUnmodifiedSince time.Time) error {
+	if ifMatch != "" && ifMatch != "*" {
+		return types.NewErrPairNotSupported(ps.IfMatch)
+	}
+	if ifNoneMatch != "" && ifNoneMatch != "*" {
+		return types.NewErrPairNotSupported(ps.IfNoneMatch)
+	}
+	if ifMatch == "*" && fi == nil {
+		return fmt.Errorf("%w: if_match not met", types.ErrPreconditionFailed)
+	}
+	if fi == nil {
+		return nil
+	}
+	if ifNoneMatch == "*" {
+		return fmt.Errorf("%w: if_none_match not met", types.ErrPreconditionFailed)
+	}
+	if !ifModifiedSince.IsZero() && !fi.ModTime().After(ifModifiedSince) {
+		return fmt.Errorf("%w: if_modified_since not met", types.ErrPreconditionFailed)
+	}
+	if !ifUnmodifiedSince.IsZero() && fi.ModTime().After(ifUnmodifiedSince) {
+		return fmt.Errorf("%w: if_unmodified_since not met", types.ErrPreconditionFailed)
+	}
+	return nil
+}
+
+// statForPreconditions will stat rp for checkPreconditions, nil will be returned if rp doesn't exist.
+func (s *Storage) statForPreconditions(rp string) (os.FileInfo, error) {
+	fi, err := s.osStat(rp)
+	if err != nil && os.IsNotExist(err) {
+		return nil, nil
+	}
+	return fi, err
+}
+
 func handleOsError(err error) error {
 	if err == nil {
 		panic("error must not be nil")
 	}
 
+	// Context errors will be returned as is, so caller could check them via errors.Is.
+	if types.IsContextError(err) {
+		return err
+	}
+
 	// Add two conditions in case of os.IsNotExist not work with fmt.Errorf
-	if errors.Is(err, os.ErrNotExist) || os.IsNotExist(err) {
+	switch {
+	case errors.Is(err, os.ErrNotExist) || os.IsNotExist(err):
 		return fmt.Errorf("%w: %v", types.ErrObjectNotExist, err)
+	// ENOTEMPTY is also treated as os.ErrExist, so we must check it first.
+	case errors.Is(err, syscall.ENOTEMPTY):
+		return fmt.Errorf("%w: %v", types.ErrDirNotEmpty, err)
+	case errors.Is(err, os.ErrExist) || os.IsExist(err):
+		return fmt.Errorf("%w: %v", types.ErrObjectAlreadyExist, err)
+	case errors.Is(err, os.ErrPermission) || os.IsPermission(err):
+		return fmt.Errorf("%w: %v", types.ErrPermissionDenied, err)
+	case errors.Is(err, syscall.ENOSPC), errors.Is(err, syscall.EDQUOT):
+		return fmt.Errorf("%w: %v", types.ErrQuotaExceeded, err)
+	case errors.Is(err, syscall.ENOTSUP):
+		return fmt.Errorf("%w: %v", types.ErrNotSupported, err)
+	case types.IsTimeout(err):
+		return fmt.Errorf("%w: %v", types.ErrTimeout, err)
 	}
-	// TODO: handle other osError here.
 	return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
 }
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/Xuanwo/storage/types/pairs"
 	"github.com/google/uuid"
@@ -97,6 +99,26 @@ func TestHandleOsError(t *testing.T) {
 				fmt.Errorf("%w: some other infos", os.ErrNotExist),
 				types.ErrObjectNotExist,
 			},
+			{
+				"exist",
+				&os.PathError{Op: "mkdir", Path: "abc", Err: syscall.EEXIST},
+				types.ErrObjectAlreadyExist,
+			},
+			{
+				"permission denied",
+				&os.PathError{Op: "open", Path: "abc", Err: syscall.EACCES},
+				types.ErrPermissionDenied,
+			},
+			{
+				"dir not empty",
+				&os.PathError{Op: "remove", Path: "abc", Err: syscall.ENOTEMPTY},
+				types.ErrDirNotEmpty,
+			},
+			{
+				"no space",
+				&os.PathError{Op: "write", Path: "abc", Err: syscall.ENOSPC},
+				types.ErrQuotaExceeded,
+			},
 			{
 				"other errors",
 				errors.New("expect unhandled error"),
@@ -111,3 +133,44 @@ func TestHandleOsError(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckPreconditions(t *testing.T) {
+	now := time.Now()
+	fi := fileInfo{modTime: now}
+
+	cases := []struct {
+		name              string
+		fi                os.FileInfo
+		ifMatch           string
+		ifNoneMatch       string
+		ifModifiedSince   time.Time
+		ifUnmodifiedSince time.Time
+		expected          error
+	}{
+		{"no preconditions", fi, "", "", time.Time{}, time.Time{}, nil},
+		{"if_match any", fi, "*", "", time.Time{}, time.Time{}, nil},
+		{"if_match any not exist", nil, "*", "", time.Time{}, time.Time{}, types.ErrPreconditionFailed},
+		{"if_match etag", fi, "abc", "", time.Time{}, time.Time{}, types.ErrNotSupported},
+		{"if_match etag not exist", nil, "abc", "", time.Time{}, time.Time{}, types.ErrNotSupported},
+		{"if_none_match any", fi, "", "*", time.Time{}, time.Time{}, types.ErrPreconditionFailed},
+		{"if_none_match any not exist", nil, "", "*", time.Time{}, time.Time{}, nil},
+		{"if_none_match etag", fi, "", "abc", time.Time{}, time.Time{}, types.ErrNotSupported},
+		{"if_none_match etag not exist", nil, "", "abc", time.Time{}, time.Time{}, types.ErrNotSupported},
+		{"if_modified_since met", fi, "", "", now.Add(-time.Hour), time.Time{}, nil},
+		{"if_modified_since not met", fi, "", "", now, time.Time{}, types.ErrPreconditionFailed},
+		{"if_unmodified_since met", fi, "", "", time.Time{}, now, nil},
+		{"if_unmodified_since not met", fi, "", "", time.Time{}, now.Add(-time.Hour), types.ErrPreconditionFailed},
+		{"time not exist", nil, "", "", now, now, nil},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPreconditions(tt.fi, tt.ifMatch, tt.ifNoneMatch, tt.ifModifiedSince, tt.ifUnmodifiedSince)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tt.expected))
+		})
+	}
+}
@@ -4,12 +4,14 @@ package gcs
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 
 	"github.com/Xuanwo/storage"
 	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/pkg/iterator"
 	"github.com/Xuanwo/storage/pkg/segment"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
@@ -19,9 +21,11 @@ import (
 
 var _ credential.Provider
 var _ endpoint.Provider
+var _ iterator.ObjectIterator
 var _ segment.Segment
 var _ storage.Storager
 var _ storageclass.Type
+var _ time.Duration
 
 // Type is the type for gcs
 const Type = "gcs"
@@ -31,6 +35,8 @@ type pairServiceCreate struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasVersioning bool
+	Versioning    bool
 }
 
 func parseServicePairCreate(opts ...*types.Pair) (*pairServiceCreate, error) {
@@ -53,6 +59,11 @@ func parseServicePairCreate(opts ...*types.Pair) (*pairServiceCreate, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.Versioning]
+	if ok {
+		result.HasVersioning = true
+		result.Versioning = v.(bool)
+	}
 	return result, nil
 }
 
@@ -162,7 +173,7 @@ type pairServiceNew struct {
 
 	// Meta-defined pairs
 	HasCredential bool
-	Credential    *credential.Provider
+	Credential    credential.Provider
 	HasProject    bool
 	Project       string
 }
@@ -193,7 +204,7 @@ func parseServicePairNew(opts ...*types.Pair) (*pairServiceNew, error) {
 	}
 	if ok {
 		result.HasCredential = true
-		result.Credential = v.(*credential.Provider)
+		result.Credential = v.(credential.Provider)
 	}
 	v, ok = values[ps.Project]
 	if !ok {
@@ -211,6 +222,16 @@ type pairStorageDelete struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasVersionID         bool
+	VersionID            string
 }
 
 func parseStoragePairDelete(opts ...*types.Pair) (*pairStorageDelete, error) {
@@ -232,6 +253,181 @@ func parseStoragePairDelete(opts ...*types.Pair) (*pairStorageDelete, error) {
 		result.Context = context.Background()
 	}
 
+	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.VersionID]
+	if ok {
+		result.HasVersionID = true
+		result.VersionID = v.(string)
+	}
+	return result, nil
+}
+
+type pairStorageDeleteBatch struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairDeleteBatch(opts ...*types.Pair) (*pairStorageDeleteBatch, error) {
+	result := &pairStorageDeleteBatch{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageDeleteLifecycle struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairDeleteLifecycle(opts ...*types.Pair) (*pairStorageDeleteLifecycle, error) {
+	result := &pairStorageDeleteLifecycle{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageDeleteTags struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairDeleteTags(opts ...*types.Pair) (*pairStorageDeleteTags, error) {
+	result := &pairStorageDeleteTags{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageGetLifecycle struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairGetLifecycle(opts ...*types.Pair) (*pairStorageGetLifecycle, error) {
+	result := &pairStorageGetLifecycle{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageGetTags struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairGetTags(opts ...*types.Pair) (*pairStorageGetTags, error) {
+	result := &pairStorageGetTags{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
 	// Parse meta-defined pairs
 	return result, nil
 }
@@ -241,8 +437,10 @@ type pairStorageInit struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasWorkDir bool
-	WorkDir    string
+	HasStorageClass bool
+	StorageClass    storageclass.Type
+	HasWorkDir      bool
+	WorkDir         string
 }
 
 func parseStoragePairInit(opts ...*types.Pair) (*pairStorageInit, error) {
@@ -265,6 +463,11 @@ func parseStoragePairInit(opts ...*types.Pair) (*pairStorageInit, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.StorageClass]
+	if ok {
+		result.HasStorageClass = true
+		result.StorageClass = v.(storageclass.Type)
+	}
 	v, ok = values[ps.WorkDir]
 	if ok {
 		result.HasWorkDir = true
@@ -313,6 +516,46 @@ func parseStoragePairList(opts ...*types.Pair) (*pairStorageList, error) {
 	return result, nil
 }
 
+type pairStorageListVersions struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+	HasFileFunc bool
+	FileFunc    types.ObjectFunc
+}
+
+func parseStoragePairListVersions(opts ...*types.Pair) (*pairStorageListVersions, error) {
+	result := &pairStorageListVersions{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	v, ok = values[ps.FileFunc]
+	if !ok {
+		return nil, types.NewErrPairRequired(ps.FileFunc)
+	}
+	if ok {
+		result.HasFileFunc = true
+		result.FileFunc = v.(types.ObjectFunc)
+	}
+	return result, nil
+}
+
 type pairStorageMetadata struct {
 	// Pre-defined pairs
 	Context context.Context
@@ -348,6 +591,18 @@ type pairStorageRead struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasVerifyChecksum    bool
+	VerifyChecksum       bool
+	HasVersionID         bool
+	VersionID            string
 }
 
 func parseStoragePairRead(opts ...*types.Pair) (*pairStorageRead, error) {
@@ -369,6 +624,96 @@ func parseStoragePairRead(opts ...*types.Pair) (*pairStorageRead, error) {
 		result.Context = context.Background()
 	}
 
+	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.VerifyChecksum]
+	if ok {
+		result.HasVerifyChecksum = true
+		result.VerifyChecksum = v.(bool)
+	}
+	v, ok = values[ps.VersionID]
+	if ok {
+		result.HasVersionID = true
+		result.VersionID = v.(string)
+	}
+	return result, nil
+}
+
+type pairStorageSetLifecycle struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairSetLifecycle(opts ...*types.Pair) (*pairStorageSetLifecycle, error) {
+	result := &pairStorageSetLifecycle{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
+	// Parse meta-defined pairs
+	return result, nil
+}
+
+type pairStorageSetTags struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairSetTags(opts ...*types.Pair) (*pairStorageSetTags, error) {
+	result := &pairStorageSetTags{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
 	// Parse meta-defined pairs
 	return result, nil
 }
@@ -378,6 +723,16 @@ type pairStorageStat struct {
 	Context context.Context
 
 	// Meta-defined pairs
+	HasIfMatch           bool
+	IfMatch              string
+	HasIfModifiedSince   bool
+	IfModifiedSince      time.Time
+	HasIfNoneMatch       bool
+	IfNoneMatch          string
+	HasIfUnmodifiedSince bool
+	IfUnmodifiedSince    time.Time
+	HasVersionID         bool
+	VersionID            string
 }
 
 func parseStoragePairStat(opts ...*types.Pair) (*pairStorageStat, error) {
@@ -399,6 +754,61 @@ func parseStoragePairStat(opts ...*types.Pair) (*pairStorageStat, error) {
 		result.Context = context.Background()
 	}
 
+	// Parse meta-defined pairs
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.VersionID]
+	if ok {
+		result.HasVersionID = true
+		result.VersionID = v.(string)
+	}
+	return result, nil
+}
+
+type pairStorageTransit struct {
+	// Pre-defined pairs
+	Context context.Context
+
+	// Meta-defined pairs
+}
+
+func parseStoragePairTransit(opts ...*types.Pair) (*pairStorageTransit, error) {
+	result := &pairStorageTransit{}
+
+	values := make(map[string]interface{})
+	for _, v := range opts {
+		values[v.Key] = v.Value
+	}
+
+	var v interface{}
+	var ok bool
+
+	// Parse pre-defined pairs
+	v, ok = values[ps.Context]
+	if ok {
+		result.Context = v.(context.Context)
+	} else {
+		result.Context = context.Background()
+	}
+
 	// Parse meta-defined pairs
 	return result, nil
 }
@@ -408,12 +818,32 @@ type pairStorageWrite struct {
 	Context context.Context
 
 	// Meta-defined pairs
-	HasChecksum     bool
-	Checksum        string
-	HasSize         bool
-	Size            int64
-	HasStorageClass bool
-	StorageClass    storageclass.Type
+	HasCacheControl       bool
+	CacheControl          string
+	HasChecksum           bool
+	Checksum              string
+	HasContentDisposition bool
+	ContentDisposition    string
+	HasContentEncoding    bool
+	ContentEncoding       string
+	HasContentType        bool
+	ContentType           string
+	HasIfMatch            bool
+	IfMatch               string
+	HasIfModifiedSince    bool
+	IfModifiedSince       time.Time
+	HasIfNoneMatch        bool
+	IfNoneMatch           string
+	HasIfUnmodifiedSince  bool
+	IfUnmodifiedSince     time.Time
+	HasSize               bool
+	Size                  int64
+	HasStorageClass       bool
+	StorageClass          storageclass.Type
+	HasTags               bool
+	Tags                  map[string]string
+	HasUserMetadata       bool
+	UserMetadata          map[string]string
 }
 
 func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
@@ -436,11 +866,51 @@ func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
 	}
 
 	// Parse meta-defined pairs
+	v, ok = values[ps.CacheControl]
+	if ok {
+		result.HasCacheControl = true
+		result.CacheControl = v.(string)
+	}
 	v, ok = values[ps.Checksum]
 	if ok {
 		result.HasChecksum = true
 		result.Checksum = v.(string)
 	}
+	v, ok = values[ps.ContentDisposition]
+	if ok {
+		result.HasContentDisposition = true
+		result.ContentDisposition = v.(string)
+	}
+	v, ok = values[ps.ContentEncoding]
+	if ok {
+		result.HasContentEncoding = true
+		result.ContentEncoding = v.(string)
+	}
+	v, ok = values[ps.ContentType]
+	if ok {
+		result.HasContentType = true
+		result.ContentType = v.(string)
+	}
+	v, ok = values[ps.IfMatch]
+	if ok {
+		result.HasIfMatch = true
+		result.IfMatch = v.(string)
+	}
+	v, ok = values[ps.IfModifiedSince]
+	if ok {
+		result.HasIfModifiedSince = true
+		result.IfModifiedSince = v.(time.Time)
+	}
+	v, ok = values[ps.IfNoneMatch]
+	if ok {
+		result.HasIfNoneMatch = true
+		result.IfNoneMatch = v.(string)
+	}
+	v, ok = values[ps.IfUnmodifiedSince]
+	if ok {
+		result.HasIfUnmodifiedSince = true
+		result.IfUnmodifiedSince = v.(time.Time)
+	}
 	v, ok = values[ps.Size]
 	if !ok {
 		return nil, types.NewErrPairRequired(ps.Size)
@@ -454,6 +924,16 @@ func parseStoragePairWrite(opts ...*types.Pair) (*pairStorageWrite, error) {
 		result.HasStorageClass = true
 		result.StorageClass = v.(storageclass.Type)
 	}
+	v, ok = values[ps.Tags]
+	if ok {
+		result.HasTags = true
+		result.Tags = v.(map[string]string)
+	}
+	v, ok = values[ps.UserMetadata]
+	if ok {
+		result.HasUserMetadata = true
+		result.UserMetadata = v.(map[string]string)
+	}
 	return result, nil
 }
 
@@ -502,6 +982,51 @@ func (s *Storage) DeleteWithContext(ctx context.Context, path string, pairs ...*
 	return s.Delete(path, pairs...)
 }
 
+// DeleteBatchWithContext adds context support for DeleteBatch.
+func (s *Storage) DeleteBatchWithContext(ctx context.Context, paths []string, pairs ...*types.Pair) (results []types.DeleteResult, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.DeleteBatch")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.DeleteBatch(paths, pairs...)
+}
+
+// DeleteLifecycleWithContext adds context support for DeleteLifecycle.
+func (s *Storage) DeleteLifecycleWithContext(ctx context.Context, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.DeleteLifecycle")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.DeleteLifecycle(pairs...)
+}
+
+// DeleteTagsWithContext adds context support for DeleteTags.
+func (s *Storage) DeleteTagsWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.DeleteTags")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.DeleteTags(path, pairs...)
+}
+
+// GetLifecycleWithContext adds context support for GetLifecycle.
+func (s *Storage) GetLifecycleWithContext(ctx context.Context, pairs ...*types.Pair) (rules []types.LifecycleRule, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.GetLifecycle")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.GetLifecycle(pairs...)
+}
+
+// GetTagsWithContext adds context support for GetTags.
+func (s *Storage) GetTagsWithContext(ctx context.Context, path string, pairs ...*types.Pair) (tags map[string]string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.GetTags")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.GetTags(path, pairs...)
+}
+
 // InitWithContext adds context support for Init.
 func (s *Storage) InitWithContext(ctx context.Context, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.Init")
@@ -520,6 +1045,15 @@ func (s *Storage) ListWithContext(ctx context.Context, path string, pairs ...*ty
 	return s.List(path, pairs...)
 }
 
+// ListVersionsWithContext adds context support for ListVersions.
+func (s *Storage) ListVersionsWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.ListVersions")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.ListVersions(path, pairs...)
+}
+
 // MetadataWithContext adds context support for Metadata.
 func (s *Storage) MetadataWithContext(ctx context.Context, pairs ...*types.Pair) (m metadata.StorageMeta, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.Metadata")
@@ -538,6 +1072,24 @@ func (s *Storage) ReadWithContext(ctx context.Context, path string, pairs ...*ty
 	return s.Read(path, pairs...)
 }
 
+// SetLifecycleWithContext adds context support for SetLifecycle.
+func (s *Storage) SetLifecycleWithContext(ctx context.Context, rules []types.LifecycleRule, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.SetLifecycle")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.SetLifecycle(rules, pairs...)
+}
+
+// SetTagsWithContext adds context support for SetTags.
+func (s *Storage) SetTagsWithContext(ctx context.Context, path string, tags map[string]string, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.SetTags")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.SetTags(path, tags, pairs...)
+}
+
 // StatWithContext adds context support for Stat.
 func (s *Storage) StatWithContext(ctx context.Context, path string, pairs ...*types.Pair) (o *types.Object, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.Stat")
@@ -547,6 +1099,15 @@ func (s *Storage) StatWithContext(ctx context.Context, path string, pairs ...*ty
 	return s.Stat(path, pairs...)
 }
 
+// TransitWithContext adds context support for Transit.
+func (s *Storage) TransitWithContext(ctx context.Context, path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.Transit")
+	defer span.Finish()
+
+	pairs = append(pairs, ps.WithContext(ctx))
+	return s.Transit(path, storageClass, pairs...)
+}
+
 // WriteWithContext adds context support for Write.
 func (s *Storage) WriteWithContext(ctx context.Context, path string, r io.Reader, pairs ...*types.Pair) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "github.com/Xuanwo/storage/services/gcs.storage.Write")
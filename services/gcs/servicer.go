@@ -3,10 +3,13 @@ package gcs
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	gs "cloud.google.com/go/storage"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
+	gsv1 "google.golang.org/api/storage/v1"
+	htransport "google.golang.org/api/transport/http"
 
 	"github.com/Xuanwo/storage"
 	"github.com/Xuanwo/storage/pkg/credential"
@@ -16,41 +19,65 @@ import (
 // Service is the gcs config.
 type Service struct {
 	service   *gs.Client
+	raw       *gsv1.Service
+	client    *http.Client
 	projectID string
 }
 
-// New will create a new aliyun oss service.
+// New will create a new gcs service.
+//
+// Credential will only be retrieved once, access tokens of service account file are refreshed by
+// google SDK itself, and api key never expires.
 func New(pairs ...*types.Pair) (s *Service, err error) {
-	const errorMessage = "%s New: %w"
-
 	s = &Service{}
 
 	opt, err := parseServicePairNew(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
 	ctx := context.Background()
 
 	options := make([]option.ClientOption, 0)
 
-	credProtocol, cred := opt.Credential.Protocol(), opt.Credential.Value()
-	switch credProtocol {
+	cred, err := opt.Credential.Retrieve()
+	if err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	switch cred.Protocol {
 	case credential.ProtocolAPIKey:
-		options = append(options, option.WithAPIKey(cred[0]))
+		options = append(options, option.WithAPIKey(cred.Args[0]))
 	case credential.ProtocolFile:
-		options = append(options, option.WithCredentialsFile(cred[0]))
+		// Google service account json file doesn't have profiles.
+		path, profile := cred.File()
+		if profile != "" {
+			return nil, types.NewError("New", s, "", pairs, credential.ErrInvalidConfig)
+		}
+		options = append(options, option.WithCredentialsFile(path))
 	default:
-		return nil, fmt.Errorf(errorMessage, s, credential.ErrUnsupportedProtocol)
+		return nil, types.NewError("New", s, "", pairs, credential.ErrUnsupportedProtocol)
 	}
 
 	client, err := gs.NewClient(ctx, options...)
 
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+
+	// raw and httpClient share the same credential with client, and are used for requests which client can't express.
+	httpClient, _, err := htransport.NewClient(ctx,
+		append([]option.ClientOption{option.WithScopes(gs.ScopeFullControl)}, options...)...)
+	if err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	raw, err := gsv1.NewService(ctx, option.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
 	s.service = client
+	s.raw = raw
+	s.client = httpClient
 	s.projectID = opt.Project
 	return
 }
@@ -62,11 +89,9 @@ func (s *Service) String() string {
 
 // List implements Servicer.List
 func (s *Service) List(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List: %w"
-
 	opt, err := parseServicePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("List", s, "", pairs, err)
 	}
 
 	it := s.service.Buckets(opt.Context, s.projectID)
@@ -77,10 +102,11 @@ func (s *Service) List(pairs ...*types.Pair) (err error) {
 			return nil
 		}
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, err)
+			err = handleGcsError(err)
+			return types.NewError("List", s, "", pairs, err)
 		}
 		bucket := s.service.Bucket(bucketAttr.Name)
-		c := newStorage(bucket, bucketAttr.Name)
+		c := newStorage(bucket, s.raw, s.client, bucketAttr.Name)
 		opt.StoragerFunc(c)
 	}
 }
@@ -90,43 +116,47 @@ func (s *Service) Get(name string, pairs ...*types.Pair) (storage.Storager, erro
 	const _ = "%s Get [%s]: %w"
 
 	bucket := s.service.Bucket(name)
-	c := newStorage(bucket, name)
+	c := newStorage(bucket, s.raw, s.client, name)
 	return c, nil
 }
 
 // Create implements Servicer.Create
 func (s *Service) Create(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Create [%s]: %w"
-
 	opt, err := parseServicePairCreate(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 
 	bucket := s.service.Bucket(name)
 
-	err = bucket.Create(opt.Context, s.projectID, nil)
+	var attrs *gs.BucketAttrs
+	if opt.HasVersioning {
+		// New bucket is not versioned, which is the same as suspended in gcs.
+		attrs = &gs.BucketAttrs{VersioningEnabled: opt.Versioning}
+	}
+
+	err = bucket.Create(opt.Context, s.projectID, attrs)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		err = handleGcsError(err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
-	c := newStorage(bucket, name)
+	c := newStorage(bucket, s.raw, s.client, name)
 	return c, nil
 }
 
 // Delete implements Servicer.Delete
 func (s *Service) Delete(name string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
-
 	opt, err := parseServicePairDelete(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 
 	bucket := s.service.Bucket(name)
 
 	err = bucket.Delete(opt.Context)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		err = handleGcsError(err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 	return nil
 }
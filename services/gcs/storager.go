@@ -3,28 +3,47 @@ package gcs
 import (
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 
 	gs "cloud.google.com/go/storage"
+	"github.com/Xuanwo/storage/pkg/checksum"
+	"github.com/Xuanwo/storage/pkg/prefix"
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/pkg/tagging"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/metadata"
+	ps "github.com/Xuanwo/storage/types/pairs"
 	"google.golang.org/api/iterator"
+	gsv1 "google.golang.org/api/storage/v1"
 )
 
+// deleteBatchLimit is the max count of calls in one batch request.
+//
+// ref: https://cloud.google.com/storage/docs/json_api/v1/how-tos/batch
+const deleteBatchLimit = 100
+
 // Storage is the gcs service client.
 //
 //go:generate ../../internal/bin/service
 type Storage struct {
 	bucket *gs.BucketHandle
-
-	name    string
-	workDir string
+	// raw is the gcs JSON API service, used for updates which gcs SDK can't express.
+	raw *gsv1.Service
+	// client is the authorized http client of raw, used for JSON API requests which raw doesn't support.
+	client *http.Client
+
+	name         string
+	workDir      string
+	storageClass storageclass.Type
 }
 
 // newStorage will create a new client.
-func newStorage(bucket *gs.BucketHandle, name string) *Storage {
+func newStorage(bucket *gs.BucketHandle, raw *gsv1.Service, client *http.Client, name string) *Storage {
 	c := &Storage{
 		bucket: bucket,
+		raw:    raw,
+		client: client,
 		name:   name,
 	}
 	return c
@@ -40,17 +59,22 @@ func (s *Storage) String() string {
 
 // Init implements Storager.Init
 func (s *Storage) Init(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Init: %w"
-
 	opt, err := parseStoragePairInit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("Init", s, "", pairs, err)
 	}
 
 	if opt.HasWorkDir {
 		// TODO: we should validate workDir
 		s.workDir = strings.TrimLeft(opt.WorkDir, "/")
 	}
+	if opt.HasStorageClass {
+		// Storage class will be used as default for Write, validate it here to fail fast.
+		if _, err = parseStorageClass(opt.StorageClass); err != nil {
+			return types.NewError("Init", s, "", pairs, err)
+		}
+		s.storageClass = opt.StorageClass
+	}
 
 	return nil
 }
@@ -60,16 +84,15 @@ func (s *Storage) Metadata(pairs ...*types.Pair) (m metadata.StorageMeta, err er
 	m = metadata.NewStorageMeta()
 	m.Name = s.name
 	m.WorkDir = s.workDir
+	m.SetFlatList(true)
 	return m, nil
 }
 
 // List implements Storager.List
 func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List [%s]: %w"
-
 	opt, err := parseStoragePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("List", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
@@ -83,7 +106,8 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 			return nil
 		}
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			err = handleGcsError(err)
+			return types.NewError("List", s, path, pairs, err)
 		}
 
 		o := &types.Object{
@@ -95,11 +119,14 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 			ObjectMeta: metadata.NewObjectMeta(),
 		}
 		o.SetContentType(object.ContentType)
-		o.SetContentMD5(string(object.MD5))
+		setObjectChecksum(o, object)
+		if meta := formatUserMetadata(object.Metadata); len(meta) > 0 {
+			o.SetUserMetadata(meta)
+		}
 
 		storageClass, err := formatStorageClass(object.StorageClass)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			return types.NewError("List", s, path, pairs, err)
 		}
 		o.SetStorageClass(storageClass)
 
@@ -109,37 +136,74 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 
 // Read implements Storager.Read
 func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err error) {
-	const errorMessage = "%s Read [%s]: %w"
-
 	opt, err := parseStoragePairRead(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+	if opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return nil, types.NewError("Read", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
-	object := s.bucket.Object(rp)
+	object, err := s.getObject(rp, opt.VersionID)
+	if err != nil {
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+	object, err = withConditions(object, opt.HasIfMatch, opt.IfMatch, opt.HasIfNoneMatch, opt.IfNoneMatch)
+	if err != nil {
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
 	r, err = object.NewReader(opt.Context)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleGcsError(err)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+
+	if opt.HasVerifyChecksum && opt.VerifyChecksum {
+		// gcs reader doesn't carry object's checksum, we need to get them via attrs.
+		attr, err := object.Attrs(opt.Context)
+		if err != nil {
+			r.Close()
+			err = handleGcsError(err)
+			return nil, types.NewError("Read", s, path, pairs, err)
+		}
+		o := &types.Object{ObjectMeta: metadata.NewObjectMeta()}
+		setObjectChecksum(o, attr)
+
+		vr, err := checksum.NewVerifyReadCloser(r, o.ObjectMeta)
+		if err != nil {
+			r.Close()
+			return nil, types.NewError("Read", s, path, pairs, err)
+		}
+		r = vr
 	}
 	return
 }
 
 // Write implements Storager.Write
 func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Write [%s]: %w"
-
 	opt, err := parseStoragePairWrite(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	if !opt.HasStorageClass && s.storageClass != "" {
+		// Storage class set in Init will be used as default.
+		opt.HasStorageClass, opt.StorageClass = true, s.storageClass
+	}
+	if opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
-	object := s.bucket.Object(rp)
+	object, err := withConditions(s.bucket.Object(rp), opt.HasIfMatch, opt.IfMatch, opt.HasIfNoneMatch, opt.IfNoneMatch)
+	if err != nil {
+		return types.NewError("Write", s, path, pairs, err)
+	}
 	w := object.NewWriter(opt.Context)
-	defer w.Close()
 
 	if opt.HasChecksum {
 		w.MD5 = []byte(opt.Checksum)
@@ -150,32 +214,74 @@ func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err err
 	if opt.HasStorageClass {
 		storageClass, err := parseStorageClass(opt.StorageClass)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			return types.NewError("Write", s, path, pairs, err)
 		}
 		w.StorageClass = storageClass
 	}
+	if opt.HasUserMetadata || opt.HasTags {
+		// Copy user metadata to prevent caller's map being modified by tags.
+		w.Metadata = make(map[string]string, len(opt.UserMetadata)+1)
+		for k, v := range opt.UserMetadata {
+			w.Metadata[k] = v
+		}
+		if opt.HasTags {
+			w.Metadata[tagsMetadataKey] = tagging.Encode(opt.Tags)
+		}
+	}
+	if opt.HasContentType {
+		w.ContentType = opt.ContentType
+	}
+	if opt.HasContentEncoding {
+		w.ContentEncoding = opt.ContentEncoding
+	}
+	if opt.HasContentDisposition {
+		w.ContentDisposition = opt.ContentDisposition
+	}
+	if opt.HasCacheControl {
+		w.CacheControl = opt.CacheControl
+	}
 
 	_, err = io.Copy(w, r)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		// Abort upload to prevent uncompleted object being created.
+		_ = w.CloseWithError(err)
+		err = handleGcsError(err)
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	// Object will be created while closing, upload error will be returned here.
+	err = w.Close()
+	if err != nil {
+		err = handleGcsError(err)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 	return nil
 }
 
 // Stat implements Storager.Stat
 func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err error) {
-	const errorMessage = "%s Stat [%s]: %w"
-
 	opt, err := parseStoragePairStat(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	if opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
-	attr, err := s.bucket.Object(rp).Attrs(opt.Context)
+	object, err := s.getObject(rp, opt.VersionID)
+	if err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	object, err = withConditions(object, opt.HasIfMatch, opt.IfMatch, opt.HasIfNoneMatch, opt.IfNoneMatch)
+	if err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	attr, err := object.Attrs(opt.Context)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleGcsError(err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	o = &types.Object{
@@ -186,30 +292,311 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 		UpdatedAt:  attr.Updated,
 		ObjectMeta: metadata.NewObjectMeta(),
 	}
+	o.SetVersionID(formatVersionID(attr.Generation))
+	setObjectChecksum(o, attr)
+	setObjectHeaders(o, attr)
+	if meta := formatUserMetadata(attr.Metadata); len(meta) > 0 {
+		o.SetUserMetadata(meta)
+	}
 
 	storageClass, err := formatStorageClass(attr.StorageClass)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 	o.SetStorageClass(storageClass)
 
 	return o, nil
 }
 
+// ListVersions implements Storager.ListVersions
+func (s *Storage) ListVersions(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairListVersions(pairs...)
+	if err != nil {
+		return types.NewError("ListVersions", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	objects := make([]*types.Object, 0)
+	it := s.bucket.Objects(opt.Context, &gs.Query{
+		Prefix:   rp,
+		Versions: true,
+	})
+	for {
+		attr, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			err = handleGcsError(err)
+			return types.NewError("ListVersions", s, path, pairs, err)
+		}
+		// Objects are sorted by name, so generations of rp always come before other objects under prefix rp.
+		if attr.Name != rp {
+			break
+		}
+
+		o := &types.Object{
+			ID:         attr.Name,
+			Name:       path,
+			Type:       types.ObjectTypeFile,
+			Size:       attr.Size,
+			UpdatedAt:  attr.Updated,
+			ObjectMeta: metadata.NewObjectMeta(),
+		}
+		o.SetVersionID(formatVersionID(attr.Generation))
+		setObjectChecksum(o, attr)
+
+		storageClass, err := formatStorageClass(attr.StorageClass)
+		if err != nil {
+			return types.NewError("ListVersions", s, path, pairs, err)
+		}
+		o.SetStorageClass(storageClass)
+
+		objects = append(objects, o)
+	}
+
+	// gcs returns generations from the oldest to the newest.
+	for i := len(objects) - 1; i >= 0; i-- {
+		opt.FileFunc(objects[i])
+	}
+	return nil
+}
+
+// GetTags implements Storager.GetTags
+func (s *Storage) GetTags(path string, pairs ...*types.Pair) (tags map[string]string, err error) {
+	opt, err := parseStoragePairGetTags(pairs...)
+	if err != nil {
+		return nil, types.NewError("GetTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	attr, err := s.bucket.Object(rp).Attrs(opt.Context)
+	if err != nil {
+		err = handleGcsError(err)
+		return nil, types.NewError("GetTags", s, path, pairs, err)
+	}
+
+	tags, err = formatTags(attr.Metadata)
+	if err != nil {
+		return nil, types.NewError("GetTags", s, path, pairs, err)
+	}
+	return tags, nil
+}
+
+// SetTags implements Storager.SetTags
+func (s *Storage) SetTags(path string, tags map[string]string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairSetTags(pairs...)
+	if err != nil {
+		return types.NewError("SetTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	// Metadata will be merged with existing ones, so other custom metadata will be kept.
+	_, err = s.bucket.Object(rp).Update(opt.Context, gs.ObjectAttrsToUpdate{
+		Metadata: map[string]string{tagsMetadataKey: tagging.Encode(tags)},
+	})
+	if err != nil {
+		err = handleGcsError(err)
+		return types.NewError("SetTags", s, path, pairs, err)
+	}
+	return nil
+}
+
+// DeleteTags implements Storager.DeleteTags
+func (s *Storage) DeleteTags(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteTags(pairs...)
+	if err != nil {
+		return types.NewError("DeleteTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	// Custom metadata can't be removed one by one, so an empty value is used instead.
+	_, err = s.bucket.Object(rp).Update(opt.Context, gs.ObjectAttrsToUpdate{
+		Metadata: map[string]string{tagsMetadataKey: ""},
+	})
+	if err != nil {
+		err = handleGcsError(err)
+		return types.NewError("DeleteTags", s, path, pairs, err)
+	}
+	return nil
+}
+
 // Delete implements Storager.Delete
 func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
+	opt, err := parseStoragePairDelete(pairs...)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
 
-	opt, err := parseStoragePairStat(pairs...)
+	rp := s.getAbsPath(path)
+
+	// Key ends with "/" is a dir, gcs will delete it even if there are keys under it.
+	if strings.HasSuffix(rp, "/") {
+		err = prefix.CheckDirEmpty(opt.Context, rp, s.listKeys)
+		if err != nil {
+			return types.NewError("Delete", s, path, pairs, err)
+		}
+	}
+
+	object, err := s.getObject(rp, opt.VersionID)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	object, err = withConditions(object, opt.HasIfMatch, opt.IfMatch, opt.HasIfNoneMatch, opt.IfNoneMatch)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	err = object.Delete(opt.Context)
+	if err != nil {
+		err = handleGcsError(err)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	return nil
+}
+
+// DeleteBatch implements Storager.DeleteBatch
+//
+// gcs doesn't check whether dir is empty in batch, so keys end with "/" will be deleted directly.
+func (s *Storage) DeleteBatch(paths []string, pairs ...*types.Pair) (results []types.DeleteResult, err error) {
+	opt, err := parseStoragePairDeleteBatch(pairs...)
+	if err != nil {
+		return nil, types.NewError("DeleteBatch", s, "", pairs, err)
+	}
+
+	results = make([]types.DeleteResult, len(paths))
+	for k, v := range paths {
+		results[k].Path = v
+	}
+
+	for start := 0; start < len(paths); start += deleteBatchLimit {
+		end := start + deleteBatchLimit
+		if end > len(paths) {
+			end = len(paths)
+		}
+
+		keys := make([]string, 0, end-start)
+		for i := start; i < end; i++ {
+			keys = append(keys, s.getAbsPath(paths[i]))
+		}
+
+		errs, err := s.deleteBatch(opt.Context, keys)
+		if err != nil {
+			err = handleGcsError(err)
+			if types.IsContextError(err) {
+				return nil, types.NewError("DeleteBatch", s, "", pairs, err)
+			}
+			for i := start; i < end; i++ {
+				results[i].Err = types.NewError("DeleteBatch", s, paths[i], pairs, err)
+			}
+			continue
+		}
+
+		for k, v := range errs {
+			if v != nil {
+				results[start+k].Err = types.NewError("DeleteBatch", s, paths[start+k], pairs, handleGcsError(v))
+			}
+		}
+	}
+	return results, nil
+}
+
+// GetLifecycle implements Storager.GetLifecycle
+func (s *Storage) GetLifecycle(pairs ...*types.Pair) (rules []types.LifecycleRule, err error) {
+	opt, err := parseStoragePairGetLifecycle(pairs...)
+	if err != nil {
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+
+	attrs, err := s.bucket.Attrs(opt.Context)
+	if err != nil {
+		err = handleGcsError(err)
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+
+	rules = make([]types.LifecycleRule, 0, len(attrs.Lifecycle.Rules))
+	for _, v := range attrs.Lifecycle.Rules {
+		rule, err := formatLifecycleRule(v)
+		if err != nil {
+			return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SetLifecycle implements Storager.SetLifecycle
+func (s *Storage) SetLifecycle(rules []types.LifecycleRule, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairSetLifecycle(pairs...)
+	if err != nil {
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+
+	if len(rules) == 0 {
+		return s.DeleteLifecycleWithContext(opt.Context, pairs...)
+	}
+
+	lifecycle, err := parseLifecycle(rules)
+	if err != nil {
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+
+	_, err = s.bucket.Update(opt.Context, gs.BucketAttrsToUpdate{Lifecycle: &lifecycle})
+	if err != nil {
+		err = handleGcsError(err)
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+	return nil
+}
+
+// DeleteLifecycle implements Storager.DeleteLifecycle
+//
+// gcs SDK will not send an empty lifecycle in update, so we patch the bucket with `"lifecycle": {}`
+// via JSON API directly.
+func (s *Storage) DeleteLifecycle(pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteLifecycle(pairs...)
+	if err != nil {
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+
+	_, err = s.raw.Buckets.Patch(s.name, &gsv1.Bucket{Lifecycle: &gsv1.BucketLifecycle{}}).
+		Context(opt.Context).Do()
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleGcsError(err)
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+	return nil
+}
+
+// Transit implements Storager.Transit
+func (s *Storage) Transit(path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairTransit(pairs...)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
-	err = s.bucket.Object(rp).Delete(opt.Context)
+	class, err := parseStorageClass(storageClass)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	// gcs can't change storage class directly, the object needs to be rewritten with the new class.
+	object := s.bucket.Object(rp)
+	copier := object.CopierFrom(object)
+	copier.StorageClass = class
+	_, err = copier.Run(opt.Context)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleGcsError(err)
+		return types.NewError("Transit", s, path, pairs, err)
 	}
 	return nil
 }
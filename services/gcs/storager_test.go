@@ -0,0 +1,158 @@
+package gcs
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	gsv1 "google.golang.org/api/storage/v1"
+
+	"github.com/Xuanwo/storage/types"
+)
+
+// newTestStorage will create a Storage which sends all JSON API requests to endpoint.
+func newTestStorage(t *testing.T, endpoint string) *Storage {
+	raw, err := gsv1.NewService(context.Background(),
+		option.WithEndpoint(endpoint+"/storage/v1/"), option.WithHTTPClient(http.DefaultClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newStorage(nil, raw, http.DefaultClient, "test")
+}
+
+func TestStorage_DeleteLifecycle(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		err    error
+	}{
+		{"delete", http.StatusOK, nil},
+		{"not exist", http.StatusNotFound, types.ErrObjectNotExist},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPatch, r.Method)
+				assert.Equal(t, "/storage/v1/b/test", r.URL.Path)
+
+				body, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{"lifecycle":{}}`, string(body))
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				if tt.status != http.StatusOK {
+					_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Not Found","errors":[{"reason":"notFound"}]}}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"name":"test"}`))
+			}))
+			defer server.Close()
+
+			s := newTestStorage(t, server.URL)
+
+			err := s.DeleteLifecycle()
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+
+			// SetLifecycle with no rules should delete lifecycle as well.
+			err = s.SetLifecycle(nil)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+		})
+	}
+}
+
+func TestStorage_DeleteBatch(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/batch/storage/v1", r.URL.Path)
+		requests++
+
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+
+		// Read the whole request before writing, the server may close the
+		// request body once the response is written.
+		ids, paths := make([]string, 0), make([]string, 0)
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			req, err := http.ReadRequest(bufio.NewReader(part))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, http.MethodDelete, req.Method)
+
+			ids = append(ids, part.Header.Get("Content-ID"))
+			paths = append(paths, req.URL.EscapedPath())
+		}
+		assert.True(t, len(ids) <= deleteBatchLimit)
+
+		mw := multipart.NewWriter(w)
+		w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+		for k, v := range ids {
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Type", "application/http")
+			header.Set("Content-ID", "response-"+v)
+			resp, err := mw.CreatePart(header)
+			assert.NoError(t, err)
+
+			if paths[k] == "/storage/v1/b/test/o/dir%2Fnot-exist" {
+				body := `{"error":{"code":404,"message":"Not Found","errors":[{"reason":"notFound"}]}}`
+				_, _ = fmt.Fprintf(resp, "HTTP/1.1 404 Not Found\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+				continue
+			}
+			_, _ = fmt.Fprint(resp, "HTTP/1.1 204 No Content\r\nContent-Length: 0\r\n\r\n")
+		}
+		assert.NoError(t, mw.Close())
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	paths := make([]string, 0, deleteBatchLimit+1)
+	for i := 0; i < deleteBatchLimit; i++ {
+		paths = append(paths, fmt.Sprintf("dir/%d", i))
+	}
+	paths = append(paths, "dir/not-exist")
+
+	results, err := s.DeleteBatch(paths)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, len(paths), len(results))
+	for k, v := range results {
+		assert.Equal(t, paths[k], v.Path)
+		if v.Path == "dir/not-exist" {
+			assert.True(t, errors.Is(v.Err, types.ErrObjectNotExist))
+			continue
+		}
+		assert.NoError(t, v.Err)
+	}
+}
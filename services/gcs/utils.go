@@ -1,9 +1,27 @@
 package gcs
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
 	"strings"
 
+	gs "cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+
+	"github.com/Xuanwo/storage/pkg/checksum"
 	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/pkg/tagging"
 	"github.com/Xuanwo/storage/types"
 )
 
@@ -15,6 +33,212 @@ func (s *Storage) getRelPath(path string) string {
 	return strings.TrimPrefix(path, s.workDir+"/")
 }
 
+// listKeys implements prefix.ListFunc
+func (s *Storage) listKeys(ctx context.Context, prefix, marker string, limit int) (keys []string, next string, err error) {
+	it := s.bucket.Objects(ctx, &gs.Query{
+		Prefix: prefix,
+	})
+
+	objects := make([]*gs.ObjectAttrs, 0, limit)
+	next, err = iterator.NewPager(it, limit, marker).NextPage(&objects)
+	if err != nil {
+		return nil, "", handleGcsError(err)
+	}
+
+	keys = make([]string, 0, len(objects))
+	for _, v := range objects {
+		keys = append(keys, v.Name)
+	}
+	return keys, next, nil
+}
+
+// deleteBatch will delete keys via gcs JSON API batch request, and return the error of every key in order.
+//
+// gcs SDK doesn't support batch request, so the multipart/mixed request will be sent via client directly.
+// keys should not exceed deleteBatchLimit.
+//
+// ref: https://cloud.google.com/storage/docs/json_api/v1/how-tos/batch
+func (s *Storage) deleteBatch(ctx context.Context, keys []string) ([]error, error) {
+	base, err := url.Parse(s.raw.BasePath)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for k, v := range keys {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", "application/http")
+		header.Set("Content-ID", strconv.Itoa(k))
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		_, err = fmt.Fprintf(part, "DELETE %sb/%s/o/%s HTTP/1.1\r\n\r\n",
+			base.Path, url.PathEscape(s.name), url.PathEscape(v))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	// Batch endpoint is "/batch/storage/v1" while base path is "/storage/v1/".
+	u := *base
+	u.Path = "/batch" + strings.TrimSuffix(base.Path, "/")
+	req, err := http.NewRequest(http.MethodPost, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "multipart/mixed; boundary="+w.Boundary())
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err = googleapi.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+	_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(keys))
+	found := make([]bool, len(keys))
+	r := multipart.NewReader(resp.Body, params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Content-ID of response is "response-<Content-ID of request>".
+		k, err := strconv.Atoi(strings.TrimPrefix(part.Header.Get("Content-ID"), "response-"))
+		if err != nil || k < 0 || k >= len(keys) {
+			return nil, fmt.Errorf("invalid content id %s in batch response", part.Header.Get("Content-ID"))
+		}
+
+		partResp, err := http.ReadResponse(bufio.NewReader(part), req)
+		if err != nil {
+			return nil, err
+		}
+		errs[k] = googleapi.CheckResponse(partResp)
+		partResp.Body.Close()
+		found[k] = true
+	}
+	for k, v := range found {
+		if !v {
+			errs[k] = fmt.Errorf("no response for %s in batch response", keys[k])
+		}
+	}
+	return errs, nil
+}
+
+// setObjectHeaders will set object's http content headers from gcs object attrs.
+// getObject will return the handle of rp, generation will be used as version id if given.
+func (s *Storage) getObject(rp, versionID string) (*gs.ObjectHandle, error) {
+	object := s.bucket.Object(rp)
+	if versionID == "" {
+		return object, nil
+	}
+
+	generation, err := strconv.ParseInt(versionID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid generation %s", types.ErrObjectNotExist, versionID)
+	}
+	return object.Generation(generation), nil
+}
+
+// withConditions will apply if_match and if_none_match to object as gcs preconditions.
+//
+// gcs preconditions are based on generation instead of etag, so if_match only accepts generation which is
+// returned as version-id in Stat, and if_none_match only accepts "*".
+func withConditions(object *gs.ObjectHandle, hasIfMatch bool, ifMatch string, hasIfNoneMatch bool, ifNoneMatch string) (*gs.ObjectHandle, error) {
+	var cond gs.Conditions
+	if hasIfMatch {
+		generation, err := strconv.ParseInt(ifMatch, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("if_match %s is not a generation: %w", ifMatch, types.ErrNotSupported)
+		}
+		cond.GenerationMatch = generation
+	}
+	if hasIfNoneMatch {
+		if ifNoneMatch != "*" {
+			return nil, fmt.Errorf("if_none_match %s is not *: %w", ifNoneMatch, types.ErrNotSupported)
+		}
+		cond.DoesNotExist = true
+	}
+
+	if cond == (gs.Conditions{}) {
+		return object, nil
+	}
+	return object.If(cond), nil
+}
+
+func formatVersionID(generation int64) string {
+	return strconv.FormatInt(generation, 10)
+}
+
+// tagsMetadataKey is the key of custom metadata which stores tags, because gcs doesn't support object tagging.
+//
+// Tags are stored in a single key and encoded as query string, so that they could be replaced by one patch.
+const tagsMetadataKey = "storage-tags"
+
+// formatTags will decode tags from custom metadata.
+func formatTags(m map[string]string) (map[string]string, error) {
+	tags, err := tagging.Decode(m[tagsMetadataKey])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid tags %s: %v", types.ErrUnhandledError, m[tagsMetadataKey], err)
+	}
+	return tags, nil
+}
+
+// formatUserMetadata will return custom metadata without tags.
+func formatUserMetadata(m map[string]string) map[string]string {
+	meta := make(map[string]string, len(m))
+	for k, v := range m {
+		if k == tagsMetadataKey {
+			continue
+		}
+		meta[k] = v
+	}
+	return meta
+}
+
+func setObjectHeaders(o *types.Object, attr *gs.ObjectAttrs) {
+	if attr.ContentType != "" {
+		o.SetContentType(attr.ContentType)
+	}
+	if attr.ContentEncoding != "" {
+		o.SetContentEncoding(attr.ContentEncoding)
+	}
+	if attr.ContentDisposition != "" {
+		o.SetContentDisposition(attr.ContentDisposition)
+	}
+	if attr.CacheControl != "" {
+		o.SetCacheControl(attr.CacheControl)
+	}
+}
+
+// setObjectChecksum will set object's checksum from gcs object attrs.
+func setObjectChecksum(o *types.Object, attr *gs.ObjectAttrs) {
+	if attr.Etag != "" {
+		o.SetETag(attr.Etag)
+	}
+	// Composite objects don't have MD5.
+	if len(attr.MD5) > 0 {
+		o.SetContentMD5(checksum.FormatBytes(attr.MD5))
+	}
+	o.SetContentCRC32C(checksum.FormatCRC32C(attr.CRC32C))
+}
+
 const (
 	storageClassStandard = "STANDARD"
 	storageClassNearLine = "NEARLINE"
@@ -48,3 +272,102 @@ func formatStorageClass(in string) (storageclass.Type, error) {
 		return "", types.ErrStorageClassNotSupported
 	}
 }
+
+// handleGcsError will map gcs errors and http status codes onto sentinel errors.
+//
+// ref: https://cloud.google.com/storage/docs/json_api/v1/status-codes
+func handleGcsError(err error) error {
+	if err == nil {
+		panic("error must not be nil")
+	}
+	// Context errors will be returned as is, so caller could check them via errors.Is.
+	if types.IsContextError(err) {
+		return err
+	}
+
+	if errors.Is(err, gs.ErrObjectNotExist) || errors.Is(err, gs.ErrBucketNotExist) {
+		return fmt.Errorf("%w: %v", types.ErrObjectNotExist, err)
+	}
+
+	var e *googleapi.Error
+	if !errors.As(err, &e) {
+		if types.IsTimeout(err) {
+			return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+	}
+
+	for _, v := range e.Errors {
+		switch v.Reason {
+		case "rateLimitExceeded", "userRateLimitExceeded":
+			return fmt.Errorf("%w: %v", types.ErrRateLimited, err)
+		case "quotaExceeded", "dailyLimitExceeded":
+			return fmt.Errorf("%w: %v", types.ErrQuotaExceeded, err)
+		case "conditionNotMet":
+			return fmt.Errorf("%w: %v", types.ErrPreconditionFailed, err)
+		case "conflict", "alreadyExists":
+			// gcs returns "conflict" while bucket name is already taken.
+			return fmt.Errorf("%w: %v", types.ErrObjectAlreadyExist, err)
+		case "authError":
+			return fmt.Errorf("%w: %v", types.ErrConfigIncorrect, err)
+		}
+	}
+
+	if se := types.ErrFromStatusCode(e.Code); se != nil {
+		return fmt.Errorf("%w: %v", se, err)
+	}
+	return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+}
+
+// parseLifecycle will parse types.LifecycleRule into gcs lifecycle.
+//
+// gcs rules don't have id, prefix or status, and every gcs rule only has one action, so a rule will be split into
+// one gcs rule per action.
+func parseLifecycle(in []types.LifecycleRule) (l gs.Lifecycle, err error) {
+	for _, v := range in {
+		if v.Prefix != "" || !v.Enabled || v.AbortIncompleteSegmentDays > 0 {
+			return l, fmt.Errorf("%w: rule %s has prefix, is disabled or aborts segments", types.ErrNotSupported, v.ID)
+		}
+
+		if v.ExpirationDays > 0 {
+			l.Rules = append(l.Rules, gs.LifecycleRule{
+				Action:    gs.LifecycleAction{Type: gs.DeleteAction},
+				Condition: gs.LifecycleCondition{AgeInDays: int64(v.ExpirationDays)},
+			})
+		}
+		for _, t := range v.Transitions {
+			storageClass, err := parseStorageClass(t.StorageClass)
+			if err != nil {
+				return l, err
+			}
+			l.Rules = append(l.Rules, gs.LifecycleRule{
+				Action:    gs.LifecycleAction{Type: gs.SetStorageClassAction, StorageClass: storageClass},
+				Condition: gs.LifecycleCondition{AgeInDays: int64(t.Days)},
+			})
+		}
+	}
+	return l, nil
+}
+
+// formatLifecycleRule will format gcs lifecycle rule into types.LifecycleRule.
+func formatLifecycleRule(in gs.LifecycleRule) (rule types.LifecycleRule, err error) {
+	c := in.Condition
+	if !c.CreatedBefore.IsZero() || c.Liveness != gs.LiveAndArchived || len(c.MatchesStorageClasses) > 0 || c.NumNewerVersions > 0 {
+		return rule, fmt.Errorf("%w: rule has conditions other than age", types.ErrNotSupported)
+	}
+
+	rule.Enabled = true
+	switch in.Action.Type {
+	case gs.DeleteAction:
+		rule.ExpirationDays = int(c.AgeInDays)
+	case gs.SetStorageClassAction:
+		storageClass, err := formatStorageClass(in.Action.StorageClass)
+		if err != nil {
+			return rule, err
+		}
+		rule.Transitions = []types.LifecycleTransition{{Days: int(c.AgeInDays), StorageClass: storageClass}}
+	default:
+		return rule, fmt.Errorf("%w: rule has action %s", types.ErrNotSupported, in.Action.Type)
+	}
+	return rule, nil
+}
@@ -17,22 +17,26 @@ type Service struct {
 }
 
 // New will create a new kodo service.
+//
+// Credential will only be retrieved once, because kodo only supports hmac credential and
+// SDK signs requests with keys in a shared Mac which can't be replaced safely.
 func New(pairs ...*types.Pair) (s *Service, err error) {
-	const errorMessage = "%s New: %w"
-
 	s = &Service{}
 
 	opt, err := parseServicePairNew(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
-	credProtocol, cred := opt.Credential.Protocol(), opt.Credential.Value()
-	if credProtocol != credential.ProtocolHmac {
-		return nil, fmt.Errorf(errorMessage, s, err)
+	cred, err := opt.Credential.Retrieve()
+	if err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	if cred.Protocol != credential.ProtocolHmac {
+		return nil, types.NewError("New", s, "", pairs, credential.ErrUnsupportedProtocol)
 	}
 
-	mac := qbox.NewMac(cred[0], cred[1])
+	mac := qbox.NewMac(cred.Args[0], cred.Args[1])
 	cfg := &qs.Config{}
 	s.service = qs.NewBucketManager(mac, cfg)
 	return
@@ -45,18 +49,16 @@ func (s *Service) String() string {
 
 // List implements Service.List
 func (s *Service) List(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List: %w"
-
 	opt, err := parseServicePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("List", s, "", pairs, err)
 	}
 
 	buckets, err := s.service.Buckets(false)
 	for _, v := range buckets {
 		store, err := newStorage(s.service, v)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, err)
+			return types.NewError("List", s, "", pairs, err)
 		}
 		opt.StoragerFunc(store)
 	}
@@ -65,50 +67,46 @@ func (s *Service) List(pairs ...*types.Pair) (err error) {
 
 // Get implements Service.Get
 func (s *Service) Get(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Get [%s]: %w"
-
 	c, err := newStorage(s.service, name)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Get", s, name, pairs, err)
 	}
 	return c, nil
 }
 
 // Create implements Service.Create
 func (s *Service) Create(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Create [%s]: %w"
-
 	opt, err := parseServicePairCreate(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 
 	// Check region ID.
 	_, ok := qs.GetRegionByID(qs.RegionID(opt.Location))
 	if !ok {
 		err = fmt.Errorf("region %s is invalid", opt.Location)
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 
 	err = s.service.CreateBucket(name, qs.RegionID(opt.Location))
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		err = handleKodoError(err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 
 	c, err := newStorage(s.service, name)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 	return c, nil
 }
 
 // Delete implements Service.Delete
 func (s *Service) Delete(name string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
-
 	err = s.service.DropBucket(name)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		err = handleKodoError(err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 	return nil
 }
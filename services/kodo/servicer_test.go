@@ -0,0 +1,19 @@
+package kodo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(pairs.WithCredential(credential.MustNewAPIKey("key")))
+	assert.True(t, errors.Is(err, credential.ErrUnsupportedProtocol))
+
+	_, err = New(pairs.WithCredential(credential.MustNewHmac("ak", "sk")))
+	assert.NoError(t, err)
+}
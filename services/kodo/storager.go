@@ -7,8 +7,12 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/Xuanwo/storage/pkg/prefix"
+	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/metadata"
+	ps "github.com/Xuanwo/storage/types/pairs"
+	"github.com/qiniu/api.v7/v7/auth"
 	qs "github.com/qiniu/api.v7/v7/storage"
 )
 
@@ -20,8 +24,9 @@ type Storage struct {
 	domain    string
 	putPolicy qs.PutPolicy // kodo need PutPolicy to generate upload token.
 
-	name    string
-	workDir string
+	name         string
+	workDir      string
+	storageClass storageclass.Type
 }
 
 // newStorage will create a new client.
@@ -29,7 +34,7 @@ func newStorage(bucket *qs.BucketManager, name string) (s *Storage, err error) {
 	// Get bucket's domain.
 	domains, err := bucket.ListBucketDomains(name)
 	if err != nil {
-		return nil, err
+		return nil, handleKodoError(err)
 	}
 	// TODO: we need to choose user's production domain.
 	if len(domains) == 0 {
@@ -59,17 +64,22 @@ func (s *Storage) String() string {
 
 // Init implements Storager.Init
 func (s *Storage) Init(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Init: %w"
-
 	opt, err := parseStoragePairInit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("Init", s, "", pairs, err)
 	}
 
 	if opt.HasWorkDir {
 		// TODO: we should validate workDir
 		s.workDir = strings.TrimLeft(opt.WorkDir, "/")
 	}
+	if opt.HasStorageClass {
+		// Storage class will be used as default for Write, validate it here to fail fast.
+		if _, err = parseStorageClass(opt.StorageClass); err != nil {
+			return types.NewError("Init", s, "", pairs, err)
+		}
+		s.storageClass = opt.StorageClass
+	}
 
 	return nil
 }
@@ -79,25 +89,29 @@ func (s *Storage) Metadata(pairs ...*types.Pair) (m metadata.StorageMeta, err er
 	m = metadata.NewStorageMeta()
 	m.Name = s.name
 	m.WorkDir = s.workDir
+	m.SetFlatList(true)
 	return m, nil
 }
 
 // List implements Storager.List
 func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List [%s]: %w"
-
 	opt, err := parseStoragePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("List", s, path, pairs, err)
 	}
 
 	marker := ""
 	rp := s.getAbsPath(path)
 
 	for {
+		if err = opt.Context.Err(); err != nil {
+			return types.NewError("List", s, path, pairs, err)
+		}
+
 		entries, _, nextMarker, _, err := s.bucket.ListFiles(s.name, rp, "", marker, 1000)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			err = handleKodoError(err)
+			return types.NewError("List", s, path, pairs, err)
 		}
 
 		for _, v := range entries {
@@ -110,10 +124,11 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 			}
 			o.SetContentType(v.MimeType)
 			o.SetETag(v.Hash)
+			setObjectChecksum(o.ObjectMeta, v.Hash)
 
 			storageClass, err := formatStorageClass(v.Type)
 			if err != nil {
-				return fmt.Errorf(errorMessage, s, path, err)
+				return types.NewError("List", s, path, pairs, err)
 			}
 			o.SetStorageClass(storageClass)
 
@@ -129,15 +144,40 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 
 // Read implements Storager.Read
 func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err error) {
-	const errorMessage = "%s Read [%s]: %w"
+	opt, err := parseStoragePairRead(pairs...)
+	if err != nil {
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
 	url := qs.MakePrivateURL(s.bucket.Mac, s.domain, rp, 3600)
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(opt.Context, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		err = handleKodoError(err)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = handleKodoError(qs.ResponseError(resp))
+		resp.Body.Close()
+		// kodo doesn't have a dedicated error code for reading archived file, so we check object's
+		// storage class instead.
+		if !errors.Is(err, types.ErrObjectNotExist) {
+			if fi, serr := s.bucket.Stat(s.name, rp); serr == nil && fi.Type == storageClassArchive {
+				err = fmt.Errorf("%w: %v", types.ErrObjectArchived, err)
+			}
+		}
+		return nil, types.NewError("Read", s, path, pairs, err)
 	}
 
 	r = resp.Body
@@ -146,34 +186,77 @@ func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err
 
 // Write implements Storager.Write
 func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Write [%s]: %w"
-
 	opt, err := parseStoragePairWrite(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	if !opt.HasStorageClass && s.storageClass != "" {
+		// Storage class set in Init will be used as default.
+		opt.HasStorageClass, opt.StorageClass = true, s.storageClass
+	}
+	if opt.HasIfMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
+	extra := &qs.PutExtra{}
+	if opt.HasContentType {
+		extra.MimeType = opt.ContentType
+	}
+
+	policy := s.putPolicy
+	if opt.HasIfNoneMatch {
+		// kodo could only forbid overwriting via insert only put policy.
+		if opt.IfNoneMatch != "*" {
+			err = types.NewErrPairNotSupported(ps.IfNoneMatch)
+			return types.NewError("Write", s, path, pairs, err)
+		}
+		policy.InsertOnly = 1
+	}
+	if opt.HasStorageClass {
+		fileType, err := parseStorageClass(opt.StorageClass)
+		if err != nil {
+			return types.NewError("Write", s, path, pairs, err)
+		}
+		policy.FileType = fileType
+	}
+
 	uploader := qs.NewFormUploader(s.bucket.Cfg)
 	ret := qs.PutRet{}
 	err = uploader.Put(opt.Context,
-		&ret, s.putPolicy.UploadToken(s.bucket.Mac), rp, r, opt.Size, nil)
+		&ret, policy.UploadToken(s.bucket.Mac), rp, r, opt.Size, extra)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleKodoError(err)
+		if opt.HasIfNoneMatch && errors.Is(err, types.ErrObjectAlreadyExist) {
+			err = fmt.Errorf("%w: %v", types.ErrPreconditionFailed, err)
+		}
+		return types.NewError("Write", s, path, pairs, err)
 	}
 	return nil
 }
 
 // Stat implements Storager.Stat
 func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err error) {
-	const errorMessage = "%s Stat [%s]: %w"
+	opt, err := parseStoragePairStat(pairs...)
+	if err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
 	fi, err := s.bucket.Stat(s.name, rp)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleKodoError(err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	o = &types.Object{
@@ -185,10 +268,14 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 		ObjectMeta: metadata.NewObjectMeta(),
 	}
 	o.SetETag(fi.Hash)
+	setObjectChecksum(o.ObjectMeta, fi.Hash)
+	if fi.MimeType != "" {
+		o.SetContentType(fi.MimeType)
+	}
 
 	storageClass, err := formatStorageClass(fi.Type)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 	o.SetStorageClass(storageClass)
 
@@ -197,13 +284,90 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 
 // Delete implements Storager.Delete
 func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
+	opt, err := parseStoragePairDelete(pairs...)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
+	// Key ends with "/" is a dir, kodo will delete it even if there are keys under it.
+	if strings.HasSuffix(rp, "/") {
+		err = prefix.CheckDirEmpty(opt.Context, rp, s.listKeys)
+		if err != nil {
+			return types.NewError("Delete", s, path, pairs, err)
+		}
+	}
+
 	err = s.bucket.Delete(s.name, rp)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleKodoError(err)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	return nil
+}
+
+// Restore implements Storager.Restore
+//
+// kodo SDK doesn't support restoreAr yet, so we call it via SDK's client, tier is not supported by kodo.
+//
+// ref: https://developer.qiniu.com/kodo/api/6380/restore-archive
+func (s *Storage) Restore(path string, days int, tier storageclass.RestoreTier, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairRestore(pairs...)
+	if err != nil {
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	host, err := s.bucket.RsReqHost(s.name)
+	if err != nil {
+		err = handleKodoError(err)
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+	url := fmt.Sprintf("%s/restoreAr/%s/freezeAfterDays/%d", host, qs.EncodedEntry(s.name, rp), days)
+
+	err = s.bucket.Client.Call(auth.WithCredentials(opt.Context, s.bucket.Mac), nil, http.MethodPost, url, nil)
+	// kodo refuses to restore object which is being restored or already restored, treat it as success.
+	var e *qs.ErrorInfo
+	if errors.As(err, &e) && strings.Contains(strings.ToLower(e.Err), "already") {
+		return nil
+	}
+	if err != nil {
+		err = handleKodoError(err)
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+	return nil
+}
+
+// Transit implements Storager.Transit
+func (s *Storage) Transit(path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairTransit(pairs...)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	fileType, err := parseStorageClass(storageClass)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	err = s.bucket.ChangeType(s.name, rp, fileType)
+	if err != nil {
+		err = handleKodoError(err)
+		return types.NewError("Transit", s, path, pairs, err)
 	}
 	return nil
 }
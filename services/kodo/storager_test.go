@@ -0,0 +1,83 @@
+package kodo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qiniu/api.v7/v7/auth"
+	qs "github.com/qiniu/api.v7/v7/storage"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/types"
+)
+
+// newTestStorage will create a Storage which sends all requests to endpoint.
+func newTestStorage(endpoint string) *Storage {
+	bucket := qs.NewBucketManager(auth.New("ak", "sk"), &qs.Config{RsHost: endpoint})
+	return &Storage{
+		bucket: bucket,
+		domain: endpoint,
+		name:   "test",
+	}
+}
+
+func TestStorage_ReadArchived(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/object":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"forbidden"}`))
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/stat/"):
+			_, _ = w.Write([]byte(`{"hash":"Fto5o-5ea0sNMlW_75VgGJCv2AcJ","type":2}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	s := newTestStorage(server.URL)
+
+	_, err := s.Read("object")
+	assert.True(t, errors.Is(err, types.ErrObjectArchived))
+}
+
+func TestStorage_Restore(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		err    error
+	}{
+		{"restore", http.StatusOK, "", nil},
+		{"restoring", http.StatusBadRequest, `{"error":"already in restore progress"}`, nil},
+		{"not exist", 612, `{"error":"no such file or directory"}`, types.ErrObjectNotExist},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.True(t, strings.HasPrefix(r.URL.Path, "/restoreAr/"))
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			s := newTestStorage(server.URL)
+
+			err := s.Restore("object", 1, storageclass.RestoreTierStandard)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+		})
+	}
+}
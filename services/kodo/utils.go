@@ -1,11 +1,19 @@
 package kodo
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	qs "github.com/qiniu/api.v7/v7/storage"
+
+	"github.com/Xuanwo/storage/pkg/checksum"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/metadata"
 )
 
 func (s *Storage) getAbsPath(path string) string {
@@ -16,6 +24,37 @@ func (s *Storage) getRelPath(path string) string {
 	return strings.TrimPrefix(path, s.workDir+"/")
 }
 
+// listKeys implements prefix.ListFunc
+func (s *Storage) listKeys(ctx context.Context, prefix, marker string, limit int) (keys []string, next string, err error) {
+	// kodo sdk doesn't support context, check it before request.
+	if err = ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	entries, _, next, hasNext, err := s.bucket.ListFiles(s.name, prefix, "", marker, limit)
+	if err != nil {
+		return nil, "", handleKodoError(err)
+	}
+
+	keys = make([]string, 0, len(entries))
+	for _, v := range entries {
+		keys = append(keys, v.Key)
+	}
+	if !hasNext {
+		next = ""
+	}
+	return keys, next, nil
+}
+
+// setObjectChecksum will set object's checksum via kodo's hash, which is qiniu etag in url safe base64.
+func setObjectChecksum(m metadata.ObjectMeta, hash string) {
+	b, err := base64.URLEncoding.DecodeString(hash)
+	if err != nil {
+		return
+	}
+	m.SetQiniuETag(checksum.FormatBytes(b))
+}
+
 func convertUnixTimestampToTime(v int64) time.Time {
 	if v == 0 {
 		return time.Time{}
@@ -57,3 +96,52 @@ func formatStorageClass(in int) (storageclass.Type, error) {
 		return "", types.ErrStorageClassNotSupported
 	}
 }
+
+// handleKodoError will map kodo error codes onto sentinel errors.
+//
+// ref: https://developer.qiniu.com/kodo/api/3928/error-responses
+func handleKodoError(err error) error {
+	if err == nil {
+		panic("error must not be nil")
+	}
+	// Context errors will be returned as is, so caller could check them via errors.Is.
+	if types.IsContextError(err) {
+		return err
+	}
+
+	var e *qs.ErrorInfo
+	if !errors.As(err, &e) {
+		if types.IsTimeout(err) {
+			return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+	}
+
+	switch e.Code {
+	case 401:
+		return fmt.Errorf("%w: %v", types.ErrConfigIncorrect, err)
+	case 612, 631:
+		// 612: no such file, 631: no such bucket.
+		return fmt.Errorf("%w: %v", types.ErrObjectNotExist, err)
+	case 614:
+		// 614: file or bucket already exists.
+		return fmt.Errorf("%w: %v", types.ErrObjectAlreadyExist, err)
+	case 608:
+		// 608: file has been modified.
+		return fmt.Errorf("%w: %v", types.ErrPreconditionFailed, err)
+	case 630:
+		// 630: too many buckets.
+		return fmt.Errorf("%w: %v", types.ErrQuotaExceeded, err)
+	case 573:
+		// 573: request is too frequent.
+		return fmt.Errorf("%w: %v", types.ErrRateLimited, err)
+	case 599:
+		// 599: server internal error.
+		return fmt.Errorf("%w: %v", types.ErrServiceUnavailable, err)
+	}
+
+	if se := types.ErrFromStatusCode(e.Code); se != nil {
+		return fmt.Errorf("%w: %v", se, err)
+	}
+	return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+}
@@ -0,0 +1,27 @@
+package kodo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/checksum"
+	"github.com/Xuanwo/storage/types/metadata"
+)
+
+func TestSetObjectChecksum(t *testing.T) {
+	m := metadata.NewObjectMeta()
+	// Qiniu etag of empty content.
+	setObjectChecksum(m, "Fto5o-5ea0sNMlW_75VgGJCv2AcJ")
+
+	h, err := checksum.New(checksum.QiniuETag)
+	assert.NoError(t, err)
+	v, ok := m.GetQiniuETag()
+	assert.True(t, ok)
+	assert.Equal(t, checksum.FormatBytes(h.Sum(nil)), v)
+
+	m = metadata.NewObjectMeta()
+	setObjectChecksum(m, "invalid hash")
+	_, ok = m.GetQiniuETag()
+	assert.False(t, ok)
+}
@@ -17,24 +17,36 @@ type Service struct {
 
 // New will create a new aliyun oss service.
 func New(pairs ...*types.Pair) (s *Service, err error) {
-	const errorMessage = "%s New: %w"
-
 	s = &Service{}
 
 	opt, err := parseServicePairNew(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
-	credProtocol, cred := opt.Credential.Protocol(), opt.Credential.Value()
-	if credProtocol != credential.ProtocolHmac {
-		return nil, fmt.Errorf(errorMessage, s, credential.ErrUnsupportedProtocol)
+	cred, err := opt.Credential.Retrieve()
+	if err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	if cred.Protocol == credential.ProtocolFile {
+		cred, err = loadCredentialFile(cred.File())
+		if err != nil {
+			return nil, types.NewError("New", s, "", pairs, err)
+		}
+	}
+	if cred.Protocol != credential.ProtocolHmac && cred.Protocol != credential.ProtocolSTS {
+		return nil, types.NewError("New", s, "", pairs, credential.ErrUnsupportedProtocol)
 	}
 	ep := opt.Endpoint.Value()
+	// oss SDK signs requests without base path, and can't connect via unix socket.
+	if err = ep.CheckHostOnly(); err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
 
-	s.service, err = oss.New(ep.String(), cred[0], cred[1])
+	s.service, err = oss.New(ep.String(), cred.Args[0], cred.Args[1],
+		oss.SetCredentialsProvider(newCredentialsProvider(opt.Credential, cred)))
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 	return
 }
@@ -49,28 +61,31 @@ func (s *Service) String() string {
 
 // List implements Servicer.List
 func (s *Service) List(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List: %w"
-
 	opt, err := parseServicePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("List", s, "", pairs, err)
 	}
 
 	marker := ""
 	var output oss.ListBucketsResult
 	for {
+		if err = opt.Context.Err(); err != nil {
+			return types.NewError("List", s, "", pairs, err)
+		}
+
 		output, err = s.service.ListBuckets(
 			oss.Marker(marker),
 			oss.MaxKeys(1000),
 		)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, err)
+			err = handleOssError(err)
+			return types.NewError("List", s, "", pairs, err)
 		}
 
 		for _, v := range output.Buckets {
 			bucket, err := s.service.Bucket(v.Name)
 			if err != nil {
-				return fmt.Errorf(errorMessage, s, err)
+				return types.NewError("List", s, "", pairs, err)
 			}
 			if opt.HasStoragerFunc {
 				c := newStorage(bucket)
@@ -88,37 +103,50 @@ func (s *Service) List(pairs ...*types.Pair) (err error) {
 
 // Get implements Servicer.Get
 func (s *Service) Get(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Get [%s]: %w"
-
 	bucket, err := s.service.Bucket(name)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Get", s, name, pairs, err)
 	}
 	return newStorage(bucket), nil
 }
 
 // Create implements Servicer.Create
 func (s *Service) Create(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Create [%s]: %w"
+	opt, err := parseServicePairCreate(pairs...)
+	if err != nil {
+		return nil, types.NewError("Create", s, name, pairs, err)
+	}
 
-	err := s.service.CreateBucket(name)
+	err = s.service.CreateBucket(name)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		err = handleOssError(err)
+		return nil, types.NewError("Create", s, name, pairs, err)
+	}
+
+	if opt.HasVersioning {
+		status := oss.VersionSuspended
+		if opt.Versioning {
+			status = oss.VersionEnabled
+		}
+		err = s.service.SetBucketVersioning(name, oss.VersioningConfig{Status: string(status)})
+		if err != nil {
+			err = handleOssError(err)
+			return nil, types.NewError("Create", s, name, pairs, err)
+		}
 	}
 	bucket, err := s.service.Bucket(name)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 	return newStorage(bucket), nil
 }
 
 // Delete implements Servicer.Delete
 func (s *Service) Delete(name string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
-
 	err = s.service.DeleteBucket(name)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		err = handleOssError(err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 	return nil
 }
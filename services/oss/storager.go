@@ -1,6 +1,8 @@
 package oss
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -9,18 +11,32 @@ import (
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 
+	"github.com/Xuanwo/storage/pkg/checksum"
+	"github.com/Xuanwo/storage/pkg/iowrap"
+	"github.com/Xuanwo/storage/pkg/prefix"
+	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/metadata"
+	ps "github.com/Xuanwo/storage/types/pairs"
 )
 
+// deleteBatchLimit is the max count of keys in one DeleteObjects request.
+const deleteBatchLimit = 1000
+
+// copyObjectSizeLimit is the max size of object which could be copied in one CopyObject request.
+//
+// ref: https://help.aliyun.com/document_detail/31979.html
+const copyObjectSizeLimit = 1 * 1024 * 1024 * 1024
+
 // Storage is the aliyun object storage service.
 //
 //go:generate ../../internal/bin/service
 type Storage struct {
 	bucket *oss.Bucket
 
-	name    string
-	workDir string
+	name         string
+	workDir      string
+	storageClass storageclass.Type
 }
 
 // newStorage will create a new client.
@@ -41,17 +57,22 @@ func (s *Storage) String() string {
 
 // Init implements Storager.Init
 func (s *Storage) Init(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Init: %w"
-
 	opt, err := parseStoragePairInit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("Init", s, "", pairs, err)
 	}
 
 	if opt.HasWorkDir {
 		// TODO: we should validate workDir
 		s.workDir = strings.TrimLeft(opt.WorkDir, "/")
 	}
+	if opt.HasStorageClass {
+		// Storage class will be used as default for Write, validate it here to fail fast.
+		if _, err = parseStorageClass(opt.StorageClass); err != nil {
+			return types.NewError("Init", s, "", pairs, err)
+		}
+		s.storageClass = opt.StorageClass
+	}
 
 	return nil
 }
@@ -61,16 +82,15 @@ func (s *Storage) Metadata(pairs ...*types.Pair) (m metadata.StorageMeta, err er
 	m = metadata.NewStorageMeta()
 	m.Name = s.bucket.BucketName
 	m.WorkDir = s.workDir
+	m.SetFlatList(true)
 	return m, nil
 }
 
 // List implements Storager.List
 func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List [%s]: %w"
-
 	opt, err := parseStoragePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("List", s, path, pairs, err)
 	}
 
 	marker := ""
@@ -80,13 +100,18 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 
 	var output oss.ListObjectsResult
 	for {
+		if err = opt.Context.Err(); err != nil {
+			return types.NewError("List", s, path, pairs, err)
+		}
+
 		output, err = s.bucket.ListObjects(
 			oss.Marker(marker),
 			oss.MaxKeys(limit),
 			oss.Prefix(rp),
 		)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			err = handleOssError(err)
+			return types.NewError("List", s, path, pairs, err)
 		}
 
 		for _, v := range output.CommonPrefixes {
@@ -114,10 +139,11 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 
 			o.SetContentType(v.Type)
 			o.SetETag(v.ETag)
+			checksum.FromETag(o.ObjectMeta, v.ETag)
 
 			storageClass, err := formatStorageClass(v.Type)
 			if err != nil {
-				return fmt.Errorf(errorMessage, s, path, err)
+				return types.NewError("List", s, path, pairs, err)
 			}
 			o.SetStorageClass(storageClass)
 
@@ -136,24 +162,63 @@ func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
 
 // Read implements Storager.Read
 func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err error) {
-	const errorMessage = "%s Read [%s]: %w"
+	opt, err := parseStoragePairRead(pairs...)
+	if err != nil {
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+
+	options := make([]oss.Option, 0)
+	if opt.HasIfMatch {
+		options = append(options, oss.IfMatch(opt.IfMatch))
+	}
+	if opt.HasIfNoneMatch {
+		options = append(options, oss.IfNoneMatch(opt.IfNoneMatch))
+	}
+	if opt.HasIfModifiedSince {
+		options = append(options, oss.IfModifiedSince(opt.IfModifiedSince))
+	}
+	if opt.HasIfUnmodifiedSince {
+		options = append(options, oss.IfUnmodifiedSince(opt.IfUnmodifiedSince))
+	}
+	if opt.HasVersionID {
+		options = append(options, oss.VersionId(opt.VersionID))
+	}
 
 	rp := s.getAbsPath(path)
 
-	output, err := s.bucket.GetObject(rp)
+	output, err := s.bucket.DoGetObject(&oss.GetObjectRequest{ObjectKey: rp}, options)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleOssError(err)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+
+	r = iowrap.ContextReadCloser(opt.Context, output.Response)
+	if opt.HasVerifyChecksum && opt.VerifyChecksum {
+		m := metadata.NewObjectMeta()
+		setObjectChecksum(m, output.Response.Headers)
+
+		r, err = checksum.NewVerifyReadCloser(r, m)
+		if err != nil {
+			output.Response.Close()
+			return nil, types.NewError("Read", s, path, pairs, err)
+		}
 	}
-	return output, nil
+	return r, nil
 }
 
 // Write implements Storager.Write
 func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Write [%s]: %w"
-
 	opt, err := parseStoragePairWrite(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	if !opt.HasStorageClass && s.storageClass != "" {
+		// Storage class set in Init will be used as default.
+		opt.HasStorageClass, opt.StorageClass = true, s.storageClass
+	}
+	if opt.HasIfMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 
 	options := make([]oss.Option, 0)
@@ -164,42 +229,103 @@ func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err err
 		options = append(options, oss.ContentLength(opt.Size))
 	}
 	if opt.HasStorageClass {
-		// TODO: we need to handle different storage class name between services.
-		options = append(options, oss.StorageClass(oss.StorageClassType(opt.StorageClass)))
+		storageClass, err := parseStorageClass(opt.StorageClass)
+		if err != nil {
+			return types.NewError("Write", s, path, pairs, err)
+		}
+		options = append(options, oss.StorageClass(oss.StorageClassType(storageClass)))
+	}
+	for k, v := range opt.UserMetadata {
+		options = append(options, oss.Meta(k, v))
+	}
+	if opt.HasContentType {
+		options = append(options, oss.ContentType(opt.ContentType))
+	}
+	if opt.HasContentEncoding {
+		options = append(options, oss.ContentEncoding(opt.ContentEncoding))
+	}
+	if opt.HasContentDisposition {
+		options = append(options, oss.ContentDisposition(opt.ContentDisposition))
+	}
+	if opt.HasCacheControl {
+		options = append(options, oss.CacheControl(opt.CacheControl))
+	}
+	if opt.HasExpires {
+		options = append(options, oss.Expires(opt.Expires))
+	}
+	// SetTagging will return nil option for empty tags.
+	if len(opt.Tags) > 0 {
+		options = append(options, oss.SetTagging(parseTagging(opt.Tags)))
+	}
+
+	if opt.HasIfNoneMatch {
+		// oss could only forbid overwriting existing object, which equals to if_none_match "*".
+		if opt.IfNoneMatch != "*" {
+			err = types.NewErrPairNotSupported(ps.IfNoneMatch)
+			return types.NewError("Write", s, path, pairs, err)
+		}
+		options = append(options, withHeader(forbidOverwriteHeader, "true"))
 	}
 
 	rp := s.getAbsPath(path)
 
-	err = s.bucket.PutObject(rp, r, options...)
+	err = s.bucket.PutObject(rp, iowrap.ContextReader(opt.Context, r), options...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleOssError(err)
+		if opt.HasIfNoneMatch && errors.Is(err, types.ErrObjectAlreadyExist) {
+			err = fmt.Errorf("%w: %v", types.ErrPreconditionFailed, err)
+		}
+		return types.NewError("Write", s, path, pairs, err)
 	}
 	return nil
 }
 
 // Stat implements Storager.Stat
 func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err error) {
-	const errorMessage = "%s Stat [%s]: %w"
+	opt, err := parseStoragePairStat(pairs...)
+	if err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+
+	options := make([]oss.Option, 0)
+	if opt.HasIfMatch {
+		options = append(options, oss.IfMatch(opt.IfMatch))
+	}
+	if opt.HasIfNoneMatch {
+		options = append(options, oss.IfNoneMatch(opt.IfNoneMatch))
+	}
+	if opt.HasIfModifiedSince {
+		options = append(options, oss.IfModifiedSince(opt.IfModifiedSince))
+	}
+	if opt.HasIfUnmodifiedSince {
+		options = append(options, oss.IfUnmodifiedSince(opt.IfUnmodifiedSince))
+	}
+	if opt.HasVersionID {
+		options = append(options, oss.VersionId(opt.VersionID))
+	}
 
 	rp := s.getAbsPath(path)
 
-	output, err := s.bucket.GetObjectMeta(rp)
+	output, err := s.bucket.GetObjectDetailedMeta(rp, options...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleOssError(err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	// Parse content length.
 	size, err := strconv.ParseInt(output.Get("Content-Length"), 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 	// Parse last modified.
 	lastModified, err := time.Parse(time.RFC822, output.Get("Last-Modified"))
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
-	// TODO: get object's checksum and storage class.
 	o = &types.Object{
 		ID:         rp,
 		Name:       path,
@@ -208,25 +334,474 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 		UpdatedAt:  lastModified,
 		ObjectMeta: metadata.NewObjectMeta(),
 	}
+	if v := output.Get("ETag"); v != "" {
+		o.SetETag(v)
+	}
+	setObjectChecksum(o.ObjectMeta, output)
+	setObjectHeaders(o.ObjectMeta, output)
 
 	storageClass, err := formatStorageClass(output.Get(storageClassHeader))
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 	o.SetStorageClass(storageClass)
+	if storageClass == storageclass.Cold {
+		o.SetRestoreStatus(formatRestoreStatus(output.Get(restoreHeader)))
+	}
+	if meta := formatUserMetadata(output); len(meta) > 0 {
+		o.SetUserMetadata(meta)
+	}
+	if v := oss.GetVersionId(output); v != "" {
+		o.SetVersionID(v)
+	}
 
 	return o, nil
 }
 
 // Delete implements Storager.Delete
 func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
+	opt, err := parseStoragePairDelete(pairs...)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	// Key ends with "/" is a dir, oss will delete it even if there are keys under it.
+	if strings.HasSuffix(rp, "/") {
+		err = prefix.CheckDirEmpty(opt.Context, rp, s.listKeys)
+		if err != nil {
+			return types.NewError("Delete", s, path, pairs, err)
+		}
+	}
+
+	options := make([]oss.Option, 0)
+	if opt.HasVersionID {
+		options = append(options, oss.VersionId(opt.VersionID))
+	}
+
+	err = s.bucket.DeleteObject(rp, options...)
+	if err != nil {
+		err = handleOssError(err)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	return nil
+}
+
+// GetTags implements Storager.GetTags
+func (s *Storage) GetTags(path string, pairs ...*types.Pair) (tags map[string]string, err error) {
+	opt, err := parseStoragePairGetTags(pairs...)
+	if err != nil {
+		return nil, types.NewError("GetTags", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return nil, types.NewError("GetTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	output, err := s.bucket.GetObjectTagging(rp)
+	if err != nil {
+		err = handleOssError(err)
+		return nil, types.NewError("GetTags", s, path, pairs, err)
+	}
+
+	tags = make(map[string]string, len(output.Tags))
+	for _, v := range output.Tags {
+		tags[v.Key] = v.Value
+	}
+	return tags, nil
+}
+
+// SetTags implements Storager.SetTags
+func (s *Storage) SetTags(path string, tags map[string]string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairSetTags(pairs...)
+	if err != nil {
+		return types.NewError("SetTags", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("SetTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	err = s.bucket.PutObjectTagging(rp, parseTagging(tags))
+	if err != nil {
+		err = handleOssError(err)
+		return types.NewError("SetTags", s, path, pairs, err)
+	}
+	return nil
+}
+
+// DeleteTags implements Storager.DeleteTags
+func (s *Storage) DeleteTags(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteTags(pairs...)
+	if err != nil {
+		return types.NewError("DeleteTags", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("DeleteTags", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
-	err = s.bucket.DeleteObject(rp)
+	err = s.bucket.DeleteObjectTagging(rp)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleOssError(err)
+		return types.NewError("DeleteTags", s, path, pairs, err)
 	}
 	return nil
 }
+
+// ListVersions implements Storager.ListVersions
+func (s *Storage) ListVersions(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairListVersions(pairs...)
+	if err != nil {
+		return types.NewError("ListVersions", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	keyMarker, versionIDMarker := "", ""
+	for {
+		if err = opt.Context.Err(); err != nil {
+			return types.NewError("ListVersions", s, path, pairs, err)
+		}
+
+		output, err := s.bucket.ListObjectVersions(
+			oss.Prefix(rp),
+			oss.KeyMarker(keyMarker),
+			oss.VersionIdMarker(versionIDMarker),
+			oss.MaxKeys(1000),
+		)
+		if err != nil {
+			err = handleOssError(err)
+			return types.NewError("ListVersions", s, path, pairs, err)
+		}
+
+		for _, v := range output.ObjectVersions {
+			// Versions are sorted by key, so versions of rp always come before other keys under prefix rp.
+			if v.Key != rp {
+				return nil
+			}
+
+			o := &types.Object{
+				ID:         v.Key,
+				Name:       path,
+				Type:       types.ObjectTypeFile,
+				Size:       v.Size,
+				UpdatedAt:  v.LastModified,
+				ObjectMeta: metadata.NewObjectMeta(),
+			}
+			o.SetVersionID(v.VersionId)
+			o.SetETag(v.ETag)
+
+			storageClass, err := formatStorageClass(v.StorageClass)
+			if err != nil {
+				return types.NewError("ListVersions", s, path, pairs, err)
+			}
+			o.SetStorageClass(storageClass)
+
+			opt.FileFunc(o)
+		}
+
+		if !output.IsTruncated {
+			return nil
+		}
+		keyMarker, versionIDMarker = output.NextKeyMarker, output.NextVersionIdMarker
+	}
+}
+
+// DeleteAll implements Storager.DeleteAll
+func (s *Storage) DeleteAll(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteAll(pairs...)
+	if err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+	if err = types.CheckNotRoot(path); err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+
+	err = prefix.DeleteAll(opt.Context, s.getAbsPath(path), s.listKeys, s.getRelPath, s.DeleteBatchWithContext)
+	if err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+	return nil
+}
+
+// DeleteBatch implements Storager.DeleteBatch
+func (s *Storage) DeleteBatch(paths []string, pairs ...*types.Pair) (results []types.DeleteResult, err error) {
+	opt, err := parseStoragePairDeleteBatch(pairs...)
+	if err != nil {
+		return nil, types.NewError("DeleteBatch", s, "", pairs, err)
+	}
+
+	results = make([]types.DeleteResult, len(paths))
+	for k, v := range paths {
+		results[k].Path = v
+	}
+
+	for start := 0; start < len(paths); start += deleteBatchLimit {
+		// oss sdk doesn't support context, check it before every request.
+		if err = opt.Context.Err(); err != nil {
+			return nil, types.NewError("DeleteBatch", s, "", pairs, err)
+		}
+
+		end := start + deleteBatchLimit
+		if end > len(paths) {
+			end = len(paths)
+		}
+
+		seen := make(map[string]bool)
+		keys := make([]string, 0, end-start)
+		for i := start; i < end; i++ {
+			rp := s.getAbsPath(paths[i])
+			if !seen[rp] {
+				keys = append(keys, rp)
+			}
+			seen[rp] = true
+		}
+
+		// oss doesn't return failed objects, so we need to check deleted objects instead.
+		output, err := s.bucket.DeleteObjects(keys)
+		if err != nil {
+			err = handleOssError(err)
+			for i := start; i < end; i++ {
+				results[i].Err = types.NewError("DeleteBatch", s, paths[i], pairs, err)
+			}
+			continue
+		}
+
+		deleted := make(map[string]bool, len(output.DeletedObjects))
+		for _, v := range output.DeletedObjects {
+			deleted[v] = true
+		}
+		for i := start; i < end; i++ {
+			if !deleted[s.getAbsPath(paths[i])] {
+				results[i].Err = types.NewError("DeleteBatch", s, paths[i], pairs, types.ErrUnhandledError)
+			}
+		}
+	}
+	return results, nil
+}
+
+// GetLifecycle implements Storager.GetLifecycle
+func (s *Storage) GetLifecycle(pairs ...*types.Pair) (rules []types.LifecycleRule, err error) {
+	opt, err := parseStoragePairGetLifecycle(pairs...)
+	if err != nil {
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+
+	output, err := s.bucket.Client.GetBucketLifecycle(s.name)
+	if e, ok := err.(oss.ServiceError); ok && e.Code == "NoSuchLifecycle" {
+		return []types.LifecycleRule{}, nil
+	}
+	if err != nil {
+		err = handleOssError(err)
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+
+	rules = make([]types.LifecycleRule, 0, len(output.Rules))
+	for _, v := range output.Rules {
+		rule, err := formatLifecycleRule(v)
+		if err != nil {
+			return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SetLifecycle implements Storager.SetLifecycle
+func (s *Storage) SetLifecycle(rules []types.LifecycleRule, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairSetLifecycle(pairs...)
+	if err != nil {
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+
+	// oss requires at least one rule in lifecycle configuration.
+	if len(rules) == 0 {
+		return s.DeleteLifecycleWithContext(opt.Context, pairs...)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+
+	input := make([]oss.LifecycleRule, 0, len(rules))
+	for _, v := range rules {
+		rule, err := parseLifecycleRule(v)
+		if err != nil {
+			return types.NewError("SetLifecycle", s, "", pairs, err)
+		}
+		input = append(input, rule)
+	}
+
+	err = s.bucket.Client.SetBucketLifecycle(s.name, input)
+	if err != nil {
+		err = handleOssError(err)
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+	return nil
+}
+
+// DeleteLifecycle implements Storager.DeleteLifecycle
+func (s *Storage) DeleteLifecycle(pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteLifecycle(pairs...)
+	if err != nil {
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+
+	err = s.bucket.Client.DeleteBucketLifecycle(s.name)
+	if err != nil {
+		err = handleOssError(err)
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+	return nil
+}
+
+// Restore implements Storager.Restore
+//
+// oss SDK doesn't support restore request body yet, so Archive objects will be restored for 1 day with default tier.
+func (s *Storage) Restore(path string, days int, tier storageclass.RestoreTier, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairRestore(pairs...)
+	if err != nil {
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	err = s.bucket.RestoreObject(rp)
+	if e, ok := err.(oss.ServiceError); ok && e.Code == "RestoreAlreadyInProgress" {
+		return nil
+	}
+	if err != nil {
+		err = handleOssError(err)
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+	return nil
+}
+
+// Transit implements Storager.Transit
+func (s *Storage) Transit(path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairTransit(pairs...)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	class, err := parseStorageClass(storageClass)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	// CopyObject has a size limit, so we need to stat it first.
+	output, err := s.bucket.GetObjectDetailedMeta(rp)
+	if err != nil {
+		err = handleOssError(err)
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+	if output.Get(storageClassHeader) == class {
+		return nil
+	}
+	size, err := strconv.ParseInt(output.Get(oss.HTTPHeaderContentLength), 10, 64)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+	if size > copyObjectSizeLimit {
+		err = fmt.Errorf("%w: object size %d exceeds copy limit %d", types.ErrNotSupported, size, int64(copyObjectSizeLimit))
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	_, err = s.bucket.CopyObject(rp, rp, oss.ObjectStorageClass(oss.StorageClassType(class)))
+	if err != nil {
+		err = handleOssError(err)
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+	return nil
+}
+
+// InitAppend implements Storager.InitAppend
+func (s *Storage) InitAppend(path string, pairs ...*types.Pair) (offset int64, err error) {
+	opt, err := parseStoragePairInitAppend(pairs...)
+	if err != nil {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	output, err := s.bucket.GetObjectDetailedMeta(rp)
+	if err == nil {
+		if output.Get(objectTypeHeader) != objectTypeAppendable {
+			err = fmt.Errorf("%w: object is not appendable", types.ErrObjectAlreadyExist)
+			return 0, types.NewError("InitAppend", s, path, pairs, err)
+		}
+		offset, err = strconv.ParseInt(output.Get(oss.HTTPHeaderOssNextAppendPosition), 10, 64)
+		if err != nil {
+			return 0, types.NewError("InitAppend", s, path, pairs, err)
+		}
+		return offset, nil
+	}
+	if err = handleOssError(err); !errors.Is(err, types.ErrObjectNotExist) {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+
+	// Appending empty content at position 0 will create an empty appendable object.
+	_, err = s.bucket.AppendObject(rp, strings.NewReader(""), 0)
+	if err != nil {
+		err = handleOssError(err)
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+	return 0, nil
+}
+
+// WriteAppend implements Storager.WriteAppend
+//
+// Data will be split into blocks of appendBlockSize.
+func (s *Storage) WriteAppend(path string, offset int64, r io.Reader, pairs ...*types.Pair) (next int64, err error) {
+	opt, err := parseStoragePairWriteAppend(pairs...)
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	next = offset
+	err = iowrap.ReadBlocks(r, appendBlockSize, func(block []byte) error {
+		if err := opt.Context.Err(); err != nil {
+			return err
+		}
+		n, err := s.bucket.AppendObject(rp, bytes.NewReader(block), next)
+		if err != nil {
+			return handleOssError(err)
+		}
+		next = n
+		return nil
+	})
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, err)
+	}
+	return next, nil
+}
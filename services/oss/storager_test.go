@@ -0,0 +1,150 @@
+package oss
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+// newTestStorage will create a Storage which sends requests to endpoint.
+func newTestStorage(t *testing.T, endpoint string) *Storage {
+	client, err := oss.New(endpoint, "access_key", "secret_key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket, err := client.Bucket("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newStorage(bucket)
+	s.name = "test"
+	return s
+}
+
+// writeOssError will write an oss error response.
+func writeOssError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte("<Error><Code>" + code + "</Code><Message>" + code + "</Message></Error>"))
+}
+
+func TestStorage_WriteIfNoneMatch(t *testing.T) {
+	exist := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/test/object", r.URL.Path)
+		assert.Equal(t, "true", r.Header.Get(forbidOverwriteHeader))
+		assert.Empty(t, r.Header.Get(oss.HTTPHeaderOssMetaPrefix+forbidOverwriteHeader))
+
+		if exist {
+			writeOssError(w, http.StatusConflict, "FileAlreadyExists")
+			return
+		}
+		exist = true
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	err := s.Write("object", strings.NewReader("content"), pairs.WithSize(7), pairs.WithIfNoneMatch("*"))
+	assert.NoError(t, err)
+
+	err = s.Write("object", strings.NewReader("content"), pairs.WithSize(7), pairs.WithIfNoneMatch("*"))
+	assert.True(t, errors.Is(err, types.ErrPreconditionFailed))
+
+	err = s.Write("object", strings.NewReader("content"), pairs.WithSize(7), pairs.WithIfMatch("etag"))
+	assert.True(t, errors.Is(err, types.ErrNotSupported))
+}
+
+func TestStorage_WriteAppend(t *testing.T) {
+	var content []byte
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/test/object", r.URL.Path)
+		requests++
+
+		position, err := strconv.ParseInt(r.URL.Query().Get("position"), 10, 64)
+		assert.NoError(t, err)
+		if position != int64(len(content)) {
+			writeOssError(w, http.StatusConflict, "PositionNotEqualToLength")
+			return
+		}
+
+		data, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		content = append(content, data...)
+		w.Header().Set(oss.HTTPHeaderOssNextAppendPosition, strconv.Itoa(len(content)))
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	data := bytes.Repeat([]byte("a"), appendBlockSize+1)
+	next, err := s.WriteAppend("object", 0, bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), next)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, data, content)
+
+	_, err = s.WriteAppend("object", 0, strings.NewReader("content"))
+	assert.True(t, errors.Is(err, types.ErrPreconditionFailed))
+	assert.Equal(t, len(data), len(content))
+}
+
+func TestStorage_Transit(t *testing.T) {
+	cases := []struct {
+		name   string
+		class  string
+		size   int64
+		copied bool
+		err    error
+	}{
+		{"transit", storageClassStandard, 1024, true, nil},
+		{"already in class", storageClassArchive, 1024, false, nil},
+		{"too large", storageClassStandard, copyObjectSizeLimit + 1, false, types.ErrNotSupported},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			copied := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/test/object", r.URL.Path)
+
+				switch r.Method {
+				case http.MethodHead:
+					w.Header().Set(storageClassHeader, tt.class)
+					w.Header().Set("Content-Length", strconv.FormatInt(tt.size, 10))
+				case http.MethodPut:
+					copied = true
+					assert.Equal(t, storageClassArchive, r.Header.Get(storageClassHeader))
+					_, _ = w.Write([]byte("<CopyObjectResult></CopyObjectResult>"))
+				}
+			}))
+			defer server.Close()
+
+			s := newTestStorage(t, server.URL)
+
+			err := s.Transit("object", storageclass.Cold)
+			assert.Equal(t, tt.copied, copied)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+		})
+	}
+}
@@ -1,12 +1,74 @@
 package oss
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
 	"strings"
+	"sync"
 
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"github.com/Xuanwo/storage/pkg/checksum"
+	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/metadata"
 )
 
+// credentialsProvider implements oss.CredentialsProvider, credential will be refreshed while expired.
+type credentialsProvider struct {
+	provider credential.Provider
+
+	value credential.Value
+	lock  sync.Mutex
+}
+
+func newCredentialsProvider(p credential.Provider, v credential.Value) *credentialsProvider {
+	return &credentialsProvider{
+		provider: p,
+		value:    v,
+	}
+}
+
+// GetCredentials implements oss.CredentialsProvider
+func (p *credentialsProvider) GetCredentials() oss.Credentials {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.provider.IsExpired() {
+		v, err := p.provider.Retrieve()
+		// oss.CredentialsProvider can't return error, keep using current value
+		// and let service return the auth error if refresh failed.
+		if err == nil && (v.Protocol == credential.ProtocolHmac || v.Protocol == credential.ProtocolSTS) {
+			p.value = v
+		}
+	}
+	return credentials(p.value)
+}
+
+// credentials implements oss.Credentials
+type credentials credential.Value
+
+func (c credentials) GetAccessKeyID() string {
+	return c.Args[0]
+}
+
+func (c credentials) GetAccessKeySecret() string {
+	return c.Args[1]
+}
+
+func (c credentials) GetSecurityToken() string {
+	if c.Protocol != credential.ProtocolSTS {
+		return ""
+	}
+	return c.Args[2]
+}
+
 func (s *Storage) getAbsPath(path string) string {
 	return strings.TrimPrefix(s.workDir+"/"+path, "/")
 }
@@ -15,16 +77,131 @@ func (s *Storage) getRelPath(path string) string {
 	return strings.TrimPrefix(path, s.workDir+"/")
 }
 
+// listKeys implements prefix.ListFunc
+func (s *Storage) listKeys(ctx context.Context, prefix, marker string, limit int) (keys []string, next string, err error) {
+	// oss sdk doesn't support context, check it before request.
+	if err = ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	output, err := s.bucket.ListObjects(oss.Prefix(prefix), oss.Marker(marker), oss.MaxKeys(limit))
+	if err != nil {
+		return nil, "", handleOssError(err)
+	}
+
+	keys = make([]string, 0, len(output.Objects))
+	for _, v := range output.Objects {
+		keys = append(keys, v.Key)
+	}
+	if output.IsTruncated {
+		next = output.NextMarker
+	}
+	return keys, next, nil
+}
+
+// setObjectChecksum will set object's checksum from oss response header.
+func setObjectChecksum(m metadata.ObjectMeta, header http.Header) {
+	// ETag of KMS encrypted object is not md5 of content.
+	if header.Get(oss.HTTPHeaderOssServerSideEncryption) != serverSideEncryptionKMS {
+		checksum.FromETag(m, header.Get("ETag"))
+	}
+
+	// Content-MD5 is base64 encoded, and only returned while object uploaded with it.
+	if v := header.Get("Content-MD5"); v != "" {
+		md5, err := base64.StdEncoding.DecodeString(v)
+		if err == nil {
+			m.SetContentMD5(checksum.FormatBytes(md5))
+		}
+	}
+}
+
 const (
 	// ref: https://www.alibabacloud.com/help/doc-detail/31984.htm
 	storageClassHeader = "x-oss-storage-class"
+	// serverSideEncryptionKMS is the value of x-oss-server-side-encryption for KMS encrypted objects.
+	serverSideEncryptionKMS = "KMS"
+
+	// ref: https://www.alibabacloud.com/help/doc-detail/52930.htm
+	restoreHeader = "x-oss-restore"
+
+	// ref: https://www.alibabacloud.com/help/doc-detail/31978.htm
+	forbidOverwriteHeader = "x-oss-forbid-overwrite"
+
+	// ref: https://www.alibabacloud.com/help/doc-detail/31982.htm
+	objectTypeHeader     = "x-oss-object-type"
+	objectTypeAppendable = "Appendable"
+	// appendBlockSize is the max size of data appended in one request.
+	appendBlockSize = 4 * 1024 * 1024
 
 	// ref: https://www.alibabacloud.com/help/doc-detail/51374.htm
 	storageClassStandard = "STANDARD"
 	storageClassIA       = "IA"
 	storageClassArchive  = "Archive"
+
+	// ref: https://www.alibabacloud.com/help/doc-detail/31964.htm
+	lifecycleStatusEnabled  = "Enabled"
+	lifecycleStatusDisabled = "Disabled"
 )
 
+// withHeader will create an oss.Option which sets header key to value.
+//
+// oss SDK doesn't export a generic header option and oss.Option's argument type is unexported, so
+// we set the header via oss.Meta and rename it.
+func withHeader(key, value string) oss.Option {
+	meta := oss.Meta(key, value)
+	metaKey := reflect.ValueOf(oss.HTTPHeaderOssMetaPrefix + key)
+
+	fn := reflect.MakeFunc(reflect.TypeOf(meta), func(args []reflect.Value) []reflect.Value {
+		out := reflect.ValueOf(meta).Call(args)
+
+		m := args[0]
+		m.SetMapIndex(reflect.ValueOf(key), m.MapIndex(metaKey))
+		m.SetMapIndex(metaKey, reflect.Value{})
+		return out
+	})
+	return fn.Interface().(oss.Option)
+}
+
+func parseTagging(tags map[string]string) oss.Tagging {
+	tagging := oss.Tagging{Tags: make([]oss.Tag, 0, len(tags))}
+	for k, v := range tags {
+		tagging.Tags = append(tagging.Tags, oss.Tag{Key: k, Value: v})
+	}
+	return tagging
+}
+
+// formatUserMetadata will get user metadata from oss response headers, keys will be converted into lower case.
+func formatUserMetadata(header http.Header) map[string]string {
+	m := make(map[string]string)
+	for k, v := range header {
+		if !strings.HasPrefix(k, oss.HTTPHeaderOssMetaPrefix) || len(v) == 0 {
+			continue
+		}
+		m[strings.ToLower(strings.TrimPrefix(k, oss.HTTPHeaderOssMetaPrefix))] = v[0]
+	}
+	return m
+}
+
+// setObjectHeaders will set object's http content headers from oss response headers.
+func setObjectHeaders(m metadata.ObjectMeta, header http.Header) {
+	if v := header.Get(oss.HTTPHeaderContentType); v != "" {
+		m.SetContentType(v)
+	}
+	if v := header.Get(oss.HTTPHeaderContentEncoding); v != "" {
+		m.SetContentEncoding(v)
+	}
+	if v := header.Get(oss.HTTPHeaderContentDisposition); v != "" {
+		m.SetContentDisposition(v)
+	}
+	if v := header.Get(oss.HTTPHeaderCacheControl); v != "" {
+		m.SetCacheControl(v)
+	}
+	// Expires could be an invalid date like "0", which is ignored.
+	if expires, err := http.ParseTime(header.Get(oss.HTTPHeaderExpires)); err == nil {
+		m.SetExpires(expires)
+	}
+}
+
 // parseStorageClass will parse storageclass.Type into service independent storage class type.
 func parseStorageClass(in storageclass.Type) (string, error) {
 	switch in {
@@ -52,3 +229,195 @@ func formatStorageClass(in string) (storageclass.Type, error) {
 		return "", types.ErrStorageClassNotSupported
 	}
 }
+
+// credentialFile is the config file used by aliyun cli, which is "~/.aliyun/config.json" by default.
+//
+// ref: https://github.com/aliyun/aliyun-cli#configure
+type credentialFile struct {
+	Current  string `json:"current"`
+	Profiles []struct {
+		Name            string `json:"name"`
+		Mode            string `json:"mode"`
+		AccessKeyID     string `json:"access_key_id"`
+		AccessKeySecret string `json:"access_key_secret"`
+		StsToken        string `json:"sts_token"`
+	} `json:"profiles"`
+}
+
+// loadCredentialFile will load hmac or sts credential from aliyun cli's config file.
+//
+// Current profile in config file will be used if profile is empty.
+func loadCredentialFile(path, profile string) (credential.Value, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return credential.Value{}, err
+	}
+	f := credentialFile{}
+	err = json.Unmarshal(content, &f)
+	if err != nil {
+		return credential.Value{}, fmt.Errorf("%w: %v", credential.ErrInvalidConfig, err)
+	}
+	if profile == "" {
+		profile = f.Current
+	}
+
+	for _, v := range f.Profiles {
+		if v.Name != profile {
+			continue
+		}
+		switch v.Mode {
+		case "AK", "":
+			return credential.Value{
+				Protocol: credential.ProtocolHmac,
+				Args:     []string{v.AccessKeyID, v.AccessKeySecret},
+			}, nil
+		case "StsToken":
+			return credential.Value{
+				Protocol: credential.ProtocolSTS,
+				Args:     []string{v.AccessKeyID, v.AccessKeySecret, v.StsToken},
+			}, nil
+		default:
+			return credential.Value{}, credential.ErrUnsupportedProtocol
+		}
+	}
+	return credential.Value{}, credential.ErrCredentialNotFound
+}
+
+// handleOssError will map oss error codes and http status codes onto sentinel errors.
+//
+// ref: https://www.alibabacloud.com/help/doc-detail/32005.htm
+func handleOssError(err error) error {
+	if err == nil {
+		panic("error must not be nil")
+	}
+	// Context errors will be returned as is, so caller could check them via errors.Is.
+	if types.IsContextError(err) {
+		return err
+	}
+
+	var code int
+	switch e := err.(type) {
+	case oss.ServiceError:
+		switch e.Code {
+		case "AccessDenied":
+			return fmt.Errorf("%w: %v", types.ErrPermissionDenied, err)
+		case "NoSuchKey", "NoSuchBucket", "NoSuchUpload":
+			return fmt.Errorf("%w: %v", types.ErrObjectNotExist, err)
+		case "InvalidAccessKeyId", "SignatureDoesNotMatch", "RequestTimeTooSkewed":
+			return fmt.Errorf("%w: %v", types.ErrConfigIncorrect, err)
+		case "BucketAlreadyExists", "FileAlreadyExists", "ObjectNotAppendable":
+			return fmt.Errorf("%w: %v", types.ErrObjectAlreadyExist, err)
+		case "BucketNotEmpty":
+			return fmt.Errorf("%w: %v", types.ErrDirNotEmpty, err)
+		case "PreconditionFailed", "PositionNotEqualToLength":
+			return fmt.Errorf("%w: %v", types.ErrPreconditionFailed, err)
+		case "InvalidObjectState":
+			return fmt.Errorf("%w: %v", types.ErrObjectArchived, err)
+		case "TooManyBuckets", "QuotaExceeded":
+			return fmt.Errorf("%w: %v", types.ErrQuotaExceeded, err)
+		case "RequestTimeout":
+			return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+		case "InternalError", "ServiceUnavailable":
+			return fmt.Errorf("%w: %v", types.ErrServiceUnavailable, err)
+		case "NotImplemented", "MethodNotAllowed":
+			return fmt.Errorf("%w: %v", types.ErrNotSupported, err)
+		}
+		code = e.StatusCode
+	case oss.UnexpectedStatusCodeError:
+		code = e.Got()
+	default:
+		if types.IsTimeout(err) {
+			return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+	}
+
+	if se := types.ErrFromStatusCode(code); se != nil {
+		return fmt.Errorf("%w: %v", se, err)
+	}
+	return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+}
+
+// parseLifecycleRule will parse types.LifecycleRule into oss lifecycle rule.
+func parseLifecycleRule(in types.LifecycleRule) (oss.LifecycleRule, error) {
+	rule := oss.LifecycleRule{
+		ID:     in.ID,
+		Prefix: in.Prefix,
+		Status: lifecycleStatusDisabled,
+	}
+	if in.Enabled {
+		rule.Status = lifecycleStatusEnabled
+	}
+	if in.ExpirationDays > 0 {
+		rule.Expiration = &oss.LifecycleExpiration{Days: in.ExpirationDays}
+	}
+	for _, v := range in.Transitions {
+		storageClass, err := parseStorageClass(v.StorageClass)
+		if err != nil {
+			return rule, err
+		}
+		rule.Transitions = append(rule.Transitions, oss.LifecycleTransition{
+			Days:         v.Days,
+			StorageClass: oss.StorageClassType(storageClass),
+		})
+	}
+	if in.AbortIncompleteSegmentDays > 0 {
+		rule.AbortMultipartUpload = &oss.LifecycleAbortMultipartUpload{Days: in.AbortIncompleteSegmentDays}
+	}
+	return rule, nil
+}
+
+// formatLifecycleRule will format oss lifecycle rule into types.LifecycleRule.
+//
+// Rules filtered by tags, expired by date or acting on noncurrent versions can't be represented.
+func formatLifecycleRule(in oss.LifecycleRule) (rule types.LifecycleRule, err error) {
+	if len(in.Tags) > 0 {
+		return rule, fmt.Errorf("%w: rule %s is filtered by tags", types.ErrNotSupported, in.ID)
+	}
+	if in.NonVersionExpiration != nil || in.NonVersionTransition != nil {
+		return rule, fmt.Errorf("%w: rule %s has noncurrent version actions", types.ErrNotSupported, in.ID)
+	}
+
+	rule.ID = in.ID
+	rule.Prefix = in.Prefix
+	rule.Enabled = in.Status == lifecycleStatusEnabled
+
+	if v := in.Expiration; v != nil {
+		if v.Days == 0 {
+			return rule, fmt.Errorf("%w: rule %s is not expired by days", types.ErrNotSupported, in.ID)
+		}
+		rule.ExpirationDays = v.Days
+	}
+	for _, v := range in.Transitions {
+		if v.Days == 0 {
+			return rule, fmt.Errorf("%w: rule %s is not transited by days", types.ErrNotSupported, in.ID)
+		}
+		storageClass, err := formatStorageClass(string(v.StorageClass))
+		if err != nil {
+			return rule, err
+		}
+		rule.Transitions = append(rule.Transitions, types.LifecycleTransition{
+			Days:         v.Days,
+			StorageClass: storageClass,
+		})
+	}
+	if v := in.AbortMultipartUpload; v != nil {
+		if v.Days == 0 {
+			return rule, fmt.Errorf("%w: rule %s doesn't abort segments by days", types.ErrNotSupported, in.ID)
+		}
+		rule.AbortIncompleteSegmentDays = v.Days
+	}
+	return rule, nil
+}
+
+// formatRestoreStatus will format x-oss-restore header of an Archive object into storageclass.RestoreStatus.
+func formatRestoreStatus(in string) storageclass.RestoreStatus {
+	switch {
+	case in == "":
+		return storageclass.RestoreStatusArchived
+	case strings.Contains(in, `ongoing-request="true"`):
+		return storageclass.RestoreStatusRestoring
+	default:
+		return storageclass.RestoreStatusRestored
+	}
+}
@@ -0,0 +1,128 @@
+package oss
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/metadata"
+)
+
+func TestLoadCredentialFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oss")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(path, []byte(`{
+  "current": "default",
+  "profiles": [
+    {"name": "default", "mode": "AK", "access_key_id": "ak", "access_key_secret": "sk"},
+    {"name": "sts", "mode": "StsToken", "access_key_id": "ak", "access_key_secret": "sk", "sts_token": "token"},
+    {"name": "ram", "mode": "RamRoleArn"}
+  ]
+}`), 0600)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		profile string
+		value   credential.Value
+		err     error
+	}{
+		{"current", "", credential.Value{Protocol: credential.ProtocolHmac, Args: []string{"ak", "sk"}}, nil},
+		{"sts", "sts", credential.Value{Protocol: credential.ProtocolSTS, Args: []string{"ak", "sk", "token"}}, nil},
+		{"unsupported mode", "ram", credential.Value{}, credential.ErrUnsupportedProtocol},
+		{"not found", "not-exist", credential.Value{}, credential.ErrCredentialNotFound},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := loadCredentialFile(path, tt.profile)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+			assert.Equal(t, tt.value, v)
+		})
+	}
+}
+
+func TestHandleOssError(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    error
+		expected error
+	}{
+		{"non-oss error", errors.New("test"), types.ErrUnhandledError},
+		{"no such key", oss.ServiceError{Code: "NoSuchKey", StatusCode: 404}, types.ErrObjectNotExist},
+		{"bucket not empty", oss.ServiceError{Code: "BucketNotEmpty", StatusCode: 409}, types.ErrDirNotEmpty},
+		{"object archived", oss.ServiceError{Code: "InvalidObjectState", StatusCode: 403}, types.ErrObjectArchived},
+		{"append position mismatch", oss.ServiceError{Code: "PositionNotEqualToLength", StatusCode: 409}, types.ErrPreconditionFailed},
+		{"not appendable", oss.ServiceError{Code: "ObjectNotAppendable", StatusCode: 409}, types.ErrObjectAlreadyExist},
+		{"other conflict", oss.ServiceError{Code: "xxxx", StatusCode: 409}, types.ErrUnhandledError},
+		{"fallback to status code", oss.ServiceError{Code: "xxxx", StatusCode: 429}, types.ErrRateLimited},
+		{"unexpected status code", oss.UnexpectedStatusCodeError{}, types.ErrUnhandledError},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, errors.Is(handleOssError(tt.input), tt.expected))
+		})
+	}
+}
+
+func TestFormatUserMetadata(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Oss-Meta-Job-Id", "123")
+	header.Set("Content-Type", "text/plain")
+
+	assert.Equal(t, map[string]string{"job-id": "123"}, formatUserMetadata(header))
+}
+
+func TestSetObjectHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set(oss.HTTPHeaderContentDisposition, "attachment")
+	header.Set(oss.HTTPHeaderContentEncoding, "gzip")
+
+	m := metadata.NewObjectMeta()
+	setObjectHeaders(m, header)
+	assert.Equal(t, "attachment", m.MustGetContentDisposition())
+	assert.Equal(t, "gzip", m.MustGetContentEncoding())
+	_, ok := m.GetExpires()
+	assert.False(t, ok)
+}
+
+func TestLifecycleRule(t *testing.T) {
+	rule := types.LifecycleRule{
+		ID:                         "archive",
+		Prefix:                     "logs/",
+		ExpirationDays:             365,
+		Transitions:                []types.LifecycleTransition{{Days: 90, StorageClass: storageclass.Cold}},
+		AbortIncompleteSegmentDays: 7,
+	}
+
+	in, err := parseLifecycleRule(rule)
+	assert.NoError(t, err)
+	assert.Equal(t, lifecycleStatusDisabled, in.Status)
+
+	out, err := formatLifecycleRule(in)
+	assert.NoError(t, err)
+	assert.Equal(t, rule, out)
+
+	_, err = formatLifecycleRule(oss.LifecycleRule{
+		Status:     lifecycleStatusEnabled,
+		Expiration: &oss.LifecycleExpiration{CreatedBeforeDate: "2020-01-01T00:00:00.000Z"},
+	})
+	assert.True(t, errors.Is(err, types.ErrNotSupported))
+}
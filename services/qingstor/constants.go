@@ -2,3 +2,6 @@ package qingstor
 
 // DirectoryContentType is the mime type that qingstor used for a directory.
 const DirectoryContentType = "application/x-directory"
+
+// userMetadataPrefix is the header prefix for user metadata, qingstor sdk requires it in metadata keys.
+const userMetadataPrefix = "x-qs-meta-"
@@ -8,6 +8,7 @@ import (
 	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/yunify/qingstor-sdk-go/v3/config"
 	iface "github.com/yunify/qingstor-sdk-go/v3/interface"
+	qssigner "github.com/yunify/qingstor-sdk-go/v3/request/signer"
 	"github.com/yunify/qingstor-sdk-go/v3/service"
 
 	"github.com/Xuanwo/storage"
@@ -24,8 +25,6 @@ type Service struct {
 
 // New will create a new qingstor service.
 func New(pairs ...*types.Pair) (s *Service, err error) {
-	const errorMessage = "%s New: %w"
-
 	s = &Service{
 		noRedirectClient: &http.Client{
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
@@ -36,24 +35,65 @@ func New(pairs ...*types.Pair) (s *Service, err error) {
 
 	opt, err := parseServicePairNew(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
-	credProtocol, cred := opt.Credential.Protocol(), opt.Credential.Value()
-	if credProtocol != credential.ProtocolHmac {
-		return nil, fmt.Errorf(errorMessage, s, credential.ErrUnsupportedProtocol)
-	}
-	cfg, err := config.New(cred[0], cred[1])
+	cred, err := opt.Credential.Retrieve()
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	var cfg *config.Config
+	switch cred.Protocol {
+	case credential.ProtocolHmac:
+		cfg, err = config.New(cred.Args[0], cred.Args[1])
+		if err != nil {
+			return nil, types.NewError("New", s, "", pairs, err)
+		}
+	case credential.ProtocolFile:
+		// Load qingstor sdk's yaml config file, which doesn't have profiles.
+		path, profile := cred.File()
+		if profile != "" {
+			return nil, types.NewError("New", s, "", pairs, credential.ErrInvalidConfig)
+		}
+		cfg, err = config.NewDefault()
+		if err != nil {
+			return nil, types.NewError("New", s, "", pairs, err)
+		}
+		err = cfg.LoadConfigFromFilePath(path)
+		if err != nil {
+			return nil, types.NewError("New", s, "", pairs, err)
+		}
+	default:
+		return nil, types.NewError("New", s, "", pairs, credential.ErrUnsupportedProtocol)
 	}
 	if opt.HasEndpoint {
 		ep := opt.Endpoint.Value()
+		// qingstor SDK only takes host, port and protocol, and signs requests without base path.
+		if err = ep.CheckHostOnly(); err != nil {
+			return nil, types.NewError("New", s, "", pairs, err)
+		}
 		cfg.Host = ep.Host
 		cfg.Port = ep.Port
 		cfg.Protocol = ep.Protocol
 	}
 
+	// Credential will be refreshed while expired, and requests will be signed with it in transport.
+	transport := cfg.Connection.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	cfg.Connection = &http.Client{
+		Transport: &credentialTransport{
+			provider:  opt.Credential,
+			transport: transport,
+			signer: qssigner.QingStorSigner{
+				AccessKeyID:     cfg.AccessKeyID,
+				SecretAccessKey: cfg.SecretAccessKey,
+			},
+		},
+		Timeout: cfg.Connection.Timeout,
+	}
+
 	s.config = cfg
 	s.service, _ = service.Init(cfg)
 	return
@@ -69,11 +109,9 @@ func (s *Service) String() string {
 
 // Create implements Servicer.Create
 func (s *Service) Create(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Create [%s]: %w"
-
 	opt, err := parseServicePairCreate(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 
 	// TODO: check bucket name here.
@@ -81,62 +119,56 @@ func (s *Service) Create(name string, pairs ...*types.Pair) (storage.Storager, e
 	bucket, err := s.service.Bucket(name, opt.Location)
 	if err != nil {
 		err = handleQingStorError(err)
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 
 	_, err = bucket.Put()
 	if err != nil {
 		err = handleQingStorError(err)
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 	return newStorage(bucket)
 }
 
 // Delete implements Servicer.Delete
 func (s *Service) Delete(name string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
-
 	opt, err := parseServicePairDelete(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 	bucket, err := s.get(name, opt.Location)
 	if err != nil {
 		err = handleQingStorError(err)
-		return fmt.Errorf(errorMessage, s, name, err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 	_, err = bucket.Delete()
 	if err != nil {
 		err = handleQingStorError(err)
-		return fmt.Errorf(errorMessage, s, name, err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 	return nil
 }
 
 // Get implements Servicer.Get
 func (s *Service) Get(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Get [%s]: %w"
-
 	opt, err := parseServicePairGet(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Get", s, name, pairs, err)
 	}
 
 	bucket, err := s.get(name, opt.Location)
 	if err != nil {
 		err = handleQingStorError(err)
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Get", s, name, pairs, err)
 	}
 	return newStorage(bucket)
 }
 
 // List implements Servicer.List
 func (s *Service) List(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List: %w"
-
 	opt, err := parseServicePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("List", s, "", pairs, err)
 	}
 
 	input := &service.ListBucketsInput{}
@@ -148,18 +180,18 @@ func (s *Service) List(pairs ...*types.Pair) (err error) {
 	output, err := s.service.ListBuckets(input)
 	if err != nil {
 		err = handleQingStorError(err)
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("List", s, "", pairs, err)
 	}
 
 	for _, v := range output.Buckets {
 		store, err := s.get(*v.Name, *v.Location)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, err)
+			return types.NewError("List", s, "", pairs, err)
 		}
 		if opt.HasStoragerFunc {
 			c, err := newStorage(store)
 			if err != nil {
-				return fmt.Errorf(errorMessage, s, err)
+				return types.NewError("List", s, "", pairs, err)
 			}
 			opt.StoragerFunc(c)
 		}
@@ -168,11 +200,9 @@ func (s *Service) List(pairs ...*types.Pair) (err error) {
 }
 
 func (s *Service) get(name, location string) (*service.Bucket, error) {
-	const errorMessage = "%s get [%s]: %w"
-
 	if !IsBucketNameValid(name) {
 		err := handleQingStorError(ErrInvalidBucketName)
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("get", s, name, nil, err)
 	}
 
 	// TODO: add bucket name check here.
@@ -180,7 +210,7 @@ func (s *Service) get(name, location string) (*service.Bucket, error) {
 		bucket, err := s.service.Bucket(name, location)
 		if err != nil {
 			err = handleQingStorError(err)
-			return nil, fmt.Errorf(errorMessage, s, name, err)
+			return nil, types.NewError("get", s, name, nil, err)
 		}
 		return bucket, nil
 	}
@@ -190,11 +220,11 @@ func (s *Service) get(name, location string) (*service.Bucket, error) {
 	r, err := s.noRedirectClient.Head(url)
 	if err != nil {
 		err = handleQingStorError(err)
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("get", s, name, nil, err)
 	}
 	if r.StatusCode != http.StatusTemporaryRedirect {
 		err = fmt.Errorf("head status is %d instead of %d", r.StatusCode, http.StatusTemporaryRedirect)
-		return nil, fmt.Errorf(errorMessage, s, name, handleQingStorError(err))
+		return nil, types.NewError("get", s, name, nil, handleQingStorError(err))
 	}
 
 	// Example URL: https://bucket.zone.qingstor.com
@@ -202,7 +232,7 @@ func (s *Service) get(name, location string) (*service.Bucket, error) {
 	bucket, err := s.service.Bucket(name, location)
 	if err != nil {
 		err = handleQingStorError(err)
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("get", s, name, nil, err)
 	}
 	return bucket, nil
 }
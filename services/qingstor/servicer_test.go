@@ -60,6 +60,21 @@ func TestService_New(t *testing.T) {
 	assert.Equal(t, srv.config.Host, host)
 	assert.Equal(t, srv.config.Port, port)
 	assert.Equal(t, srv.config.Protocol, "http")
+
+	// Endpoint which qingstor SDK could not use.
+	for v, expected := range map[string]error{
+		"unix:/tmp/qingstor.sock":      endpoint.ErrUnsupportedProtocol,
+		"http://127.0.0.1:8080/prefix": endpoint.ErrInvalidConfig,
+	} {
+		ep, err := endpoint.Parse(v)
+		assert.NoError(t, err)
+
+		_, err = New(
+			pairs.WithCredential(credential.MustNewHmac(accessKey, secretKey)),
+			pairs.WithEndpoint(ep),
+		)
+		assert.True(t, errors.Is(err, expected), v)
+	}
 }
 
 func TestService_Get(t *testing.T) {
@@ -1,6 +1,7 @@
 package qingstor
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -9,13 +10,23 @@ import (
 	"github.com/pengsrc/go-shared/convert"
 	qsconfig "github.com/yunify/qingstor-sdk-go/v3/config"
 	iface "github.com/yunify/qingstor-sdk-go/v3/interface"
+	qserror "github.com/yunify/qingstor-sdk-go/v3/request/errors"
 	"github.com/yunify/qingstor-sdk-go/v3/service"
 
+	"github.com/Xuanwo/storage/pkg/checksum"
+	"github.com/Xuanwo/storage/pkg/iowrap"
+	"github.com/Xuanwo/storage/pkg/iterator"
+	"github.com/Xuanwo/storage/pkg/prefix"
 	"github.com/Xuanwo/storage/pkg/segment"
+	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/metadata"
+	ps "github.com/Xuanwo/storage/types/pairs"
 )
 
+// deleteBatchLimit is the max count of keys in one DeleteMultipleObjects request.
+const deleteBatchLimit = 1000
+
 // Storage is the qingstor object storage client.
 //
 //go:generate ../../internal/bin/service
@@ -26,7 +37,8 @@ type Storage struct {
 	properties *service.Properties
 
 	// options for this storager.
-	workDir string // workDir dir for all operation.
+	workDir      string            // workDir dir for all operation.
+	storageClass storageclass.Type // storageClass will be used as default for Write.
 
 	segments    map[string]*segment.Segment
 	segmentLock sync.RWMutex
@@ -45,17 +57,22 @@ func newStorage(bucket *service.Bucket) (*Storage, error) {
 
 // Init implements Storager.Init
 func (s *Storage) Init(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Init: %w"
-
 	opt, err := parseStoragePairInit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("Init", s, "", pairs, err)
 	}
 
 	if opt.HasWorkDir {
 		// TODO: we should validate workDir
 		s.workDir = strings.TrimLeft(opt.WorkDir, "/")
 	}
+	if opt.HasStorageClass {
+		// Storage class will be used as default for Write, validate it here to fail fast.
+		if _, err = parseStorageClass(opt.StorageClass); err != nil {
+			return types.NewError("Init", s, "", pairs, err)
+		}
+		s.storageClass = opt.StorageClass
+	}
 
 	return nil
 }
@@ -74,19 +91,18 @@ func (s *Storage) Metadata(pairs ...*types.Pair) (m metadata.StorageMeta, err er
 	m.Name = *s.properties.BucketName
 	m.WorkDir = s.workDir
 	m.SetLocation(*s.properties.Zone)
+	m.SetFlatList(true)
 	return m, nil
 }
 
 // Statistical implements Storager.Statistical
 func (s *Storage) Statistical(pairs ...*types.Pair) (m metadata.StorageStatistic, err error) {
-	const errorMessage = "%s Statistical: %w"
-
 	m = metadata.NewStorageStatistic()
 
 	output, err := s.bucket.GetStatistics()
 	if err != nil {
 		err = handleQingStorError(err)
-		return m, fmt.Errorf(errorMessage, s, err)
+		return m, types.NewError("Statistical", s, "", pairs, err)
 	}
 
 	if output.Size != nil {
@@ -100,16 +116,31 @@ func (s *Storage) Statistical(pairs ...*types.Pair) (m metadata.StorageStatistic
 
 // Stat implements Storager.Stat
 func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err error) {
-	const errorMessage = "%s Stat [%s]: %w"
+	opt, err := parseStoragePairStat(pairs...)
+	if err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
 
 	input := &service.HeadObjectInput{}
+	if opt.HasIfMatch {
+		input.IfMatch = &opt.IfMatch
+	}
+	if opt.HasIfNoneMatch {
+		input.IfNoneMatch = &opt.IfNoneMatch
+	}
+	if opt.HasIfModifiedSince {
+		input.IfModifiedSince = &opt.IfModifiedSince
+	}
+	if opt.HasIfUnmodifiedSince {
+		input.IfUnmodifiedSince = &opt.IfUnmodifiedSince
+	}
 
 	rp := s.getAbsPath(path)
 
 	output, err := s.bucket.HeadObject(rp, input)
 	if err != nil {
 		err = handleQingStorError(err)
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	// TODO: Add dir support.
@@ -128,11 +159,15 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 	}
 	if output.ETag != nil {
 		o.SetETag(service.StringValue(output.ETag))
+		// ETag of SSE-C encrypted object is not md5 of content.
+		if output.XQSEncryptionCustomerAlgorithm == nil {
+			checksum.FromETag(o.ObjectMeta, service.StringValue(output.ETag))
+		}
 	}
 
 	storageClass, err := formatStorageClass(service.StringValue(output.XQSStorageClass))
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 	o.SetStorageClass(storageClass)
 
@@ -141,22 +176,112 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 
 // Delete implements Storager.Delete
 func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
+	opt, err := parseStoragePairDelete(pairs...)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
+	// Key ends with "/" is a dir, qingstor will delete it even if there are keys under it.
+	if strings.HasSuffix(rp, "/") {
+		err = prefix.CheckDirEmpty(opt.Context, rp, s.listKeys)
+		if err != nil {
+			return types.NewError("Delete", s, path, pairs, err)
+		}
+	}
+
 	_, err = s.bucket.DeleteObject(rp)
 	if err != nil {
 		err = handleQingStorError(err)
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	return nil
+}
+
+// DeleteAll implements Storager.DeleteAll
+func (s *Storage) DeleteAll(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteAll(pairs...)
+	if err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+	if err = types.CheckNotRoot(path); err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+
+	err = prefix.DeleteAll(opt.Context, s.getAbsPath(path), s.listKeys, s.getRelPath, s.DeleteBatchWithContext)
+	if err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
 	}
 	return nil
 }
 
+// DeleteBatch implements Storager.DeleteBatch
+func (s *Storage) DeleteBatch(paths []string, pairs ...*types.Pair) (results []types.DeleteResult, err error) {
+	opt, err := parseStoragePairDeleteBatch(pairs...)
+	if err != nil {
+		return nil, types.NewError("DeleteBatch", s, "", pairs, err)
+	}
+
+	results = make([]types.DeleteResult, len(paths))
+	for k, v := range paths {
+		results[k].Path = v
+	}
+
+	for start := 0; start < len(paths); start += deleteBatchLimit {
+		// qingstor sdk doesn't support context, check it before every request.
+		if err = opt.Context.Err(); err != nil {
+			return nil, types.NewError("DeleteBatch", s, "", pairs, err)
+		}
+
+		end := start + deleteBatchLimit
+		if end > len(paths) {
+			end = len(paths)
+		}
+
+		// index maps object key to it's indexes in paths.
+		index := make(map[string][]int)
+		objects := make([]*service.KeyType, 0, end-start)
+		for i := start; i < end; i++ {
+			rp := s.getAbsPath(paths[i])
+			if _, ok := index[rp]; !ok {
+				objects = append(objects, &service.KeyType{Key: service.String(rp)})
+			}
+			index[rp] = append(index[rp], i)
+		}
+
+		output, err := s.bucket.DeleteMultipleObjects(&service.DeleteMultipleObjectsInput{
+			Objects: objects,
+			Quiet:   service.Bool(true),
+		})
+		if err != nil {
+			err = handleQingStorError(err)
+			for i := start; i < end; i++ {
+				results[i].Err = types.NewError("DeleteBatch", s, paths[i], pairs, err)
+			}
+			continue
+		}
+
+		// Only failed objects will be returned in quiet mode.
+		for _, v := range output.Errors {
+			err := handleQingStorError(&qserror.QingStorError{
+				Code:    service.StringValue(v.Code),
+				Message: service.StringValue(v.Message),
+			})
+			for _, i := range index[service.StringValue(v.Key)] {
+				results[i].Err = types.NewError("DeleteBatch", s, paths[i], pairs, err)
+			}
+		}
+	}
+	return results, nil
+}
+
 // Copy implements Storager.Copy
 func (s *Storage) Copy(src, dst string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Copy from [%s] to [%s]: %w"
-
 	rs := s.getAbsPath(src)
 	rd := s.getAbsPath(dst)
 
@@ -165,15 +290,13 @@ func (s *Storage) Copy(src, dst string, pairs ...*types.Pair) (err error) {
 	})
 	if err != nil {
 		err = handleQingStorError(err)
-		return fmt.Errorf(errorMessage, s, src, dst, err)
+		return types.NewErrorWithDst("Copy", s, src, dst, pairs, err)
 	}
 	return nil
 }
 
 // Move implements Storager.Move
 func (s *Storage) Move(src, dst string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Move from [%s] to [%s]: %w"
-
 	rs := s.getAbsPath(src)
 	rd := s.getAbsPath(dst)
 
@@ -182,18 +305,16 @@ func (s *Storage) Move(src, dst string, pairs ...*types.Pair) (err error) {
 	})
 	if err != nil {
 		err = handleQingStorError(err)
-		return fmt.Errorf(errorMessage, s, src, dst, err)
+		return types.NewErrorWithDst("Move", s, src, dst, pairs, err)
 	}
 	return nil
 }
 
 // Reach implements Storager.Reach
 func (s *Storage) Reach(path string, pairs ...*types.Pair) (url string, err error) {
-	const errorMessage = "%s Reach [%s]: %w"
-
 	opt, err := parseStoragePairReach(pairs...)
 	if err != nil {
-		return "", fmt.Errorf(errorMessage, s, path, err)
+		return "", types.NewError("Reach", s, path, pairs, err)
 	}
 
 	// FIXME: sdk should export GetObjectRequest as interface too?
@@ -204,11 +325,11 @@ func (s *Storage) Reach(path string, pairs ...*types.Pair) (url string, err erro
 	r, _, err := bucket.GetObjectRequest(rp, nil)
 	if err != nil {
 		err = handleQingStorError(err)
-		return "", fmt.Errorf(errorMessage, s, path, err)
+		return "", types.NewError("Reach", s, path, pairs, err)
 	}
 	if err = r.Build(); err != nil {
 		err = handleQingStorError(err)
-		return "", fmt.Errorf(errorMessage, s, path, err)
+		return "", types.NewError("Reach", s, path, pairs, err)
 	}
 
 	expire := 3600
@@ -217,129 +338,128 @@ func (s *Storage) Reach(path string, pairs ...*types.Pair) (url string, err erro
 	}
 	if err = r.SignQuery(expire); err != nil {
 		err = handleQingStorError(err)
-		return "", fmt.Errorf(errorMessage, s, path, err)
+		return "", types.NewError("Reach", s, path, pairs, err)
 	}
 	return r.HTTPRequest.URL.String(), nil
 }
 
 // List implements Storager.List
 func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List [%s]: %w"
-
 	opt, _ := parseStoragePairList(pairs...)
 
 	marker := ""
-	limit := 200
-
 	rp := s.getAbsPath(path)
 
-	var output *service.ListObjectsOutput
 	for {
-		output, err = s.bucket.ListObjects(&service.ListObjectsInput{
-			Limit:  &limit,
-			Marker: &marker,
-			Prefix: &rp,
-		})
+		objects, next, err := s.listObjects(opt.Context, rp, marker)
 		if err != nil {
-			err = handleQingStorError(err)
-			return fmt.Errorf(errorMessage, s, path, err)
+			return types.NewError("List", s, path, pairs, err)
 		}
 
-		for _, v := range output.CommonPrefixes {
-			o := &types.Object{
-				ID:         *v,
-				Name:       s.getRelPath(*v),
-				Type:       types.ObjectTypeDir,
-				ObjectMeta: metadata.NewObjectMeta(),
-			}
-
-			if opt.HasDirFunc {
-				opt.DirFunc(o)
-			}
-		}
-
-		for _, v := range output.Keys {
-			o := &types.Object{
-				ID:         *v.Key,
-				Name:       s.getRelPath(*v.Key),
-				Size:       service.Int64Value(v.Size),
-				UpdatedAt:  convertUnixTimestampToTime(service.IntValue(v.Modified)),
-				ObjectMeta: metadata.NewObjectMeta(),
-			}
-
-			if v.MimeType != nil {
-				o.SetContentType(service.StringValue(v.MimeType))
-			}
-			if v.StorageClass != nil {
-				storageClass, err := formatStorageClass(service.StringValue(v.StorageClass))
-				if err != nil {
-					return fmt.Errorf(errorMessage, s, path, err)
-				}
-				o.SetStorageClass(storageClass)
-			}
-			if v.Etag != nil {
-				o.SetETag(service.StringValue(v.Etag))
-			}
-
-			// If key's content type == DirectoryContentType,
-			// we should treat this key as a Dir ObjectMeta.
-			if service.StringValue(v.MimeType) == DirectoryContentType {
-				o.Type = types.ObjectTypeDir
-
+		for _, o := range objects {
+			if o.Type == types.ObjectTypeDir {
 				if opt.HasDirFunc {
 					opt.DirFunc(o)
 				}
 				continue
 			}
-
-			o.Type = types.ObjectTypeFile
 			if opt.HasFileFunc {
 				opt.FileFunc(o)
 			}
 		}
 
-		marker = convert.StringValue(output.NextMarker)
-		if marker == "" {
-			break
+		if next == "" {
+			return nil
 		}
-		if output.HasMore != nil && !*output.HasMore {
-			break
-		}
-		if len(output.Keys) == 0 {
-			break
+		marker = next
+	}
+}
+
+// Iterate implements Storager.Iterate
+func (s *Storage) Iterate(path string, pairs ...*types.Pair) (it *iterator.ObjectIterator, err error) {
+	opt, err := parseStoragePairIterate(pairs...)
+	if err != nil {
+		return nil, types.NewError("Iterate", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	it, err = iterator.NewObjectIterator(func(marker string) ([]*types.Object, string, error) {
+		objects, next, err := s.listObjects(opt.Context, rp, marker)
+		if err != nil {
+			return nil, "", types.NewError("Iterate", s, path, pairs, err)
 		}
+		return objects, next, nil
+	}, opt.ContinuationToken)
+	if err != nil {
+		return nil, types.NewError("Iterate", s, path, pairs, err)
 	}
-	return
+	return it, nil
 }
 
 // Read implements Storager.Read
 func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err error) {
-	const errorMessage = "%s Read [%s]: %w"
+	opt, err := parseStoragePairRead(pairs...)
+	if err != nil {
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
 
 	input := &service.GetObjectInput{}
+	if opt.HasIfMatch {
+		input.IfMatch = &opt.IfMatch
+	}
+	if opt.HasIfNoneMatch {
+		input.IfNoneMatch = &opt.IfNoneMatch
+	}
+	if opt.HasIfModifiedSince {
+		input.IfModifiedSince = &opt.IfModifiedSince
+	}
+	if opt.HasIfUnmodifiedSince {
+		input.IfUnmodifiedSince = &opt.IfUnmodifiedSince
+	}
 
 	rp := s.getAbsPath(path)
 
 	output, err := s.bucket.GetObject(rp, input)
 	if err != nil {
 		err = handleQingStorError(err)
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+
+	r = iowrap.ContextReadCloser(opt.Context, output.Body)
+	if opt.HasVerifyChecksum && opt.VerifyChecksum {
+		m := metadata.NewObjectMeta()
+		if output.XQSEncryptionCustomerAlgorithm == nil {
+			checksum.FromETag(m, service.StringValue(output.ETag))
+		}
+
+		r, err = checksum.NewVerifyReadCloser(r, m)
+		if err != nil {
+			output.Body.Close()
+			return nil, types.NewError("Read", s, path, pairs, err)
+		}
 	}
-	return output.Body, nil
+	return r, nil
 }
 
 // WriteFile implements Storager.WriteFile
 func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Write [%s]: %w"
-
 	opt, err := parseStoragePairWrite(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	if !opt.HasStorageClass && s.storageClass != "" {
+		// Storage class set in Init will be used as default.
+		opt.HasStorageClass, opt.StorageClass = true, s.storageClass
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 
 	input := &service.PutObjectInput{
 		ContentLength: &opt.Size,
-		Body:          r,
+		Body:          iowrap.ContextReader(opt.Context, r),
 	}
 	if opt.HasChecksum {
 		input.ContentMD5 = &opt.Checksum
@@ -347,28 +467,39 @@ func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err err
 	if opt.HasStorageClass {
 		storageClass, err := parseStorageClass(opt.StorageClass)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			return types.NewError("Write", s, path, pairs, err)
 		}
 		input.XQSStorageClass = service.String(storageClass)
 	}
+	if opt.HasUserMetadata {
+		meta := parseUserMetadata(opt.UserMetadata)
+		input.XQSMetaData = &meta
+	}
+	if opt.HasContentType {
+		input.ContentType = &opt.ContentType
+	}
+	if opt.HasContentEncoding {
+		input.ContentEncoding = &opt.ContentEncoding
+	}
+	if opt.HasCacheControl {
+		input.CacheControl = &opt.CacheControl
+	}
 
 	rp := s.getAbsPath(path)
 
 	_, err = s.bucket.PutObject(rp, input)
 	if err != nil {
 		err = handleQingStorError(err)
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 	return nil
 }
 
 // ListSegments implements Storager.ListSegments
 func (s *Storage) ListSegments(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s ListSegments [%s]: %w"
-
 	opt, err := parseStoragePairListSegments(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("ListSegments", s, path, pairs, err)
 	}
 
 	keyMarker := ""
@@ -379,6 +510,10 @@ func (s *Storage) ListSegments(path string, pairs ...*types.Pair) (err error) {
 
 	var output *service.ListMultipartUploadsOutput
 	for {
+		if err = opt.Context.Err(); err != nil {
+			return types.NewError("ListSegments", s, path, pairs, err)
+		}
+
 		output, err = s.bucket.ListMultipartUploads(&service.ListMultipartUploadsInput{
 			KeyMarker:      &keyMarker,
 			Limit:          &limit,
@@ -387,7 +522,7 @@ func (s *Storage) ListSegments(path string, pairs ...*types.Pair) (err error) {
 		})
 		if err != nil {
 			err = handleQingStorError(err)
-			return fmt.Errorf(errorMessage, s, path, err)
+			return types.NewError("ListSegments", s, path, pairs, err)
 		}
 
 		for _, v := range output.Uploads {
@@ -417,11 +552,9 @@ func (s *Storage) ListSegments(path string, pairs ...*types.Pair) (err error) {
 
 // InitSegment implements Storager.InitSegment
 func (s *Storage) InitSegment(path string, pairs ...*types.Pair) (id string, err error) {
-	const errorMessage = "%s InitSegment [%s]: %w"
-
 	opt, err := parseStoragePairInitSegment(pairs...)
 	if err != nil {
-		return "", fmt.Errorf(errorMessage, s, path, err)
+		return "", types.NewError("InitSegment", s, path, pairs, err)
 	}
 
 	input := &service.InitiateMultipartUploadInput{}
@@ -431,7 +564,7 @@ func (s *Storage) InitSegment(path string, pairs ...*types.Pair) (id string, err
 	output, err := s.bucket.InitiateMultipartUpload(rp, input)
 	if err != nil {
 		err = handleQingStorError(err)
-		return "", fmt.Errorf(errorMessage, s, path, err)
+		return "", types.NewError("InitSegment", s, path, pairs, err)
 	}
 
 	id = *output.UploadID
@@ -444,18 +577,21 @@ func (s *Storage) InitSegment(path string, pairs ...*types.Pair) (id string, err
 
 // WriteSegment implements Storager.WriteSegment
 func (s *Storage) WriteSegment(id string, offset, size int64, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s WriteSegment [%s]: %w"
+	opt, err := parseStoragePairWriteSegment(pairs...)
+	if err != nil {
+		return types.NewError("WriteSegment", s, id, pairs, err)
+	}
 
 	s.segmentLock.RLock()
 	seg, ok := s.segments[id]
 	if !ok {
-		return fmt.Errorf(errorMessage, s, id, segment.ErrSegmentNotInitiated)
+		return types.NewError("WriteSegment", s, id, pairs, segment.ErrSegmentNotInitiated)
 	}
 	s.segmentLock.RUnlock()
 
 	p, err := seg.InsertPart(offset, size)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, id, err)
+		return types.NewError("WriteSegment", s, id, pairs, err)
 	}
 
 	rp := s.getAbsPath(seg.Path)
@@ -464,29 +600,27 @@ func (s *Storage) WriteSegment(id string, offset, size int64, r io.Reader, pairs
 		PartNumber:    &p.Index,
 		UploadID:      &seg.ID,
 		ContentLength: &size,
-		Body:          r,
+		Body:          iowrap.ContextReader(opt.Context, r),
 	})
 	if err != nil {
 		err = handleQingStorError(err)
-		return fmt.Errorf(errorMessage, s, id, err)
+		return types.NewError("WriteSegment", s, id, pairs, err)
 	}
 	return
 }
 
 // CompleteSegment implements Storager.CompleteSegment
 func (s *Storage) CompleteSegment(id string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s CompleteSegment [%s]: %w"
-
 	s.segmentLock.RLock()
 	seg, ok := s.segments[id]
 	if !ok {
-		return fmt.Errorf(errorMessage, s, id, segment.ErrSegmentNotInitiated)
+		return types.NewError("CompleteSegment", s, id, pairs, segment.ErrSegmentNotInitiated)
 	}
 	s.segmentLock.RUnlock()
 
 	err = seg.ValidateParts()
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, id, err)
+		return types.NewError("CompleteSegment", s, id, pairs, err)
 	}
 
 	parts := seg.SortedParts()
@@ -507,7 +641,7 @@ func (s *Storage) CompleteSegment(id string, pairs ...*types.Pair) (err error) {
 	})
 	if err != nil {
 		err = handleQingStorError(err)
-		return fmt.Errorf(errorMessage, s, id, err)
+		return types.NewError("CompleteSegment", s, id, pairs, err)
 	}
 
 	s.segmentLock.Lock()
@@ -518,12 +652,10 @@ func (s *Storage) CompleteSegment(id string, pairs ...*types.Pair) (err error) {
 
 // AbortSegment implements Storager.AbortSegment
 func (s *Storage) AbortSegment(id string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s AbortSegment [%s]: %w"
-
 	s.segmentLock.RLock()
 	seg, ok := s.segments[id]
 	if !ok {
-		return fmt.Errorf(errorMessage, s, id, segment.ErrSegmentNotInitiated)
+		return types.NewError("AbortSegment", s, id, pairs, segment.ErrSegmentNotInitiated)
 	}
 	s.segmentLock.RUnlock()
 
@@ -534,7 +666,7 @@ func (s *Storage) AbortSegment(id string, pairs ...*types.Pair) (err error) {
 	})
 	if err != nil {
 		err = handleQingStorError(err)
-		return fmt.Errorf(errorMessage, s, id, err)
+		return types.NewError("AbortSegment", s, id, pairs, err)
 	}
 
 	s.segmentLock.Lock()
@@ -542,3 +674,169 @@ func (s *Storage) AbortSegment(id string, pairs ...*types.Pair) (err error) {
 	s.segmentLock.Unlock()
 	return
 }
+
+// GetLifecycle implements Storager.GetLifecycle
+func (s *Storage) GetLifecycle(pairs ...*types.Pair) (rules []types.LifecycleRule, err error) {
+	opt, err := parseStoragePairGetLifecycle(pairs...)
+	if err != nil {
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+
+	output, err := s.bucket.GetLifecycle()
+	if e, ok := err.(*qserror.QingStorError); ok && e.Code == "lifecycle_not_exists" {
+		return []types.LifecycleRule{}, nil
+	}
+	if err != nil {
+		err = handleQingStorError(err)
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+
+	rules = make([]types.LifecycleRule, 0, len(output.Rule))
+	for _, v := range output.Rule {
+		rule, err := formatLifecycleRule(v)
+		if err != nil {
+			return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SetLifecycle implements Storager.SetLifecycle
+func (s *Storage) SetLifecycle(rules []types.LifecycleRule, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairSetLifecycle(pairs...)
+	if err != nil {
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+
+	if len(rules) == 0 {
+		return s.DeleteLifecycleWithContext(opt.Context, pairs...)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+
+	input := &service.PutBucketLifecycleInput{}
+	for k, v := range rules {
+		rule, err := parseLifecycleRule(v, k)
+		if err != nil {
+			return types.NewError("SetLifecycle", s, "", pairs, err)
+		}
+		input.Rule = append(input.Rule, rule)
+	}
+
+	_, err = s.bucket.PutLifecycle(input)
+	if err != nil {
+		err = handleQingStorError(err)
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+	return nil
+}
+
+// DeleteLifecycle implements Storager.DeleteLifecycle
+func (s *Storage) DeleteLifecycle(pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteLifecycle(pairs...)
+	if err != nil {
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+
+	_, err = s.bucket.DeleteLifecycle()
+	if err != nil {
+		err = handleQingStorError(err)
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+	return nil
+}
+
+// Transit implements Storager.Transit
+func (s *Storage) Transit(path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairTransit(pairs...)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	class, err := parseStorageClass(storageClass)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	// Move object onto itself with a new storage class, which will not copy the data.
+	_, err = s.bucket.PutObject(rp, &service.PutObjectInput{
+		XQSMoveSource:   &rp,
+		XQSStorageClass: service.String(class),
+	})
+	if err != nil {
+		err = handleQingStorError(err)
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+	return nil
+}
+
+// InitAppend implements Storager.InitAppend
+//
+// qingstor's HeadObject output doesn't carry object type, so an existing object's
+// size will be returned as offset and WriteAppend will fail if it's not appendable.
+func (s *Storage) InitAppend(path string, pairs ...*types.Pair) (offset int64, err error) {
+	opt, err := parseStoragePairInitAppend(pairs...)
+	if err != nil {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	output, err := s.bucket.HeadObject(rp, &service.HeadObjectInput{})
+	if err == nil {
+		return convert.Int64Value(output.ContentLength), nil
+	}
+	if err = handleQingStorError(err); !errors.Is(err, types.ErrObjectNotExist) {
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+
+	// Appending empty content at position 0 will create an empty appendable object.
+	_, err = s.appendObject(opt.Context, rp, 0, nil)
+	if err != nil {
+		err = handleAppendError(err)
+		return 0, types.NewError("InitAppend", s, path, pairs, err)
+	}
+	return 0, nil
+}
+
+// WriteAppend implements Storager.WriteAppend
+//
+// Data will be split into blocks of appendBlockSize.
+func (s *Storage) WriteAppend(path string, offset int64, r io.Reader, pairs ...*types.Pair) (next int64, err error) {
+	opt, err := parseStoragePairWriteAppend(pairs...)
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	next = offset
+	err = iowrap.ReadBlocks(r, appendBlockSize, func(block []byte) error {
+		n, err := s.appendObject(opt.Context, rp, next, block)
+		if err != nil {
+			return handleAppendError(err)
+		}
+		next = n
+		return nil
+	})
+	if err != nil {
+		return 0, types.NewError("WriteAppend", s, path, pairs, err)
+	}
+	return next, nil
+}
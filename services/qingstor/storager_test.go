@@ -2,8 +2,14 @@ package qingstor
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,9 +19,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/pengsrc/go-shared/convert"
 	"github.com/stretchr/testify/assert"
+	qsconfig "github.com/yunify/qingstor-sdk-go/v3/config"
 	qerror "github.com/yunify/qingstor-sdk-go/v3/request/errors"
 	"github.com/yunify/qingstor-sdk-go/v3/service"
 
+	"github.com/Xuanwo/storage/pkg/iterator"
 	"github.com/Xuanwo/storage/pkg/segment"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/pairs"
@@ -57,6 +65,7 @@ func TestStorage_Metadata(t *testing.T) {
 		assert.NotNil(t, m)
 		assert.Equal(t, name, m.Name)
 		assert.Equal(t, location, m.MustGetLocation())
+		assert.True(t, m.MustGetFlatList())
 	}
 }
 
@@ -134,6 +143,11 @@ func TestStorage_AbortSegment(t *testing.T) {
 	err = client.AbortSegment(id)
 	assert.Error(t, err)
 	assert.True(t, errors.Is(err, segment.ErrSegmentNotInitiated))
+
+	e := &types.Error{}
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "AbortSegment", e.Op)
+	assert.Equal(t, id, e.Path)
 }
 
 func TestStorage_CompleteSegment(t *testing.T) {
@@ -296,6 +310,111 @@ func TestStorage_Delete(t *testing.T) {
 	}
 }
 
+func TestStorage_DeleteNonEmptyDir(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+
+	mockBucket.EXPECT().ListObjects(gomock.Any()).DoAndReturn(func(input *service.ListObjectsInput) (*service.ListObjectsOutput, error) {
+		assert.Equal(t, "dir/", *input.Prefix)
+		return &service.ListObjectsOutput{
+			Keys: []*service.KeyType{
+				{Key: service.String("dir/")},
+				{Key: service.String("dir/file")},
+			},
+		}, nil
+	})
+
+	client := Storage{
+		bucket: mockBucket,
+	}
+
+	// DeleteObject should not be called.
+	err := client.Delete("dir/")
+	assert.True(t, errors.Is(err, types.ErrDirNotEmpty))
+}
+
+func TestStorage_DeleteAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+
+	mockBucket.EXPECT().ListObjects(gomock.Any()).DoAndReturn(func(input *service.ListObjectsInput) (*service.ListObjectsOutput, error) {
+		assert.Equal(t, "dir/", *input.Prefix)
+		return &service.ListObjectsOutput{
+			HasMore: service.Bool(false),
+			Keys: []*service.KeyType{
+				{Key: service.String("dir/")},
+				{Key: service.String("dir/a")},
+				{Key: service.String("dir/b/c")},
+			},
+		}, nil
+	})
+	mockBucket.EXPECT().DeleteMultipleObjects(gomock.Any()).DoAndReturn(func(input *service.DeleteMultipleObjectsInput) (*service.DeleteMultipleObjectsOutput, error) {
+		keys := make([]string, 0, len(input.Objects))
+		for _, v := range input.Objects {
+			keys = append(keys, *v.Key)
+		}
+		assert.Equal(t, []string{"dir", "dir/", "dir/a", "dir/b/c"}, keys)
+		return &service.DeleteMultipleObjectsOutput{}, nil
+	})
+
+	client := Storage{
+		bucket: mockBucket,
+	}
+
+	err := client.DeleteAll("dir")
+	assert.NoError(t, err)
+}
+
+func TestStorage_DeleteBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+
+	paths := make([]string, 1500)
+	for k := range paths {
+		paths[k] = uuid.New().String()
+	}
+
+	// Paths should be split into two requests.
+	mockBucket.EXPECT().DeleteMultipleObjects(gomock.Any()).DoAndReturn(func(input *service.DeleteMultipleObjectsInput) (*service.DeleteMultipleObjectsOutput, error) {
+		assert.Equal(t, deleteBatchLimit, len(input.Objects))
+		assert.True(t, *input.Quiet)
+		return &service.DeleteMultipleObjectsOutput{
+			Errors: []*service.KeyDeleteErrorType{
+				{Code: service.String("permission_denied"), Key: service.String(paths[1])},
+			},
+		}, nil
+	})
+	mockBucket.EXPECT().DeleteMultipleObjects(gomock.Any()).DoAndReturn(func(input *service.DeleteMultipleObjectsInput) (*service.DeleteMultipleObjectsOutput, error) {
+		assert.Equal(t, len(paths)-deleteBatchLimit, len(input.Objects))
+		return nil, &qerror.QingStorError{StatusCode: 503, Code: "service_unavailable"}
+	})
+
+	client := Storage{
+		bucket: mockBucket,
+	}
+
+	results, err := client.DeleteBatch(paths)
+	assert.NoError(t, err)
+	assert.Equal(t, len(paths), len(results))
+	for k, v := range results {
+		assert.Equal(t, paths[k], v.Path)
+		switch {
+		case k == 1:
+			assert.True(t, errors.Is(v.Err, types.ErrPermissionDenied))
+		case k >= deleteBatchLimit:
+			assert.True(t, errors.Is(v.Err, types.ErrServiceUnavailable))
+		default:
+			assert.NoError(t, v.Err)
+		}
+	}
+}
+
 func TestStorage_InitSegment(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -538,6 +657,60 @@ func TestStorage_List(t *testing.T) {
 	}
 }
 
+func TestStorage_Iterate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+
+	keys := []string{"a", "b", "c", "d", "e"}
+
+	// Return two keys after marker in every page.
+	mockBucket.EXPECT().ListObjects(gomock.Any()).DoAndReturn(func(input *service.ListObjectsInput) (*service.ListObjectsOutput, error) {
+		output := &service.ListObjectsOutput{}
+		for _, v := range keys {
+			if v > *input.Marker && len(output.Keys) < 2 {
+				output.Keys = append(output.Keys, &service.KeyType{Key: service.String(v)})
+			}
+		}
+		last := *output.Keys[len(output.Keys)-1].Key
+		output.NextMarker = service.String(last)
+		output.HasMore = service.Bool(last != keys[len(keys)-1])
+		return output, nil
+	}).AnyTimes()
+
+	client := Storage{
+		bucket: mockBucket,
+	}
+
+	it, err := client.Iterate("")
+	assert.NoError(t, err)
+
+	names := make([]string, 0)
+	for i := 0; i < 3; i++ {
+		o, err := it.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, types.ObjectTypeFile, o.Type)
+		names = append(names, o.Name)
+	}
+
+	// Resume from the continuation token.
+	it, err = client.Iterate("", pairs.WithContinuationToken(it.ContinuationToken()))
+	assert.NoError(t, err)
+	for {
+		o, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, o.Name)
+	}
+	assert.Equal(t, keys, names)
+
+	_, err = client.Iterate("", pairs.WithContinuationToken("invalid token"))
+	assert.True(t, errors.Is(err, iterator.ErrInvalidContinuationToken))
+}
+
 func TestStorage_Move(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -627,6 +800,61 @@ func TestStorage_Read(t *testing.T) {
 	}
 }
 
+func TestStorage_ReadWithVerifyChecksum(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+
+	tests := []struct {
+		name    string
+		etag    string
+		wantErr error
+	}{
+		{
+			"checksum match",
+			"\"9a0364b9e99bb480dd25e1f0284c8555\"",
+			nil,
+		},
+		{
+			"checksum mismatch",
+			"\"00000000000000000000000000000000\"",
+			types.ErrChecksumMismatch,
+		},
+		{
+			"checksum not available",
+			"\"9a0364b9e99bb480dd25e1f0284c8555-2\"",
+			types.ErrChecksumNotAvailable,
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			mockBucket.EXPECT().GetObject(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(inputPath string, input *service.GetObjectInput) (*service.GetObjectOutput, error) {
+					return &service.GetObjectOutput{
+						ETag: convert.String(v.etag),
+						Body: ioutil.NopCloser(bytes.NewBuffer([]byte("content"))),
+					}, nil
+				})
+
+			client := Storage{
+				bucket: mockBucket,
+			}
+
+			r, err := client.Read("test_src", pairs.WithVerifyChecksum(true))
+			if err == nil {
+				_, err = ioutil.ReadAll(r)
+			}
+			if v.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, v.wantErr))
+			}
+		})
+	}
+}
+
 func TestStorage_Stat(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -744,6 +972,92 @@ func TestStorage_Write(t *testing.T) {
 	}
 }
 
+func TestStorage_WriteWithDefaultStorageClass(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+
+	client := Storage{
+		bucket: mockBucket,
+	}
+	err := client.Init(pairs.WithStorageClass(storageclass.Warm))
+	assert.NoError(t, err)
+
+	// Storage class set in Init should be used as default.
+	mockBucket.EXPECT().PutObject(gomock.Any(), gomock.Any()).DoAndReturn(func(inputPath string, input *service.PutObjectInput) (*service.PutObjectOutput, error) {
+		assert.Equal(t, storageClassStandardIA, *input.XQSStorageClass)
+		return nil, nil
+	})
+	err = client.Write("test_src", nil, pairs.WithSize(100))
+	assert.NoError(t, err)
+
+	// Storage class in Write should override the default one.
+	mockBucket.EXPECT().PutObject(gomock.Any(), gomock.Any()).DoAndReturn(func(inputPath string, input *service.PutObjectInput) (*service.PutObjectOutput, error) {
+		assert.Equal(t, storageClassStandard, *input.XQSStorageClass)
+		return nil, nil
+	})
+	err = client.Write("test_src", nil, pairs.WithSize(100), pairs.WithStorageClass(storageclass.Hot))
+	assert.NoError(t, err)
+
+	err = client.Init(pairs.WithStorageClass(storageclass.Cold))
+	assert.True(t, errors.Is(err, types.ErrStorageClassNotSupported))
+}
+
+func TestStorage_WriteWithUserMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+
+	mockBucket.EXPECT().PutObject(gomock.Any(), gomock.Any()).DoAndReturn(func(inputPath string, input *service.PutObjectInput) (*service.PutObjectOutput, error) {
+		assert.Equal(t, map[string]string{"x-qs-meta-job-id": "123"}, *input.XQSMetaData)
+		return nil, nil
+	})
+
+	client := Storage{
+		bucket: mockBucket,
+	}
+
+	err := client.Write("test_src", nil, pairs.WithSize(100), pairs.WithUserMetadata(map[string]string{"Job-Id": "123"}))
+	assert.NoError(t, err)
+}
+
+func TestStorage_ReadWithPreconditions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+
+	mockBucket.EXPECT().GetObject(gomock.Any(), gomock.Any()).DoAndReturn(func(inputPath string, input *service.GetObjectInput) (*service.GetObjectOutput, error) {
+		assert.Equal(t, "etag", *input.IfMatch)
+		return nil, &qerror.QingStorError{Code: "precondition_failed", StatusCode: 412}
+	})
+
+	client := Storage{
+		bucket: mockBucket,
+	}
+
+	_, err := client.Read("test_src", pairs.WithIfMatch("etag"))
+	assert.True(t, errors.Is(err, types.ErrPreconditionFailed))
+}
+
+func TestStorage_WriteWithPreconditions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// qingstor doesn't support conditional write, so no request should be sent.
+	client := Storage{
+		bucket: NewMockBucket(ctrl),
+	}
+
+	err := client.Write("test_src", nil, pairs.WithSize(100), pairs.WithIfNoneMatch("*"))
+	assert.True(t, errors.Is(err, types.ErrNotSupported))
+
+	err = client.Delete("test_src", pairs.WithIfMatch("etag"))
+	assert.True(t, errors.Is(err, types.ErrNotSupported))
+}
+
 func TestStorage_WriteSegment(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -891,3 +1205,191 @@ func TestStorage_ListSegments(t *testing.T) {
 		})
 	}
 }
+
+func TestStorage_ListWithCanceledContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Only the first page should be requested, list will stop after context canceled.
+	mockBucket.EXPECT().ListObjects(gomock.Any()).DoAndReturn(func(input *service.ListObjectsInput) (*service.ListObjectsOutput, error) {
+		return &service.ListObjectsOutput{
+			NextMarker: service.String("test_marker"),
+			HasMore:    service.Bool(true),
+			Keys: []*service.KeyType{
+				{Key: service.String(uuid.New().String())},
+			},
+		}, nil
+	}).Times(1)
+
+	client := Storage{
+		bucket: mockBucket,
+	}
+
+	count := 0
+	err := client.ListWithContext(ctx, "", pairs.WithFileFunc(func(*types.Object) {
+		count++
+		cancel()
+	}))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 1, count)
+}
+
+func TestStorage_WriteWithCanceledContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockBucket.EXPECT().PutObject(gomock.Any(), gomock.Any()).DoAndReturn(func(inputPath string, input *service.PutObjectInput) (*service.PutObjectOutput, error) {
+		b := make([]byte, 4)
+		_, err := input.Body.Read(b)
+		assert.NoError(t, err)
+
+		// Cancel while uploading, following reads should be aborted.
+		cancel()
+		_, err = input.Body.Read(b)
+		return nil, err
+	})
+
+	client := Storage{
+		bucket: mockBucket,
+	}
+
+	err := client.WriteWithContext(ctx, "test", bytes.NewReader(make([]byte, 100)), pairs.WithSize(100))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestStorage_Lifecycle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+	client := Storage{bucket: mockBucket}
+
+	rules := []types.LifecycleRule{
+		{ID: "expire", Enabled: true, ExpirationDays: 30, AbortIncompleteSegmentDays: 7},
+		{Prefix: "logs/", Transitions: []types.LifecycleTransition{{Days: 30, StorageClass: storageclass.Warm}}},
+	}
+
+	var put []*service.RuleType
+	mockBucket.EXPECT().PutLifecycle(gomock.Any()).DoAndReturn(func(input *service.PutBucketLifecycleInput) (*service.PutBucketLifecycleOutput, error) {
+		put = input.Rule
+		return &service.PutBucketLifecycleOutput{}, nil
+	})
+	err := client.SetLifecycle(rules)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(put))
+	assert.Equal(t, lifecycleStatusDisabled, *put[1].Status)
+
+	mockBucket.EXPECT().GetLifecycle().DoAndReturn(func() (*service.GetBucketLifecycleOutput, error) {
+		return &service.GetBucketLifecycleOutput{Rule: put}, nil
+	})
+	got, err := client.GetLifecycle()
+	assert.NoError(t, err)
+	rules[1].ID = "rule-1"
+	assert.Equal(t, rules, got)
+
+	mockBucket.EXPECT().GetLifecycle().DoAndReturn(func() (*service.GetBucketLifecycleOutput, error) {
+		return nil, &qerror.QingStorError{StatusCode: 404, Code: "lifecycle_not_exists"}
+	})
+	got, err = client.GetLifecycle()
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	mockBucket.EXPECT().DeleteLifecycle().DoAndReturn(func() (*service.DeleteBucketLifecycleOutput, error) {
+		return &service.DeleteBucketLifecycleOutput{}, nil
+	})
+	err = client.SetLifecycle(nil)
+	assert.NoError(t, err)
+
+	err = client.SetLifecycle([]types.LifecycleRule{
+		{Transitions: []types.LifecycleTransition{{Days: 30, StorageClass: storageclass.Cold}}},
+	})
+	assert.True(t, errors.Is(err, types.ErrStorageClassNotSupported))
+}
+
+func TestStorage_Transit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBucket := NewMockBucket(ctrl)
+	client := Storage{bucket: mockBucket, workDir: "/prefix"}
+
+	mockBucket.EXPECT().PutObject(gomock.Any(), gomock.Any()).DoAndReturn(func(key string, input *service.PutObjectInput) (*service.PutObjectOutput, error) {
+		assert.Equal(t, "prefix/test", key)
+		assert.Equal(t, key, *input.XQSMoveSource)
+		assert.Equal(t, storageClassStandardIA, *input.XQSStorageClass)
+		return &service.PutObjectOutput{}, nil
+	})
+	err := client.Transit("test", storageclass.Warm)
+	assert.NoError(t, err)
+
+	err = client.Transit("test", storageclass.Cold)
+	assert.True(t, errors.Is(err, types.ErrStorageClassNotSupported))
+}
+
+func TestStorage_WriteAppend(t *testing.T) {
+	var content []byte
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/test/object", r.URL.Path)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "QS access_key:"))
+		requests++
+
+		position, err := strconv.Atoi(r.URL.Query().Get("position"))
+		assert.NoError(t, err)
+		if position != len(content) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"code":"invalid_request","message":"position mismatch"}`))
+			return
+		}
+
+		data, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		content = append(content, data...)
+		w.Header().Set(nextAppendPositionHeader, strconv.Itoa(len(content)))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := qsconfig.New("access_key", "secret_key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Protocol, cfg.Host, cfg.Port = u.Scheme, u.Hostname(), port
+
+	s := Storage{
+		config:     cfg,
+		properties: &service.Properties{BucketName: convert.String("test")},
+	}
+
+	data := bytes.Repeat([]byte("a"), appendBlockSize+1)
+	next, err := s.WriteAppend("object", 0, bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), next)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, data, content)
+
+	_, err = s.WriteAppend("object", 0, strings.NewReader("content"))
+	assert.True(t, errors.Is(err, types.ErrPreconditionFailed))
+	assert.Equal(t, len(data), len(content))
+}
@@ -1,16 +1,108 @@
 package qingstor
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/pengsrc/go-shared/convert"
+	qserror "github.com/yunify/qingstor-sdk-go/v3/request/errors"
+	qssigner "github.com/yunify/qingstor-sdk-go/v3/request/signer"
+	"github.com/yunify/qingstor-sdk-go/v3/service"
+	qsutils "github.com/yunify/qingstor-sdk-go/v3/utils"
+
+	"github.com/Xuanwo/storage/pkg/checksum"
+	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
-	qserror "github.com/yunify/qingstor-sdk-go/v3/request/errors"
+	"github.com/Xuanwo/storage/types/metadata"
 )
 
+// credentialTransport will refresh credential while expired and sign requests with it.
+//
+// qingstor SDK signs requests with keys in a shared config which can't be replaced safely
+// while requests are in flight, so requests signed in header will be signed again here.
+type credentialTransport struct {
+	provider  credential.Provider
+	transport http.RoundTripper
+
+	lock   sync.Mutex
+	signer qssigner.QingStorSigner
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *credentialTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.Header.Get("Authorization"), "QS ") {
+		return t.transport.RoundTrip(req)
+	}
+
+	signer, err := t.retrieve()
+	if err != nil {
+		return nil, err
+	}
+	// RoundTrip should not modify the request.
+	req = req.Clone(req.Context())
+	if err = signRequest(&signer, req); err != nil {
+		return nil, err
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// retrieve will return a signer with current credential, credential will be refreshed if expired.
+func (t *credentialTransport) retrieve() (qssigner.QingStorSigner, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !t.provider.IsExpired() {
+		return t.signer, nil
+	}
+	v, err := t.provider.Retrieve()
+	if err != nil {
+		return qssigner.QingStorSigner{}, err
+	}
+	// qingstor doesn't support session token, so only hmac credential could be used.
+	if v.Protocol != credential.ProtocolHmac {
+		return qssigner.QingStorSigner{}, credential.ErrUnsupportedProtocol
+	}
+	t.signer = qssigner.QingStorSigner{
+		AccessKeyID:     v.Args[0],
+		SecretAccessKey: v.Args[1],
+	}
+	return t.signer, nil
+}
+
+// signRequest will sign request in header with signer.
+//
+// qingstor SDK's signer doesn't sign the append and position subresources, so they will be
+// appended to the string to sign here.
+func signRequest(signer *qssigner.QingStorSigner, req *http.Request) error {
+	stringToSign, err := signer.BuildStringToSign(req)
+	if err != nil {
+		return err
+	}
+	if _, ok := req.URL.Query()["append"]; ok {
+		stringToSign += "?" + req.URL.RawQuery
+	}
+
+	h := hmac.New(sha256.New, []byte(signer.SecretAccessKey))
+	h.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	req.Header.Set("Authorization", "QS "+signer.AccessKeyID+":"+signature)
+	return nil
+}
+
 // bucketNameRegexp is the bucket name regexp, which indicates:
 // 1. length: 6-63;
 // 2. contains lowercase letters, digits and strikethrough;
@@ -30,36 +122,69 @@ func (s *Storage) getRelPath(path string) string {
 	return strings.TrimPrefix(path, s.workDir+"/")
 }
 
+// handleQingStorError will map qingstor error codes onto sentinel errors.
+//
+// ref: https://docs.qingcloud.com/qingstor/api/common/error_code.html
 func handleQingStorError(err error) error {
 	if err == nil {
 		panic("error must not be nil")
 	}
+	// Context errors will be returned as is, so caller could check them via errors.Is.
+	if types.IsContextError(err) {
+		return err
+	}
 
 	var e *qserror.QingStorError
 	e, ok := err.(*qserror.QingStorError)
 	if !ok {
-		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
-	}
-
-	if e.Code == "" {
-		switch e.StatusCode {
-		case 404:
-			return fmt.Errorf("%w: %v", types.ErrObjectNotExist, err)
-		default:
-			return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+		if types.IsTimeout(err) {
+			return fmt.Errorf("%w: %v", types.ErrTimeout, err)
 		}
+		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
 	}
 
 	switch e.Code {
-	case "permission_denied":
+	case "permission_denied", "bucket_policy_permission_denied":
 		return fmt.Errorf("%w: %v", types.ErrPermissionDenied, err)
-	case "object_not_exists":
+	case "object_not_exists", "bucket_not_exists":
 		return fmt.Errorf("%w: %v", types.ErrObjectNotExist, err)
-	case "invalid_access_key_id":
+	case "invalid_access_key_id", "signature_not_match":
 		return fmt.Errorf("%w: %v", types.ErrConfigIncorrect, err)
-	default:
-		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+	case "bucket_already_exists", "object_already_exists":
+		return fmt.Errorf("%w: %v", types.ErrObjectAlreadyExist, err)
+	case "bucket_not_empty":
+		return fmt.Errorf("%w: %v", types.ErrDirNotEmpty, err)
+	case "precondition_failed":
+		return fmt.Errorf("%w: %v", types.ErrPreconditionFailed, err)
+	case "too_many_buckets", "quota_exceeded", "bucket_quota_exceeded":
+		return fmt.Errorf("%w: %v", types.ErrQuotaExceeded, err)
+	case "too_many_requests", "request_limit_exceeded":
+		return fmt.Errorf("%w: %v", types.ErrRateLimited, err)
+	case "request_timeout":
+		return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+	case "service_unavailable", "internal_error":
+		return fmt.Errorf("%w: %v", types.ErrServiceUnavailable, err)
+	case "not_implemented", "method_not_allowed":
+		return fmt.Errorf("%w: %v", types.ErrNotSupported, err)
+	}
+
+	// Fallback to status code while code is empty (like HEAD request) or not handled.
+	if se := types.ErrFromStatusCode(e.StatusCode); se != nil {
+		return fmt.Errorf("%w: %v", se, err)
 	}
+	return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+}
+
+// handleAppendError will handle errors returned by appendObject.
+//
+// qingstor returns 409 Conflict while position mismatched or object is not appendable,
+// both of them mean the append's precondition is not met.
+func handleAppendError(err error) error {
+	var e *qserror.QingStorError
+	if errors.As(err, &e) && e.StatusCode == http.StatusConflict {
+		return fmt.Errorf("%w: %v", types.ErrPreconditionFailed, err)
+	}
+	return handleQingStorError(err)
 }
 
 func convertUnixTimestampToTime(v int) time.Time {
@@ -72,8 +197,29 @@ func convertUnixTimestampToTime(v int) time.Time {
 const (
 	storageClassStandard   = "STANDARD"
 	storageClassStandardIA = "STANDARD_IA"
+
+	// ref: https://docs.qingcloud.com/qingstor/api/bucket/lifecycle/put_lifecycle
+	lifecycleStatusEnabled  = "enabled"
+	lifecycleStatusDisabled = "disabled"
+	// lifecycleStorageClassStandardIA is the storage class of STANDARD_IA in lifecycle transition.
+	lifecycleStorageClassStandardIA = 1
+
+	nextAppendPositionHeader = "X-QS-Next-Append-Position"
+	// appendBlockSize is the max size of data appended in one request.
+	appendBlockSize = 4 * 1024 * 1024
 )
 
+// parseUserMetadata will add userMetadataPrefix to user metadata keys.
+//
+// qingstor sdk doesn't parse metadata from response headers, so user metadata could only be written but not read.
+func parseUserMetadata(in map[string]string) map[string]string {
+	m := make(map[string]string, len(in))
+	for k, v := range in {
+		m[userMetadataPrefix+strings.ToLower(k)] = v
+	}
+	return m
+}
+
 // parseStorageClass will parse storageclass.Type into service independent storage class type.
 func parseStorageClass(in storageclass.Type) (string, error) {
 	switch in {
@@ -97,3 +243,206 @@ func formatStorageClass(in string) (storageclass.Type, error) {
 		return "", types.ErrStorageClassNotSupported
 	}
 }
+
+// listObjects will list a page of objects under rp after marker.
+func (s *Storage) listObjects(ctx context.Context, rp, marker string) (objects []*types.Object, next string, err error) {
+	// qingstor sdk doesn't support context, check it before every request.
+	if err = ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	limit := 200
+	output, err := s.bucket.ListObjects(&service.ListObjectsInput{
+		Limit:  &limit,
+		Marker: &marker,
+		Prefix: &rp,
+	})
+	if err != nil {
+		return nil, "", handleQingStorError(err)
+	}
+
+	objects = make([]*types.Object, 0, len(output.CommonPrefixes)+len(output.Keys))
+	for _, v := range output.CommonPrefixes {
+		o := &types.Object{
+			ID:         *v,
+			Name:       s.getRelPath(*v),
+			Type:       types.ObjectTypeDir,
+			ObjectMeta: metadata.NewObjectMeta(),
+		}
+		objects = append(objects, o)
+	}
+
+	for _, v := range output.Keys {
+		o := &types.Object{
+			ID:         *v.Key,
+			Name:       s.getRelPath(*v.Key),
+			Type:       types.ObjectTypeFile,
+			Size:       service.Int64Value(v.Size),
+			UpdatedAt:  convertUnixTimestampToTime(service.IntValue(v.Modified)),
+			ObjectMeta: metadata.NewObjectMeta(),
+		}
+
+		if v.MimeType != nil {
+			o.SetContentType(service.StringValue(v.MimeType))
+		}
+		if v.StorageClass != nil {
+			storageClass, err := formatStorageClass(service.StringValue(v.StorageClass))
+			if err != nil {
+				return nil, "", err
+			}
+			o.SetStorageClass(storageClass)
+		}
+		if v.Etag != nil {
+			o.SetETag(service.StringValue(v.Etag))
+			checksum.FromETag(o.ObjectMeta, service.StringValue(v.Etag))
+		}
+
+		// If key's content type == DirectoryContentType,
+		// we should treat this key as a Dir ObjectMeta.
+		if service.StringValue(v.MimeType) == DirectoryContentType {
+			o.Type = types.ObjectTypeDir
+		}
+		objects = append(objects, o)
+	}
+
+	if output.HasMore != nil && !*output.HasMore {
+		return objects, "", nil
+	}
+	if len(output.Keys) == 0 {
+		return objects, "", nil
+	}
+	return objects, convert.StringValue(output.NextMarker), nil
+}
+
+// listKeys implements prefix.ListFunc
+func (s *Storage) listKeys(ctx context.Context, prefix, marker string, limit int) (keys []string, next string, err error) {
+	// qingstor sdk doesn't support context, check it before request.
+	if err = ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	output, err := s.bucket.ListObjects(&service.ListObjectsInput{
+		Limit:  &limit,
+		Marker: &marker,
+		Prefix: &prefix,
+	})
+	if err != nil {
+		return nil, "", handleQingStorError(err)
+	}
+
+	keys = make([]string, 0, len(output.Keys))
+	for _, v := range output.Keys {
+		keys = append(keys, service.StringValue(v.Key))
+	}
+	return keys, service.StringValue(output.NextMarker), nil
+}
+
+// parseLifecycleRule will parse types.LifecycleRule into qingstor lifecycle rule.
+//
+// qingstor requires id in every rule, so rule without id will be named by its index.
+// And qingstor only supports one transition to STANDARD_IA in a rule.
+func parseLifecycleRule(in types.LifecycleRule, index int) (*service.RuleType, error) {
+	if len(in.Transitions) > 1 {
+		return nil, fmt.Errorf("%w: rule %s has multiple transitions", types.ErrNotSupported, in.ID)
+	}
+
+	id := in.ID
+	if id == "" {
+		id = fmt.Sprintf("rule-%d", index)
+	}
+	rule := &service.RuleType{
+		ID:     convert.String(id),
+		Filter: &service.FilterType{Prefix: convert.String(in.Prefix)},
+		Status: convert.String(lifecycleStatusDisabled),
+	}
+	if in.Enabled {
+		rule.Status = convert.String(lifecycleStatusEnabled)
+	}
+	if in.ExpirationDays > 0 {
+		rule.Expiration = &service.ExpirationType{Days: convert.Int(in.ExpirationDays)}
+	}
+	for _, v := range in.Transitions {
+		if v.StorageClass != storageclass.Warm {
+			return nil, types.ErrStorageClassNotSupported
+		}
+		rule.Transition = &service.TransitionType{
+			Days:         convert.Int(v.Days),
+			StorageClass: convert.Int(lifecycleStorageClassStandardIA),
+		}
+	}
+	if in.AbortIncompleteSegmentDays > 0 {
+		rule.AbortIncompleteMultipartUpload = &service.AbortIncompleteMultipartUploadType{
+			DaysAfterInitiation: convert.Int(in.AbortIncompleteSegmentDays),
+		}
+	}
+	return rule, nil
+}
+
+// formatLifecycleRule will format qingstor lifecycle rule into types.LifecycleRule.
+func formatLifecycleRule(in *service.RuleType) (rule types.LifecycleRule, err error) {
+	rule.ID = convert.StringValue(in.ID)
+	rule.Enabled = convert.StringValue(in.Status) == lifecycleStatusEnabled
+	if in.Filter != nil {
+		rule.Prefix = convert.StringValue(in.Filter.Prefix)
+	}
+
+	if v := in.Expiration; v != nil {
+		rule.ExpirationDays = convert.IntValue(v.Days)
+	}
+	if v := in.Transition; v != nil {
+		if convert.IntValue(v.StorageClass) != lifecycleStorageClassStandardIA {
+			return rule, types.ErrStorageClassNotSupported
+		}
+		rule.Transitions = []types.LifecycleTransition{{Days: convert.IntValue(v.Days), StorageClass: storageclass.Warm}}
+	}
+	if v := in.AbortIncompleteMultipartUpload; v != nil {
+		rule.AbortIncompleteSegmentDays = convert.IntValue(v.DaysAfterInitiation)
+	}
+	return rule, nil
+}
+
+// appendObject will append data to object at position and return the next append position.
+//
+// qingstor SDK doesn't support append object yet, so the request will be built and signed here.
+//
+// ref: https://docs.qingcloud.com/qingstor/api/object/append.html
+func (s *Storage) appendObject(ctx context.Context, key string, position int64, data []byte) (next int64, err error) {
+	endpoint := fmt.Sprintf("%s://%s:%d", s.config.Protocol, s.config.Host, s.config.Port)
+	if zone := convert.StringValue(s.properties.Zone); zone != "" {
+		endpoint = fmt.Sprintf("%s://%s.%s:%d", s.config.Protocol, zone, s.config.Host, s.config.Port)
+	}
+	subresource := fmt.Sprintf("append&position=%d", position)
+	u := fmt.Sprintf("%s/%s/%s?%s", endpoint,
+		qsutils.URLQueryEscape(convert.StringValue(s.properties.BucketName)), qsutils.URLQueryEscape(key), subresource)
+
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Date", convert.TimeToString(time.Now(), convert.RFC822))
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	signer := &qssigner.QingStorSigner{
+		AccessKeyID:     s.config.AccessKeyID,
+		SecretAccessKey: s.config.SecretAccessKey,
+	}
+	if err = signRequest(signer, req); err != nil {
+		return 0, err
+	}
+
+	resp, err := s.config.Connection.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		e := &qserror.QingStorError{}
+		if content, err := ioutil.ReadAll(resp.Body); err == nil {
+			_ = json.Unmarshal(content, e)
+		}
+		e.StatusCode = resp.StatusCode
+		return 0, e
+	}
+	return strconv.ParseInt(resp.Header.Get(nextAppendPositionHeader), 10, 64)
+}
@@ -2,11 +2,18 @@ package qingstor
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/pairs"
+	"github.com/pengsrc/go-shared/convert"
 	"github.com/stretchr/testify/assert"
 	qserror "github.com/yunify/qingstor-sdk-go/v3/request/errors"
 )
@@ -166,6 +173,39 @@ func TestHandleQingStorError(t *testing.T) {
 				},
 				types.ErrConfigIncorrect,
 			},
+			{
+				"bucket_already_exists",
+				&qserror.QingStorError{
+					StatusCode:   409,
+					Code:         "bucket_already_exists",
+					Message:      "",
+					RequestID:    "",
+					ReferenceURL: "",
+				},
+				types.ErrObjectAlreadyExist,
+			},
+			{
+				"bucket_not_empty",
+				&qserror.QingStorError{
+					StatusCode:   409,
+					Code:         "bucket_not_empty",
+					Message:      "",
+					RequestID:    "",
+					ReferenceURL: "",
+				},
+				types.ErrDirNotEmpty,
+			},
+			{
+				"fallback to status code",
+				&qserror.QingStorError{
+					StatusCode:   503,
+					Code:         "xxxxxx",
+					Message:      "",
+					RequestID:    "",
+					ReferenceURL: "",
+				},
+				types.ErrServiceUnavailable,
+			},
 			{
 				"not handled",
 				&qserror.QingStorError{
@@ -232,3 +272,65 @@ func TestFormatStorageClass(t *testing.T) {
 		})
 	}
 }
+
+func TestCredentialTransport(t *testing.T) {
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	called := 0
+	provider := credential.NewRefreshable(func() (credential.Value, error) {
+		called++
+		// Value expires immediately, so that it will be refreshed in every request.
+		return credential.Value{
+			Protocol:   credential.ProtocolHmac,
+			Args:       []string{fmt.Sprintf("ak%d", called), "sk"},
+			Expiration: time.Now(),
+		}, nil
+	}, 0)
+	client := &http.Client{
+		Transport: &credentialTransport{
+			provider:  provider,
+			transport: http.DefaultTransport,
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/test/object", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Date", convert.TimeToString(time.Now(), convert.RFC822))
+		req.Header.Set("Authorization", "QS stale:signature")
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		// Request passed to RoundTrip should not be modified.
+		assert.Equal(t, "QS stale:signature", req.Header.Get("Authorization"))
+	}
+
+	// Request without signature in header should not be signed.
+	resp, err := client.Get(server.URL + "/test/object?signature=xxx")
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, 3, len(auth))
+	assert.True(t, strings.HasPrefix(auth[0], "QS ak1:"))
+	assert.True(t, strings.HasPrefix(auth[1], "QS ak2:"))
+	assert.Empty(t, auth[2])
+
+	// Only hmac credential is supported by qingstor.
+	client.Transport.(*credentialTransport).provider = credential.NewRefreshable(func() (credential.Value, error) {
+		return credential.MustNewSTS("ak", "sk", "token").Retrieve()
+	}, 0)
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/test/object", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "QS stale:signature")
+	_, err = client.Do(req)
+	assert.True(t, errors.Is(err, credential.ErrUnsupportedProtocol))
+}
@@ -1,9 +1,12 @@
 package s3
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/pkg/endpoint"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -16,38 +19,78 @@ import (
 
 // Service is the s3 service config.
 type Service struct {
+	sess    *session.Session
 	service s3iface.S3API
 }
 
+// defaultRegion will be used while region not given, it's also the region
+// which GetBucketLocation returns empty location for.
+const defaultRegion = "us-east-1"
+
 // New will create a new s3 service.
 func New(pairs ...*types.Pair) (s *Service, err error) {
-	const errorMessage = "%s New: %w"
-
 	opt, err := parseServicePairNew(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
 	cfg := aws.NewConfig()
 
-	credProtocol, cred := opt.Credential.Protocol(), opt.Credential.Value()
-	switch credProtocol {
-	case credential.ProtocolHmac:
-		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(cred[0], cred[1], ""))
+	cred, err := opt.Credential.Retrieve()
+	if err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	switch cred.Protocol {
+	case credential.ProtocolHmac, credential.ProtocolSTS:
+		cfg = cfg.WithCredentials(credentials.NewCredentials(&credentialProvider{opt.Credential}))
 	case credential.ProtocolEnv:
 		cfg = cfg.WithCredentials(credentials.NewEnvCredentials())
+	case credential.ProtocolFile:
+		// Load credential from aws shared credentials file, profile will be
+		// read from env AWS_PROFILE or "default" will be used if not given.
+		c := credentials.NewSharedCredentials(cred.File())
+		_, err = c.Get()
+		if err != nil {
+			return nil, types.NewError("New", s, "", pairs, err)
+		}
+		cfg = cfg.WithCredentials(c)
 	default:
-		return nil, fmt.Errorf(errorMessage, s, credential.ErrUnsupportedProtocol)
+		return nil, types.NewError("New", s, "", pairs, credential.ErrUnsupportedProtocol)
+	}
+
+	if opt.HasEndpoint {
+		ep := opt.Endpoint.Value()
+		switch ep.Protocol {
+		case endpoint.ProtocolHTTPS, endpoint.ProtocolHTTP:
+			cfg = cfg.WithEndpoint(ep.String())
+		case endpoint.ProtocolUnix:
+			// Host is meaningless for unix socket, use localhost to make a valid url.
+			cfg = cfg.WithEndpoint("http://localhost").
+				WithHTTPClient(newUnixHTTPClient(ep.Path))
+		default:
+			return nil, types.NewError("New", s, "", pairs, endpoint.ErrUnsupportedProtocol)
+		}
+	}
+	if opt.HasLocation {
+		cfg = cfg.WithRegion(opt.Location)
+	}
+	if opt.HasForcePathStyle {
+		cfg = cfg.WithS3ForcePathStyle(opt.ForcePathStyle)
 	}
 
 	sess, err := session.NewSession(cfg)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	// Region is required to sign request, use default region if not given via pair or env.
+	if aws.StringValue(sess.Config.Region) == "" {
+		sess.Config.Region = aws.String(defaultRegion)
 	}
 
-	srv := s3.New(sess)
-
-	s = &Service{service: srv}
+	s = &Service{
+		sess:    sess,
+		service: s3.New(sess),
+	}
 	return s, nil
 }
 
@@ -58,25 +101,23 @@ func (s *Service) String() string {
 
 // List implements Servicer.List
 func (s *Service) List(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List: %w"
-
 	opt, err := parseServicePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("List", s, "", pairs, err)
 	}
 
 	input := &s3.ListBucketsInput{}
 
-	output, err := s.service.ListBuckets(input)
+	output, err := s.service.ListBucketsWithContext(opt.Context, input)
 	if err != nil {
 		err = handleS3Error(err)
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("List", s, "", pairs, err)
 	}
 
 	for _, v := range output.Buckets {
 		store, err := newStorage(s.service, *v.Name)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, err)
+			return types.NewError("List", s, "", pairs, err)
 		}
 		if opt.HasStoragerFunc {
 			opt.StoragerFunc(store)
@@ -87,59 +128,120 @@ func (s *Service) List(pairs ...*types.Pair) (err error) {
 
 // Get implements Servicer.Get
 func (s *Service) Get(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s get [%s]: %w"
+	opt, err := parseServicePairGet(pairs...)
+	if err != nil {
+		return nil, types.NewError("Get", s, name, pairs, err)
+	}
 
-	store, err := newStorage(s.service, name)
+	service, err := s.get(opt.Context, name, opt.Location)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Get", s, name, pairs, err)
+	}
+
+	store, err := newStorage(service, name)
+	if err != nil {
+		return nil, types.NewError("Get", s, name, pairs, err)
 	}
 	return store, nil
 }
 
 // Create implements Servicer.Create
 func (s *Service) Create(name string, pairs ...*types.Pair) (storage.Storager, error) {
-	const errorMessage = "%s Create [%s]: %w"
-
 	opt, err := parseServicePairCreate(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 
+	service := s.client(opt.Location)
+
 	input := &s3.CreateBucketInput{
 		Bucket: aws.String(name),
-		CreateBucketConfiguration: &s3.CreateBucketConfiguration{
+	}
+	// LocationConstraint should not be set for us-east-1.
+	if opt.Location != defaultRegion {
+		input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
 			LocationConstraint: aws.String(opt.Location),
-		},
+		}
 	}
 
-	_, err = s.service.CreateBucket(input)
+	_, err = service.CreateBucketWithContext(opt.Context, input)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		err = handleS3Error(err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 
-	store, err := newStorage(s.service, name)
+	if opt.HasVersioning {
+		status := s3.BucketVersioningStatusSuspended
+		if opt.Versioning {
+			status = s3.BucketVersioningStatusEnabled
+		}
+		_, err = service.PutBucketVersioningWithContext(opt.Context, &s3.PutBucketVersioningInput{
+			Bucket:                  aws.String(name),
+			VersioningConfiguration: &s3.VersioningConfiguration{Status: aws.String(status)},
+		})
+		if err != nil {
+			err = handleS3Error(err)
+			return nil, types.NewError("Create", s, name, pairs, err)
+		}
+	}
+
+	store, err := newStorage(service, name)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, name, err)
+		return nil, types.NewError("Create", s, name, pairs, err)
 	}
 	return store, nil
 }
 
 // Delete implements Servicer.Delete
 func (s *Service) Delete(name string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
+	opt, err := parseServicePairDelete(pairs...)
+	if err != nil {
+		return types.NewError("Delete", s, name, pairs, err)
+	}
 
-	_, err = parseServicePairDelete(pairs...)
+	service, err := s.get(opt.Context, name, opt.Location)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 
 	input := &s3.DeleteBucketInput{
 		Bucket: aws.String(name),
 	}
 
-	_, err = s.service.DeleteBucket(input)
+	_, err = service.DeleteBucketWithContext(opt.Context, input)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, name, err)
+		err = handleS3Error(err)
+		return types.NewError("Delete", s, name, pairs, err)
 	}
 	return nil
 }
+
+// get will get a client for bucket, bucket's region will be detected via GetBucketLocation if location not given.
+//
+// GetBucketLocation requires bucket owner's permission, so session's region will be used
+// while it's denied, and users could pass location explicitly if that's not correct.
+func (s *Service) get(ctx context.Context, name, location string) (s3iface.S3API, error) {
+	if location != "" {
+		return s.client(location), nil
+	}
+
+	output, err := s.service.GetBucketLocationWithContext(ctx, &s3.GetBucketLocationInput{
+		Bucket: aws.String(name),
+	})
+	if err != nil {
+		err = handleS3Error(err)
+		if errors.Is(err, types.ErrPermissionDenied) {
+			return s.service, nil
+		}
+		return nil, err
+	}
+	return s.client(s3.NormalizeBucketLocation(aws.StringValue(output.LocationConstraint))), nil
+}
+
+// client will return a client for region, default client will be reused if region is the same.
+func (s *Service) client(region string) s3iface.S3API {
+	if region == "" || region == aws.StringValue(s.sess.Config.Region) {
+		return s.service
+	}
+	return s3.New(s.sess, aws.NewConfig().WithRegion(region))
+}
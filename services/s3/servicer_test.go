@@ -0,0 +1,164 @@
+package s3
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/pkg/endpoint"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+// fakeS3 will record all requests and respond bucket location for GetBucketLocation.
+type fakeS3 struct {
+	sync.Mutex
+
+	location string
+	// denied will make GetBucketLocation return AccessDenied.
+	denied   bool
+	requests []*http.Request
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	f.requests = append(f.requests, r)
+	f.Unlock()
+
+	if _, ok := r.URL.Query()["location"]; ok {
+		if f.denied {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">` + f.location + `</LocationConstraint>`))
+		return
+	}
+	w.Header().Set("Content-Length", "0")
+}
+
+func newFakeEndpoint(t *testing.T, srv *httptest.Server, path string) endpoint.Provider {
+	u, err := url.Parse(srv.URL)
+	assert.NoError(t, err)
+	ep, err := endpoint.Parse("http://" + u.Host + path)
+	assert.NoError(t, err)
+	return ep
+}
+
+func TestService_Get(t *testing.T) {
+	cases := []struct {
+		name     string
+		location string
+		denied   bool
+		pairs    []*types.Pair
+		region   string
+	}{
+		{"detect region", "eu-west-2", false, nil, "eu-west-2"},
+		{"detect empty location", "", false, nil, "us-east-1"},
+		{"detect denied", "eu-west-2", true, nil, "us-east-1"},
+		{"location given", "", false, []*types.Pair{pairs.WithLocation("ap-east-1")}, "ap-east-1"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeS3{location: tt.location, denied: tt.denied}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			s, err := New(
+				pairs.WithCredential(credential.MustNewHmac("ak", "sk")),
+				pairs.WithEndpoint(newFakeEndpoint(t, srv, "/s3")),
+				pairs.WithForcePathStyle(true),
+			)
+			assert.NoError(t, err)
+
+			store, err := s.Get("bucket", tt.pairs...)
+			assert.NoError(t, err)
+			_, err = store.Stat("object")
+			assert.NoError(t, err)
+
+			fake.Lock()
+			defer fake.Unlock()
+
+			// GetBucketLocation should only be called while location not given.
+			_, detected := fake.requests[0].URL.Query()["location"]
+			assert.Equal(t, tt.pairs == nil, detected)
+
+			last := fake.requests[len(fake.requests)-1]
+			// Bucket should be in path after base path.
+			assert.Equal(t, "/s3/bucket/object", last.URL.Path)
+			// Request should be signed with bucket's region.
+			assert.Contains(t, last.Header.Get("Authorization"), "/"+tt.region+"/s3/aws4_request")
+		})
+	}
+}
+
+func TestNew_STS(t *testing.T) {
+	fake := &fakeS3{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s, err := New(
+		pairs.WithCredential(credential.MustNewSTS("ak", "sk", "token")),
+		pairs.WithEndpoint(newFakeEndpoint(t, srv, "")),
+		pairs.WithLocation("us-east-1"),
+		pairs.WithForcePathStyle(true),
+	)
+	assert.NoError(t, err)
+
+	store, err := s.Get("bucket", pairs.WithLocation("us-east-1"))
+	assert.NoError(t, err)
+	_, err = store.Stat("object")
+	assert.NoError(t, err)
+
+	fake.Lock()
+	defer fake.Unlock()
+
+	assert.Equal(t, "token", fake.requests[0].Header.Get("X-Amz-Security-Token"))
+}
+
+func TestNew_File(t *testing.T) {
+	fake := &fakeS3{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "s3")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "credentials")
+	err = ioutil.WriteFile(path, []byte("[default]\naws_access_key_id = default\naws_secret_access_key = sk\n\n[test]\naws_access_key_id = test\naws_secret_access_key = sk\n"), 0600)
+	assert.NoError(t, err)
+
+	s, err := New(
+		pairs.WithCredential(credential.MustNewFile(path, "test")),
+		pairs.WithEndpoint(newFakeEndpoint(t, srv, "")),
+		pairs.WithLocation("us-east-1"),
+		pairs.WithForcePathStyle(true),
+	)
+	assert.NoError(t, err)
+
+	store, err := s.Get("bucket", pairs.WithLocation("us-east-1"))
+	assert.NoError(t, err)
+	_, err = store.Stat("object")
+	assert.NoError(t, err)
+
+	fake.Lock()
+	defer fake.Unlock()
+
+	assert.Contains(t, fake.requests[0].Header.Get("Authorization"), "Credential=test/")
+
+	_, err = New(
+		pairs.WithCredential(credential.MustNewFile(path, "not-exist")),
+		pairs.WithEndpoint(newFakeEndpoint(t, srv, "")),
+	)
+	assert.Error(t, err)
+}
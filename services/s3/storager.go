@@ -3,23 +3,42 @@ package s3
 import (
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"strings"
 
+	"github.com/Xuanwo/storage/pkg/checksum"
+	"github.com/Xuanwo/storage/pkg/iterator"
+	"github.com/Xuanwo/storage/pkg/prefix"
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/pkg/tagging"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/metadata"
+	ps "github.com/Xuanwo/storage/types/pairs"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 )
 
+// deleteBatchLimit is the max count of keys in one DeleteObjects request.
+const deleteBatchLimit = 1000
+
+// copyObjectSizeLimit is the max size of object which could be copied in one CopyObject request.
+//
+// ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_CopyObject.html
+const copyObjectSizeLimit = 5 * 1024 * 1024 * 1024
+
 // Storage is the s3 object storage service.
 //
 //go:generate ../../internal/bin/service
 type Storage struct {
 	service s3iface.S3API
 
-	name    string
-	workDir string
+	name         string
+	workDir      string
+	storageClass storageclass.Type
 }
 
 // newStorage will create a new client.
@@ -33,17 +52,22 @@ func newStorage(service s3iface.S3API, bucketName string) (*Storage, error) {
 
 // Init implements Storager.Init
 func (s *Storage) Init(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Init: %w"
-
 	opt, err := parseStoragePairInit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("Init", s, "", pairs, err)
 	}
 
 	if opt.HasWorkDir {
 		// TODO: we should validate workDir
 		s.workDir = strings.TrimLeft(opt.WorkDir, "/")
 	}
+	if opt.HasStorageClass {
+		// Storage class will be used as default for Write, validate it here to fail fast.
+		if _, err = parseStorageClass(opt.StorageClass); err != nil {
+			return types.NewError("Init", s, "", pairs, err)
+		}
+		s.storageClass = opt.StorageClass
+	}
 
 	return nil
 }
@@ -61,84 +85,73 @@ func (s *Storage) Metadata(pairs ...*types.Pair) (m metadata.StorageMeta, err er
 	m = metadata.NewStorageMeta()
 	m.Name = s.name
 	m.WorkDir = s.workDir
+	m.SetFlatList(true)
 	return m, nil
 }
 
 // List implements Storager.List
 func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List [%s]: %w"
-
 	opt, err := parseStoragePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("List", s, path, pairs, err)
 	}
 
 	marker := ""
 	rp := s.getAbsPath(path)
 
-	var output *s3.ListObjectsV2Output
 	for {
-		output, err = s.service.ListObjectsV2(&s3.ListObjectsV2Input{
-			Bucket:     aws.String(s.name),
-			Prefix:     aws.String(rp),
-			MaxKeys:    aws.Int64(1000),
-			StartAfter: aws.String(marker),
-		})
+		objects, next, err := s.listObjects(opt.Context, rp, marker)
 		if err != nil {
-			err = handleS3Error(err)
-			return fmt.Errorf(errorMessage, s, path, err)
+			return types.NewError("List", s, path, pairs, err)
 		}
 
-		for _, v := range output.CommonPrefixes {
-			o := &types.Object{
-				ID:         *v.Prefix,
-				Name:       s.getRelPath(*v.Prefix),
-				Type:       types.ObjectTypeDir,
-				ObjectMeta: metadata.NewObjectMeta(),
-			}
-
-			if opt.HasDirFunc {
-				opt.DirFunc(o)
-			}
-		}
-
-		for _, v := range output.Contents {
-			o := &types.Object{
-				ID:         *v.Key,
-				Type:       types.ObjectTypeFile,
-				Name:       s.getRelPath(*v.Key),
-				Size:       aws.Int64Value(v.Size),
-				UpdatedAt:  aws.TimeValue(v.LastModified),
-				ObjectMeta: metadata.NewObjectMeta(),
-			}
-
-			if v.StorageClass != nil {
-				storageClass, err := formatStorageClass(*v.StorageClass)
-				if err != nil {
-					return fmt.Errorf(errorMessage, s, path, err)
+		for _, o := range objects {
+			if o.Type == types.ObjectTypeDir {
+				if opt.HasDirFunc {
+					opt.DirFunc(o)
 				}
-				o.SetStorageClass(storageClass)
+				continue
 			}
-			if v.ETag != nil {
-				o.SetETag(*v.ETag)
-			}
-
 			if opt.HasFileFunc {
 				opt.FileFunc(o)
 			}
 		}
 
-		marker = aws.StringValue(output.StartAfter)
-		if aws.BoolValue(output.IsTruncated) {
-			break
+		if next == "" {
+			return nil
 		}
+		marker = next
 	}
-	return
+}
+
+// Iterate implements Storager.Iterate
+func (s *Storage) Iterate(path string, pairs ...*types.Pair) (it *iterator.ObjectIterator, err error) {
+	opt, err := parseStoragePairIterate(pairs...)
+	if err != nil {
+		return nil, types.NewError("Iterate", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	it, err = iterator.NewObjectIterator(func(marker string) ([]*types.Object, string, error) {
+		objects, next, err := s.listObjects(opt.Context, rp, marker)
+		if err != nil {
+			return nil, "", types.NewError("Iterate", s, path, pairs, err)
+		}
+		return objects, next, nil
+	}, opt.ContinuationToken)
+	if err != nil {
+		return nil, types.NewError("Iterate", s, path, pairs, err)
+	}
+	return it, nil
 }
 
 // Read implements Storager.Read
 func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err error) {
-	const errorMessage = "%s Read [%s]: %w"
+	opt, err := parseStoragePairRead(pairs...)
+	if err != nil {
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
@@ -146,27 +159,63 @@ func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err
 		Bucket: aws.String(s.name),
 		Key:    aws.String(rp),
 	}
+	if opt.HasVersionID {
+		input.VersionId = &opt.VersionID
+	}
+	if opt.HasIfMatch {
+		input.IfMatch = &opt.IfMatch
+	}
+	if opt.HasIfNoneMatch {
+		input.IfNoneMatch = &opt.IfNoneMatch
+	}
+	if opt.HasIfModifiedSince {
+		input.IfModifiedSince = &opt.IfModifiedSince
+	}
+	if opt.HasIfUnmodifiedSince {
+		input.IfUnmodifiedSince = &opt.IfUnmodifiedSince
+	}
 
-	output, err := s.service.GetObject(input)
+	output, err := s.service.GetObjectWithContext(opt.Context, input)
 	if err != nil {
 		err = handleS3Error(err)
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Read", s, path, pairs, err)
 	}
-	return output.Body, nil
+
+	r = output.Body
+	if opt.HasVerifyChecksum && opt.VerifyChecksum {
+		m := metadata.NewObjectMeta()
+		if !isEncrypted(output.ServerSideEncryption, output.SSECustomerAlgorithm) {
+			checksum.FromETag(m, aws.StringValue(output.ETag))
+		}
+
+		r, err = checksum.NewVerifyReadCloser(r, m)
+		if err != nil {
+			output.Body.Close()
+			return nil, types.NewError("Read", s, path, pairs, err)
+		}
+	}
+	return r, nil
 }
 
 // Write implements Storager.Write
 func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Write [%s]: %w"
-
 	opt, err := parseStoragePairWrite(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	if !opt.HasStorageClass && s.storageClass != "" {
+		// Storage class set in Init will be used as default.
+		opt.HasStorageClass, opt.StorageClass = true, s.storageClass
+	}
+	if opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
 	input := &s3.PutObjectInput{
+		Bucket:        aws.String(s.name),
 		Key:           aws.String(rp),
 		ContentLength: &opt.Size,
 		Body:          aws.ReadSeekCloser(r),
@@ -177,33 +226,82 @@ func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err err
 	if opt.HasStorageClass {
 		storageClass, err := parseStorageClass(opt.StorageClass)
 		if err != nil {
-			return fmt.Errorf(errorMessage, s, path, err)
+			return types.NewError("Write", s, path, pairs, err)
 		}
 		input.StorageClass = &storageClass
 	}
+	if opt.HasUserMetadata {
+		input.Metadata = aws.StringMap(opt.UserMetadata)
+	}
+	if opt.HasContentType {
+		input.ContentType = &opt.ContentType
+	}
+	if opt.HasContentEncoding {
+		input.ContentEncoding = &opt.ContentEncoding
+	}
+	if opt.HasContentDisposition {
+		input.ContentDisposition = &opt.ContentDisposition
+	}
+	if opt.HasCacheControl {
+		input.CacheControl = &opt.CacheControl
+	}
+	if opt.HasExpires {
+		input.Expires = &opt.Expires
+	}
+	if opt.HasTags {
+		input.Tagging = aws.String(tagging.Encode(opt.Tags))
+	}
+
+	// Conditional write headers are not supported by PutObjectInput, so we set them on the request.
+	var reqOpts []request.Option
+	if opt.HasIfMatch {
+		reqOpts = append(reqOpts, withHeader("If-Match", opt.IfMatch))
+	}
+	if opt.HasIfNoneMatch {
+		reqOpts = append(reqOpts, withHeader("If-None-Match", opt.IfNoneMatch))
+	}
 
-	_, err = s.service.PutObject(input)
+	_, err = s.service.PutObjectWithContext(opt.Context, input, reqOpts...)
 	if err != nil {
 		err = handleS3Error(err)
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 	return nil
 }
 
 // Stat implements Storager.Stat
 func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err error) {
-	const errorMessage = "%s Stat [%s]: %w"
+	opt, err := parseStoragePairStat(pairs...)
+	if err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
 	input := &s3.HeadObjectInput{
-		Key: aws.String(rp),
+		Bucket: aws.String(s.name),
+		Key:    aws.String(rp),
+	}
+	if opt.HasVersionID {
+		input.VersionId = &opt.VersionID
+	}
+	if opt.HasIfMatch {
+		input.IfMatch = &opt.IfMatch
+	}
+	if opt.HasIfNoneMatch {
+		input.IfNoneMatch = &opt.IfNoneMatch
+	}
+	if opt.HasIfModifiedSince {
+		input.IfModifiedSince = &opt.IfModifiedSince
+	}
+	if opt.HasIfUnmodifiedSince {
+		input.IfUnmodifiedSince = &opt.IfUnmodifiedSince
 	}
 
-	output, err := s.service.HeadObject(input)
+	output, err := s.service.HeadObjectWithContext(opt.Context, input)
 	if err != nil {
 		err = handleS3Error(err)
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	// TODO: Add dir support.
@@ -220,34 +318,447 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 	if output.ContentType != nil {
 		o.SetContentType(*output.ContentType)
 	}
+	if output.ContentEncoding != nil {
+		o.SetContentEncoding(*output.ContentEncoding)
+	}
+	if output.ContentDisposition != nil {
+		o.SetContentDisposition(*output.ContentDisposition)
+	}
+	if output.CacheControl != nil {
+		o.SetCacheControl(*output.CacheControl)
+	}
+	if output.Expires != nil {
+		// Invalid Expires means the object is already expired, so we just ignore it.
+		if expires, err := http.ParseTime(*output.Expires); err == nil {
+			o.SetExpires(expires)
+		}
+	}
 	if output.ETag != nil {
 		o.SetETag(*output.ETag)
+		if !isEncrypted(output.ServerSideEncryption, output.SSECustomerAlgorithm) {
+			checksum.FromETag(o.ObjectMeta, *output.ETag)
+		}
 	}
 	if output.StorageClass != nil {
 		storageClass, err := formatStorageClass(*output.StorageClass)
 		if err != nil {
-			return nil, fmt.Errorf(errorMessage, s, path, err)
+			return nil, types.NewError("Stat", s, path, pairs, err)
 		}
 		o.SetStorageClass(storageClass)
+		if storageClass == storageclass.Cold {
+			o.SetRestoreStatus(formatRestoreStatus(aws.StringValue(output.Restore)))
+		}
 	}
+	if len(output.Metadata) > 0 {
+		o.SetUserMetadata(formatUserMetadata(output.Metadata))
+	}
+	if output.VersionId != nil {
+		o.SetVersionID(*output.VersionId)
+	}
+
 	return o, nil
 }
 
+// GetTags implements Storager.GetTags
+func (s *Storage) GetTags(path string, pairs ...*types.Pair) (tags map[string]string, err error) {
+	opt, err := parseStoragePairGetTags(pairs...)
+	if err != nil {
+		return nil, types.NewError("GetTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	output, err := s.service.GetObjectTaggingWithContext(opt.Context, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(rp),
+	})
+	if err != nil {
+		err = handleS3Error(err)
+		return nil, types.NewError("GetTags", s, path, pairs, err)
+	}
+
+	tags = make(map[string]string, len(output.TagSet))
+	for _, v := range output.TagSet {
+		tags[aws.StringValue(v.Key)] = aws.StringValue(v.Value)
+	}
+	return tags, nil
+}
+
+// SetTags implements Storager.SetTags
+func (s *Storage) SetTags(path string, tags map[string]string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairSetTags(pairs...)
+	if err != nil {
+		return types.NewError("SetTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	tagSet := make([]*s3.Tag, 0, len(tags))
+	for k, v := range tags {
+		tagSet = append(tagSet, &s3.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+
+	_, err = s.service.PutObjectTaggingWithContext(opt.Context, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(s.name),
+		Key:     aws.String(rp),
+		Tagging: &s3.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		err = handleS3Error(err)
+		return types.NewError("SetTags", s, path, pairs, err)
+	}
+	return nil
+}
+
+// DeleteTags implements Storager.DeleteTags
+func (s *Storage) DeleteTags(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteTags(pairs...)
+	if err != nil {
+		return types.NewError("DeleteTags", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	_, err = s.service.DeleteObjectTaggingWithContext(opt.Context, &s3.DeleteObjectTaggingInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(rp),
+	})
+	if err != nil {
+		err = handleS3Error(err)
+		return types.NewError("DeleteTags", s, path, pairs, err)
+	}
+	return nil
+}
+
 // Delete implements Storager.Delete
 func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
+	opt, err := parseStoragePairDelete(pairs...)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
+	// Key ends with "/" is a dir, s3 will delete it even if there are keys under it.
+	if strings.HasSuffix(rp, "/") {
+		err = prefix.CheckDirEmpty(opt.Context, rp, s.listKeys)
+		if err != nil {
+			return types.NewError("Delete", s, path, pairs, err)
+		}
+	}
+
 	input := &s3.DeleteObjectInput{
 		Bucket: aws.String(s.name),
 		Key:    aws.String(rp),
 	}
+	if opt.HasVersionID {
+		input.VersionId = &opt.VersionID
+	}
+
+	var reqOpts []request.Option
+	if opt.HasIfMatch {
+		reqOpts = append(reqOpts, withHeader("If-Match", opt.IfMatch))
+	}
+
+	_, err = s.service.DeleteObjectWithContext(opt.Context, input, reqOpts...)
+	if err != nil {
+		err = handleS3Error(err)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	return nil
+}
+
+// ListVersions implements Storager.ListVersions
+func (s *Storage) ListVersions(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairListVersions(pairs...)
+	if err != nil {
+		return types.NewError("ListVersions", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	input := &s3.ListObjectVersionsInput{
+		Bucket:  aws.String(s.name),
+		Prefix:  aws.String(rp),
+		MaxKeys: aws.Int64(1000),
+	}
+
+	for {
+		output, err := s.service.ListObjectVersionsWithContext(opt.Context, input)
+		if err != nil {
+			err = handleS3Error(err)
+			return types.NewError("ListVersions", s, path, pairs, err)
+		}
+
+		for _, v := range output.Versions {
+			// Versions are sorted by key, so versions of rp always come before other keys under prefix rp.
+			if aws.StringValue(v.Key) != rp {
+				return nil
+			}
+
+			o := &types.Object{
+				ID:         rp,
+				Name:       path,
+				Type:       types.ObjectTypeFile,
+				Size:       aws.Int64Value(v.Size),
+				UpdatedAt:  aws.TimeValue(v.LastModified),
+				ObjectMeta: metadata.NewObjectMeta(),
+			}
+			o.SetVersionID(aws.StringValue(v.VersionId))
+			if v.ETag != nil {
+				o.SetETag(*v.ETag)
+			}
+			if v.StorageClass != nil {
+				storageClass, err := formatStorageClass(*v.StorageClass)
+				if err != nil {
+					return types.NewError("ListVersions", s, path, pairs, err)
+				}
+				o.SetStorageClass(storageClass)
+			}
+			opt.FileFunc(o)
+		}
+
+		if !aws.BoolValue(output.IsTruncated) {
+			return nil
+		}
+		input.KeyMarker = output.NextKeyMarker
+		input.VersionIdMarker = output.NextVersionIdMarker
+	}
+}
+
+// DeleteAll implements Storager.DeleteAll
+func (s *Storage) DeleteAll(path string, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteAll(pairs...)
+	if err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+	if err = types.CheckNotRoot(path); err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+
+	err = prefix.DeleteAll(opt.Context, s.getAbsPath(path), s.listKeys, s.getRelPath, s.DeleteBatchWithContext)
+	if err != nil {
+		return types.NewError("DeleteAll", s, path, pairs, err)
+	}
+	return nil
+}
+
+// DeleteBatch implements Storager.DeleteBatch
+func (s *Storage) DeleteBatch(paths []string, pairs ...*types.Pair) (results []types.DeleteResult, err error) {
+	opt, err := parseStoragePairDeleteBatch(pairs...)
+	if err != nil {
+		return nil, types.NewError("DeleteBatch", s, "", pairs, err)
+	}
+
+	results = make([]types.DeleteResult, len(paths))
+	for k, v := range paths {
+		results[k].Path = v
+	}
+
+	for start := 0; start < len(paths); start += deleteBatchLimit {
+		end := start + deleteBatchLimit
+		if end > len(paths) {
+			end = len(paths)
+		}
+
+		// index maps object key to it's indexes in paths.
+		index := make(map[string][]int)
+		objects := make([]*s3.ObjectIdentifier, 0, end-start)
+		for i := start; i < end; i++ {
+			rp := s.getAbsPath(paths[i])
+			if _, ok := index[rp]; !ok {
+				objects = append(objects, &s3.ObjectIdentifier{Key: aws.String(rp)})
+			}
+			index[rp] = append(index[rp], i)
+		}
+
+		output, err := s.service.DeleteObjectsWithContext(opt.Context, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.name),
+			Delete: &s3.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			err = handleS3Error(err)
+			if types.IsContextError(err) {
+				return nil, types.NewError("DeleteBatch", s, "", pairs, err)
+			}
+			for i := start; i < end; i++ {
+				results[i].Err = types.NewError("DeleteBatch", s, paths[i], pairs, err)
+			}
+			continue
+		}
+
+		// Only failed objects will be returned in quiet mode.
+		for _, v := range output.Errors {
+			err := handleS3Error(awserr.New(aws.StringValue(v.Code), aws.StringValue(v.Message), nil))
+			for _, i := range index[aws.StringValue(v.Key)] {
+				results[i].Err = types.NewError("DeleteBatch", s, paths[i], pairs, err)
+			}
+		}
+	}
+	return results, nil
+}
+
+// GetLifecycle implements Storager.GetLifecycle
+func (s *Storage) GetLifecycle(pairs ...*types.Pair) (rules []types.LifecycleRule, err error) {
+	opt, err := parseStoragePairGetLifecycle(pairs...)
+	if err != nil {
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+
+	output, err := s.service.GetBucketLifecycleConfigurationWithContext(opt.Context, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(s.name),
+	})
+	if e, ok := err.(awserr.Error); ok && e.Code() == "NoSuchLifecycleConfiguration" {
+		return []types.LifecycleRule{}, nil
+	}
+	if err != nil {
+		err = handleS3Error(err)
+		return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+	}
+
+	rules = make([]types.LifecycleRule, 0, len(output.Rules))
+	for _, v := range output.Rules {
+		rule, err := formatLifecycleRule(v)
+		if err != nil {
+			return nil, types.NewError("GetLifecycle", s, "", pairs, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SetLifecycle implements Storager.SetLifecycle
+func (s *Storage) SetLifecycle(rules []types.LifecycleRule, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairSetLifecycle(pairs...)
+	if err != nil {
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+
+	// s3 requires at least one rule in lifecycle configuration.
+	if len(rules) == 0 {
+		return s.DeleteLifecycleWithContext(opt.Context, pairs...)
+	}
+
+	input := &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(s.name),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{},
+	}
+	for _, v := range rules {
+		rule, err := parseLifecycleRule(v)
+		if err != nil {
+			return types.NewError("SetLifecycle", s, "", pairs, err)
+		}
+		input.LifecycleConfiguration.Rules = append(input.LifecycleConfiguration.Rules, rule)
+	}
+
+	_, err = s.service.PutBucketLifecycleConfigurationWithContext(opt.Context, input)
+	if err != nil {
+		err = handleS3Error(err)
+		return types.NewError("SetLifecycle", s, "", pairs, err)
+	}
+	return nil
+}
+
+// DeleteLifecycle implements Storager.DeleteLifecycle
+func (s *Storage) DeleteLifecycle(pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairDeleteLifecycle(pairs...)
+	if err != nil {
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+
+	_, err = s.service.DeleteBucketLifecycleWithContext(opt.Context, &s3.DeleteBucketLifecycleInput{
+		Bucket: aws.String(s.name),
+	})
+	if err != nil {
+		err = handleS3Error(err)
+		return types.NewError("DeleteLifecycle", s, "", pairs, err)
+	}
+	return nil
+}
+
+// Restore implements Storager.Restore
+func (s *Storage) Restore(path string, days int, tier storageclass.RestoreTier, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairRestore(pairs...)
+	if err != nil {
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	input := &s3.RestoreObjectInput{
+		Bucket:         aws.String(s.name),
+		Key:            aws.String(rp),
+		RestoreRequest: &s3.RestoreRequest{Days: aws.Int64(int64(days))},
+	}
+	if v := parseRestoreTier(tier); v != "" {
+		input.RestoreRequest.GlacierJobParameters = &s3.GlacierJobParameters{Tier: aws.String(v)}
+	}
+
+	_, err = s.service.RestoreObjectWithContext(opt.Context, input)
+	if e, ok := err.(awserr.Error); ok && e.Code() == "RestoreAlreadyInProgress" {
+		return nil
+	}
+	if err != nil {
+		err = handleS3Error(err)
+		return types.NewError("Restore", s, path, pairs, err)
+	}
+	return nil
+}
+
+// Transit implements Storager.Transit
+func (s *Storage) Transit(path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error) {
+	opt, err := parseStoragePairTransit(pairs...)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	rp := s.getAbsPath(path)
+
+	class, err := parseStorageClass(storageClass)
+	if err != nil {
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+
+	// s3 rejects copying object onto itself without any change, and CopyObject has a size limit,
+	// so we need to stat it first.
+	output, err := s.service.HeadObjectWithContext(opt.Context, &s3.HeadObjectInput{
+		Bucket: aws.String(s.name),
+		Key:    aws.String(rp),
+	})
+	if err != nil {
+		err = handleS3Error(err)
+		return types.NewError("Transit", s, path, pairs, err)
+	}
+	// s3 will omit storage class for STANDARD objects.
+	current := aws.StringValue(output.StorageClass)
+	if current == "" {
+		current = s3.StorageClassStandard
+	}
+	if current == class {
+		return nil
+	}
+	if size := aws.Int64Value(output.ContentLength); size > copyObjectSizeLimit {
+		err = fmt.Errorf("%w: object size %d exceeds copy limit %d", types.ErrNotSupported, size, int64(copyObjectSizeLimit))
+		return types.NewError("Transit", s, path, pairs, err)
+	}
 
-	_, err = s.service.DeleteObject(input)
+	// Copy object onto itself with a new storage class, metadata and tags will be copied as well.
+	_, err = s.service.CopyObjectWithContext(opt.Context, &s3.CopyObjectInput{
+		Bucket:       aws.String(s.name),
+		Key:          aws.String(rp),
+		CopySource:   aws.String(url.PathEscape(s.name + "/" + rp)),
+		StorageClass: aws.String(class),
+	})
 	if err != nil {
 		err = handleS3Error(err)
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("Transit", s, path, pairs, err)
 	}
 	return nil
 }
@@ -0,0 +1,175 @@
+package s3
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+// mockS3API will only implement the methods used in tests.
+type mockS3API struct {
+	s3iface.S3API
+
+	deleteObjects      func(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error)
+	listObjectVersions func(input *s3.ListObjectVersionsInput) (*s3.ListObjectVersionsOutput, error)
+	headObject         func(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error)
+	copyObject         func(input *s3.CopyObjectInput) (*s3.CopyObjectOutput, error)
+}
+
+func (m *mockS3API) DeleteObjectsWithContext(ctx aws.Context, input *s3.DeleteObjectsInput, opts ...request.Option) (*s3.DeleteObjectsOutput, error) {
+	return m.deleteObjects(input)
+}
+
+func (m *mockS3API) HeadObjectWithContext(ctx aws.Context, input *s3.HeadObjectInput, opts ...request.Option) (*s3.HeadObjectOutput, error) {
+	return m.headObject(input)
+}
+
+func (m *mockS3API) CopyObjectWithContext(ctx aws.Context, input *s3.CopyObjectInput, opts ...request.Option) (*s3.CopyObjectOutput, error) {
+	return m.copyObject(input)
+}
+
+func (m *mockS3API) ListObjectVersionsWithContext(ctx aws.Context, input *s3.ListObjectVersionsInput, opts ...request.Option) (*s3.ListObjectVersionsOutput, error) {
+	return m.listObjectVersions(input)
+}
+
+func TestStorage_DeleteBatch(t *testing.T) {
+	paths := make([]string, 2500)
+	for k := range paths {
+		paths[k] = strconv.Itoa(k)
+	}
+
+	requests := 0
+	client := Storage{
+		name:    "test_bucket",
+		workDir: "prefix",
+		service: &mockS3API{
+			deleteObjects: func(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+				requests++
+				assert.Equal(t, "test_bucket", *input.Bucket)
+				assert.True(t, len(input.Delete.Objects) <= deleteBatchLimit)
+
+				output := &s3.DeleteObjectsOutput{}
+				for _, v := range input.Delete.Objects {
+					if *v.Key == "prefix/42" {
+						output.Errors = append(output.Errors, &s3.Error{
+							Code: aws.String("AccessDenied"),
+							Key:  v.Key,
+						})
+					}
+				}
+				return output, nil
+			},
+		},
+	}
+
+	results, err := client.DeleteBatch(paths)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, len(paths), len(results))
+	for k, v := range results {
+		assert.Equal(t, paths[k], v.Path)
+		if k == 42 {
+			assert.True(t, errors.Is(v.Err, types.ErrPermissionDenied))
+			continue
+		}
+		assert.NoError(t, v.Err)
+	}
+}
+
+func TestStorage_ListVersions(t *testing.T) {
+	requests := 0
+	client := Storage{
+		name:    "test_bucket",
+		workDir: "prefix",
+		service: &mockS3API{
+			listObjectVersions: func(input *s3.ListObjectVersionsInput) (*s3.ListObjectVersionsOutput, error) {
+				requests++
+				assert.Equal(t, "prefix/report", *input.Prefix)
+
+				if input.KeyMarker == nil {
+					return &s3.ListObjectVersionsOutput{
+						Versions: []*s3.ObjectVersion{
+							{Key: aws.String("prefix/report"), VersionId: aws.String("3")},
+							{Key: aws.String("prefix/report"), VersionId: aws.String("2")},
+						},
+						IsTruncated:         aws.Bool(true),
+						NextKeyMarker:       aws.String("prefix/report"),
+						NextVersionIdMarker: aws.String("2"),
+					}, nil
+				}
+				assert.Equal(t, "2", *input.VersionIdMarker)
+				return &s3.ListObjectVersionsOutput{
+					Versions: []*s3.ObjectVersion{
+						{Key: aws.String("prefix/report"), VersionId: aws.String("1")},
+						{Key: aws.String("prefix/report.bak"), VersionId: aws.String("1")},
+					},
+					IsTruncated:         aws.Bool(true),
+					NextKeyMarker:       aws.String("prefix/report.bak"),
+					NextVersionIdMarker: aws.String("1"),
+				}, nil
+			},
+		},
+	}
+
+	versions := make([]string, 0)
+	err := client.ListVersions("report", pairs.WithFileFunc(func(o *types.Object) {
+		assert.Equal(t, "report", o.Name)
+		versionID, _ := o.GetVersionID()
+		versions = append(versions, versionID)
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3", "2", "1"}, versions)
+	assert.Equal(t, 2, requests)
+}
+
+func TestStorage_Transit(t *testing.T) {
+	cases := []struct {
+		name   string
+		class  *string
+		size   int64
+		copied bool
+		err    error
+	}{
+		{"transit", aws.String(s3.StorageClassStandardIa), 1024, true, nil},
+		{"already in class", aws.String(s3.StorageClassGlacier), 1024, false, nil},
+		{"too large", nil, copyObjectSizeLimit + 1, false, types.ErrNotSupported},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			copied := false
+			client := Storage{
+				name: "test_bucket",
+				service: &mockS3API{
+					headObject: func(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+						assert.Equal(t, "object", aws.StringValue(input.Key))
+						return &s3.HeadObjectOutput{StorageClass: tt.class, ContentLength: aws.Int64(tt.size)}, nil
+					},
+					copyObject: func(input *s3.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+						copied = true
+						assert.Equal(t, s3.StorageClassGlacier, aws.StringValue(input.StorageClass))
+						return &s3.CopyObjectOutput{}, nil
+					},
+				},
+			}
+
+			err := client.Transit("object", storageclass.Cold)
+			assert.Equal(t, tt.copied, copied)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+		})
+	}
+}
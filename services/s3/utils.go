@@ -1,29 +1,129 @@
 package s3
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"strings"
 
+	"github.com/Xuanwo/storage/pkg/checksum"
+	"github.com/Xuanwo/storage/pkg/credential"
 	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/metadata"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
+// handleS3Error will map s3 error codes onto sentinel errors.
+//
+// ref: https://docs.aws.amazon.com/AmazonS3/latest/API/ErrorResponses.html
 func handleS3Error(err error) error {
 	if err == nil {
 		panic("error must not be nil")
 	}
+	// Context errors will be returned as is, so caller could check them via errors.Is.
+	if types.IsContextError(err) {
+		return err
+	}
 
 	var e awserr.Error
 	e, ok := err.(awserr.Error)
 	if !ok {
+		if types.IsTimeout(err) {
+			return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+		}
 		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
 	}
 
+	// Request canceled by context, return context error instead.
+	if e.Code() == request.CanceledErrorCode && types.IsContextError(e.OrigErr()) {
+		return fmt.Errorf("%w: %v", e.OrigErr(), err)
+	}
+
 	switch e.Code() {
+	case "AccessDenied", "AllAccessDisabled", "AccountProblem":
+		return fmt.Errorf("%w: %v", types.ErrPermissionDenied, err)
+	case s3.ErrCodeNoSuchKey, s3.ErrCodeNoSuchBucket, s3.ErrCodeNoSuchUpload, "NotFound":
+		return fmt.Errorf("%w: %v", types.ErrObjectNotExist, err)
+	case "InvalidAccessKeyId", "SignatureDoesNotMatch", "AuthorizationHeaderMalformed", "RequestTimeTooSkewed":
+		return fmt.Errorf("%w: %v", types.ErrConfigIncorrect, err)
+	case s3.ErrCodeBucketAlreadyExists, s3.ErrCodeBucketAlreadyOwnedByYou:
+		return fmt.Errorf("%w: %v", types.ErrObjectAlreadyExist, err)
+	case "BucketNotEmpty":
+		return fmt.Errorf("%w: %v", types.ErrDirNotEmpty, err)
+	case "InvalidStorageClass":
+		return fmt.Errorf("%w: %v", types.ErrStorageClassNotSupported, err)
+	case "PreconditionFailed", "NotModified":
+		return fmt.Errorf("%w: %v", types.ErrPreconditionFailed, err)
+	case "InvalidObjectState":
+		return fmt.Errorf("%w: %v", types.ErrObjectArchived, err)
+	case "TooManyBuckets", "QuotaExceeded":
+		return fmt.Errorf("%w: %v", types.ErrQuotaExceeded, err)
+	case "SlowDown", "Throttling", "ThrottlingException", "RequestLimitExceeded", "TooManyRequests":
+		return fmt.Errorf("%w: %v", types.ErrRateLimited, err)
+	case "RequestTimeout":
+		return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+	case "ServiceUnavailable", "InternalError":
+		return fmt.Errorf("%w: %v", types.ErrServiceUnavailable, err)
+	case "NotImplemented", "MethodNotAllowed":
+		return fmt.Errorf("%w: %v", types.ErrNotSupported, err)
+	}
+
+	// Request failed without a known code (like HEAD request), fallback to status code.
+	if re, ok := err.(awserr.RequestFailure); ok {
+		if se := types.ErrFromStatusCode(re.StatusCode()); se != nil {
+			return fmt.Errorf("%w: %v", se, err)
+		}
+	}
+	if types.IsTimeout(e.OrigErr()) {
+		return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+	}
+	return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+}
+
+// withHeader will set a header which is not supported by the input struct on the request.
+func withHeader(key, value string) request.Option {
+	return func(r *request.Request) {
+		r.HTTPRequest.Header.Set(key, value)
+	}
+}
+
+// newUnixHTTPClient will create a http client which connects to unix socket.
+func newUnixHTTPClient(path string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+// credentialProvider implements credentials.Provider, credential will be refreshed while expired.
+type credentialProvider struct {
+	credential.Provider
+}
+
+// Retrieve implements credentials.Provider
+func (p *credentialProvider) Retrieve() (credentials.Value, error) {
+	v, err := p.Provider.Retrieve()
+	if err != nil {
+		return credentials.Value{}, err
+	}
+
+	switch v.Protocol {
+	case credential.ProtocolHmac:
+		return credentials.Value{AccessKeyID: v.Args[0], SecretAccessKey: v.Args[1]}, nil
+	case credential.ProtocolSTS:
+		return credentials.Value{AccessKeyID: v.Args[0], SecretAccessKey: v.Args[1], SessionToken: v.Args[2]}, nil
 	default:
-		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+		return credentials.Value{}, credential.ErrUnsupportedProtocol
 	}
 }
 
@@ -35,6 +135,102 @@ func (s *Storage) getRelPath(path string) string {
 	return strings.TrimPrefix(path, s.workDir+"/")
 }
 
+// listObjects will list a page of objects under rp after marker.
+//
+// s3 will list keys after StartAfter, so the latest key in current page is the marker for next page.
+func (s *Storage) listObjects(ctx context.Context, rp, marker string) (objects []*types.Object, next string, err error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.name),
+		Prefix:  aws.String(rp),
+		MaxKeys: aws.Int64(1000),
+	}
+	if marker != "" {
+		input.StartAfter = aws.String(marker)
+	}
+
+	output, err := s.service.ListObjectsV2WithContext(ctx, input)
+	if err != nil {
+		return nil, "", handleS3Error(err)
+	}
+
+	objects = make([]*types.Object, 0, len(output.CommonPrefixes)+len(output.Contents))
+	for _, v := range output.CommonPrefixes {
+		o := &types.Object{
+			ID:         *v.Prefix,
+			Name:       s.getRelPath(*v.Prefix),
+			Type:       types.ObjectTypeDir,
+			ObjectMeta: metadata.NewObjectMeta(),
+		}
+		objects = append(objects, o)
+	}
+
+	for _, v := range output.Contents {
+		o := &types.Object{
+			ID:         *v.Key,
+			Type:       types.ObjectTypeFile,
+			Name:       s.getRelPath(*v.Key),
+			Size:       aws.Int64Value(v.Size),
+			UpdatedAt:  aws.TimeValue(v.LastModified),
+			ObjectMeta: metadata.NewObjectMeta(),
+		}
+
+		if v.StorageClass != nil {
+			storageClass, err := formatStorageClass(*v.StorageClass)
+			if err != nil {
+				return nil, "", err
+			}
+			o.SetStorageClass(storageClass)
+		}
+		if v.ETag != nil {
+			o.SetETag(*v.ETag)
+			checksum.FromETag(o.ObjectMeta, *v.ETag)
+		}
+		objects = append(objects, o)
+	}
+
+	if aws.BoolValue(output.IsTruncated) && len(output.Contents) > 0 {
+		next = aws.StringValue(output.Contents[len(output.Contents)-1].Key)
+	}
+	return objects, next, nil
+}
+
+// listKeys implements prefix.ListFunc
+func (s *Storage) listKeys(ctx context.Context, prefix, marker string, limit int) (keys []string, next string, err error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.name),
+		Prefix:  aws.String(prefix),
+		MaxKeys: aws.Int64(int64(limit)),
+	}
+	if marker != "" {
+		input.StartAfter = aws.String(marker)
+	}
+
+	output, err := s.service.ListObjectsV2WithContext(ctx, input)
+	if err != nil {
+		return nil, "", handleS3Error(err)
+	}
+
+	keys = make([]string, 0, len(output.Contents))
+	for _, v := range output.Contents {
+		keys = append(keys, aws.StringValue(v.Key))
+	}
+	if aws.BoolValue(output.IsTruncated) && len(keys) > 0 {
+		next = keys[len(keys)-1]
+	}
+	return keys, next, nil
+}
+
+// formatUserMetadata will format s3 metadata into user metadata.
+//
+// s3 returns metadata keys in canonical header format like "Job-Id", so keys will be converted into lower case.
+func formatUserMetadata(in map[string]*string) map[string]string {
+	m := make(map[string]string, len(in))
+	for k, v := range in {
+		m[strings.ToLower(k)] = aws.StringValue(v)
+	}
+	return m
+}
+
 // parseStorageClass will parse storageclass.Type into service independent storage class type.
 func parseStorageClass(in storageclass.Type) (string, error) {
 	switch in {
@@ -56,10 +252,124 @@ func formatStorageClass(in string) (storageclass.Type, error) {
 		return storageclass.Hot, nil
 	case s3.ObjectStorageClassStandardIa:
 		return storageclass.Warm, nil
-	case s3.ObjectStorageClassGlacier:
+	case s3.ObjectStorageClassGlacier, s3.ObjectStorageClassDeepArchive:
 		return storageclass.Cold, nil
 	default:
 		return "", types.ErrStorageClassNotSupported
 	}
 
 }
+
+// parseRestoreTier will parse storageclass.RestoreTier into s3 tier, empty means s3's default tier.
+func parseRestoreTier(in storageclass.RestoreTier) string {
+	switch in {
+	case storageclass.RestoreTierExpedited:
+		return s3.TierExpedited
+	case storageclass.RestoreTierStandard:
+		return s3.TierStandard
+	case storageclass.RestoreTierBulk:
+		return s3.TierBulk
+	default:
+		return ""
+	}
+}
+
+// formatRestoreStatus will format x-amz-restore header of a Cold object into storageclass.RestoreStatus.
+//
+// The header looks like `ongoing-request="false", expiry-date="Fri, 23 Dec 2012 00:00:00 GMT"`, and it will be
+// missing if the object is not restored.
+func formatRestoreStatus(in string) storageclass.RestoreStatus {
+	switch {
+	case in == "":
+		return storageclass.RestoreStatusArchived
+	case strings.Contains(in, `ongoing-request="true"`):
+		return storageclass.RestoreStatusRestoring
+	default:
+		return storageclass.RestoreStatusRestored
+	}
+}
+
+// parseLifecycleRule will parse types.LifecycleRule into s3 lifecycle rule.
+func parseLifecycleRule(in types.LifecycleRule) (*s3.LifecycleRule, error) {
+	rule := &s3.LifecycleRule{
+		Filter: &s3.LifecycleRuleFilter{Prefix: aws.String(in.Prefix)},
+		Status: aws.String(s3.ExpirationStatusDisabled),
+	}
+	if in.ID != "" {
+		rule.ID = aws.String(in.ID)
+	}
+	if in.Enabled {
+		rule.Status = aws.String(s3.ExpirationStatusEnabled)
+	}
+	if in.ExpirationDays > 0 {
+		rule.Expiration = &s3.LifecycleExpiration{Days: aws.Int64(int64(in.ExpirationDays))}
+	}
+	for _, v := range in.Transitions {
+		storageClass, err := parseStorageClass(v.StorageClass)
+		if err != nil {
+			return nil, err
+		}
+		rule.Transitions = append(rule.Transitions, &s3.Transition{
+			Days:         aws.Int64(int64(v.Days)),
+			StorageClass: aws.String(storageClass),
+		})
+	}
+	if in.AbortIncompleteSegmentDays > 0 {
+		rule.AbortIncompleteMultipartUpload = &s3.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: aws.Int64(int64(in.AbortIncompleteSegmentDays)),
+		}
+	}
+	return rule, nil
+}
+
+// formatLifecycleRule will format s3 lifecycle rule into types.LifecycleRule.
+//
+// Rules filtered by tags, expired by date or acting on noncurrent versions can't be represented.
+func formatLifecycleRule(in *s3.LifecycleRule) (rule types.LifecycleRule, err error) {
+	id := aws.StringValue(in.ID)
+	if in.NoncurrentVersionExpiration != nil || len(in.NoncurrentVersionTransitions) > 0 {
+		return rule, fmt.Errorf("%w: rule %s has noncurrent version actions", types.ErrNotSupported, id)
+	}
+
+	rule.ID = id
+	rule.Enabled = aws.StringValue(in.Status) == s3.ExpirationStatusEnabled
+	// Prefix outside of Filter is deprecated but still returned for old rules.
+	rule.Prefix = aws.StringValue(in.Prefix)
+	if in.Filter != nil {
+		if in.Filter.And != nil || in.Filter.Tag != nil {
+			return rule, fmt.Errorf("%w: rule %s is filtered by tags", types.ErrNotSupported, id)
+		}
+		rule.Prefix = aws.StringValue(in.Filter.Prefix)
+	}
+
+	if v := in.Expiration; v != nil {
+		if v.Date != nil || aws.BoolValue(v.ExpiredObjectDeleteMarker) {
+			return rule, fmt.Errorf("%w: rule %s is not expired by days", types.ErrNotSupported, id)
+		}
+		rule.ExpirationDays = int(aws.Int64Value(v.Days))
+	}
+	for _, v := range in.Transitions {
+		if v.Date != nil {
+			return rule, fmt.Errorf("%w: rule %s is not transited by days", types.ErrNotSupported, id)
+		}
+		storageClass, err := formatStorageClass(aws.StringValue(v.StorageClass))
+		if err != nil {
+			return rule, err
+		}
+		rule.Transitions = append(rule.Transitions, types.LifecycleTransition{
+			Days:         int(aws.Int64Value(v.Days)),
+			StorageClass: storageClass,
+		})
+	}
+	if v := in.AbortIncompleteMultipartUpload; v != nil {
+		rule.AbortIncompleteSegmentDays = int(aws.Int64Value(v.DaysAfterInitiation))
+	}
+	return rule, nil
+}
+
+// isEncrypted will check whether object is encrypted by SSE-KMS or SSE-C, whose etag is not md5 of content.
+//
+// ref: https://docs.aws.amazon.com/AmazonS3/latest/API/RESTCommonResponseHeaders.html
+func isEncrypted(sse, sseCustomerAlgorithm *string) bool {
+	return aws.StringValue(sse) == s3.ServerSideEncryptionAwsKms || aws.StringValue(sseCustomerAlgorithm) != ""
+}
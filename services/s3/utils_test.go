@@ -0,0 +1,125 @@
+package s3
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/pkg/storageclass"
+	"github.com/Xuanwo/storage/types"
+)
+
+func TestHandleS3Error(t *testing.T) {
+	t.Run("nil error will panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			_ = handleS3Error(nil)
+		})
+	})
+
+	cases := []struct {
+		name     string
+		input    error
+		expected error
+	}{
+		{"non-s3 error", errors.New("test"), types.ErrUnhandledError},
+		{"no such key", awserr.New("NoSuchKey", "", nil), types.ErrObjectNotExist},
+		{"bucket already exists", awserr.New("BucketAlreadyOwnedByYou", "", nil), types.ErrObjectAlreadyExist},
+		{"slow down", awserr.New("SlowDown", "", nil), types.ErrRateLimited},
+		{"precondition failed", awserr.New("PreconditionFailed", "", nil), types.ErrPreconditionFailed},
+		{"object archived", awserr.New("InvalidObjectState", "", nil), types.ErrObjectArchived},
+		{
+			"operation aborted",
+			awserr.NewRequestFailure(awserr.New("OperationAborted", "", nil), 409, ""),
+			types.ErrUnhandledError,
+		},
+		{
+			"not modified",
+			awserr.NewRequestFailure(awserr.New("NotModified", "", nil), 304, ""),
+			types.ErrPreconditionFailed,
+		},
+		{
+			"head not found",
+			awserr.NewRequestFailure(awserr.New("NotFound", "", nil), 404, ""),
+			types.ErrObjectNotExist,
+		},
+		{
+			"fallback to status code",
+			awserr.NewRequestFailure(awserr.New("xxxx", "", nil), 503, ""),
+			types.ErrServiceUnavailable,
+		},
+		{"not handled", awserr.New("xxxx", "", nil), types.ErrUnhandledError},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, errors.Is(handleS3Error(tt.input), tt.expected))
+		})
+	}
+}
+
+func TestFormatUserMetadata(t *testing.T) {
+	m := formatUserMetadata(map[string]*string{
+		"Job-Id": aws.String("123"),
+	})
+	assert.Equal(t, map[string]string{"job-id": "123"}, m)
+}
+
+func TestWithHeader(t *testing.T) {
+	r := &request.Request{HTTPRequest: &http.Request{Header: http.Header{}}}
+	r.ApplyOptions(withHeader("If-None-Match", "*"))
+	assert.Equal(t, "*", r.HTTPRequest.Header.Get("If-None-Match"))
+}
+
+func TestLifecycleRule(t *testing.T) {
+	rule := types.LifecycleRule{
+		ID:             "archive",
+		Prefix:         "logs/",
+		Enabled:        true,
+		ExpirationDays: 365,
+		Transitions: []types.LifecycleTransition{
+			{Days: 30, StorageClass: storageclass.Warm},
+			{Days: 90, StorageClass: storageclass.Cold},
+		},
+		AbortIncompleteSegmentDays: 7,
+	}
+
+	in, err := parseLifecycleRule(rule)
+	assert.NoError(t, err)
+	assert.Equal(t, s3.ExpirationStatusEnabled, aws.StringValue(in.Status))
+	assert.Equal(t, s3.TransitionStorageClassGlacier, aws.StringValue(in.Transitions[1].StorageClass))
+
+	out, err := formatLifecycleRule(in)
+	assert.NoError(t, err)
+	assert.Equal(t, rule, out)
+
+	_, err = parseLifecycleRule(types.LifecycleRule{
+		Transitions: []types.LifecycleTransition{{Days: 30, StorageClass: "unknown"}},
+	})
+	assert.True(t, errors.Is(err, types.ErrStorageClassNotSupported))
+
+	_, err = formatLifecycleRule(&s3.LifecycleRule{
+		Filter: &s3.LifecycleRuleFilter{Tag: &s3.Tag{Key: aws.String("k"), Value: aws.String("v")}},
+		Status: aws.String(s3.ExpirationStatusEnabled),
+	})
+	assert.True(t, errors.Is(err, types.ErrNotSupported))
+}
+
+func TestFormatRestoreStatus(t *testing.T) {
+	assert.Equal(t, storageclass.RestoreStatusArchived, formatRestoreStatus(""))
+	assert.Equal(t, storageclass.RestoreStatusRestoring, formatRestoreStatus(`ongoing-request="true"`))
+	assert.Equal(t, storageclass.RestoreStatusRestored,
+		formatRestoreStatus(`ongoing-request="false", expiry-date="Fri, 23 Dec 2012 00:00:00 GMT"`))
+}
+
+func TestIsEncrypted(t *testing.T) {
+	assert.False(t, isEncrypted(nil, nil))
+	assert.False(t, isEncrypted(aws.String(s3.ServerSideEncryptionAes256), nil))
+	assert.True(t, isEncrypted(aws.String(s3.ServerSideEncryptionAwsKms), nil))
+	assert.True(t, isEncrypted(nil, aws.String("AES256")))
+}
@@ -0,0 +1,35 @@
+package uss
+
+import (
+	"github.com/Xuanwo/storage"
+	"github.com/Xuanwo/storage/coreutils"
+	"github.com/Xuanwo/storage/pkg/namespace"
+	"github.com/Xuanwo/storage/types"
+	"github.com/Xuanwo/storage/types/pairs"
+)
+
+func init() {
+	coreutils.Register(Type, open)
+}
+
+// open implements coreutils.OpenFunc
+func open(ns string, opt ...*types.Pair) (srv storage.Servicer, store storage.Storager, err error) {
+	err = coreutils.CheckOptions(opt, pairs.Credential, pairs.WorkDir)
+	if err != nil {
+		return
+	}
+
+	name, prefix := namespace.ParseObjectStorage(ns)
+	store, err = New(name, opt...)
+	if err != nil {
+		return
+	}
+	if prefix != "" {
+		opt = append(opt, pairs.WithWorkDir(prefix))
+	}
+	err = store.Init(opt...)
+	if err != nil {
+		return
+	}
+	return
+}
@@ -5,9 +5,12 @@ import (
 	"io"
 	"strings"
 
+	"github.com/Xuanwo/storage/pkg/checksum"
 	"github.com/Xuanwo/storage/pkg/credential"
+	"github.com/Xuanwo/storage/pkg/iowrap"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/metadata"
+	ps "github.com/Xuanwo/storage/types/pairs"
 	"github.com/upyun/go-sdk/upyun"
 )
 
@@ -22,25 +25,29 @@ type Storage struct {
 }
 
 // New will create a new uss service.
+//
+// Credential will only be retrieved once, because uss only supports operator and password
+// which are fixed in SDK client.
 func New(name string, pairs ...*types.Pair) (s *Storage, err error) {
-	const errorMessage = "%s New: %w"
-
 	s = &Storage{}
 
 	opt, err := parseStoragePairNew(pairs...)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, err)
+		return nil, types.NewError("New", s, "", pairs, err)
 	}
 
-	credProtocol, cred := opt.Credential.Protocol(), opt.Credential.Value()
-	if credProtocol != credential.ProtocolHmac {
-		return nil, fmt.Errorf(errorMessage, s, credential.ErrUnsupportedProtocol)
+	cred, err := opt.Credential.Retrieve()
+	if err != nil {
+		return nil, types.NewError("New", s, "", pairs, err)
+	}
+	if cred.Protocol != credential.ProtocolHmac {
+		return nil, types.NewError("New", s, "", pairs, credential.ErrUnsupportedProtocol)
 	}
 
 	cfg := &upyun.UpYunConfig{
 		Bucket:   name,
-		Operator: cred[0],
-		Password: cred[1],
+		Operator: cred.Args[0],
+		Password: cred.Args[1],
 	}
 	s.bucket = upyun.NewUpYun(cfg)
 	s.name = name
@@ -55,11 +62,9 @@ func (s *Storage) String() string {
 
 // Init implements Storager.Init
 func (s *Storage) Init(pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Init: %w"
-
 	opt, err := parseStoragePairInit(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, err)
+		return types.NewError("Init", s, "", pairs, err)
 	}
 
 	if opt.HasWorkDir {
@@ -80,89 +85,146 @@ func (s *Storage) Metadata(pairs ...*types.Pair) (m metadata.StorageMeta, err er
 
 // List implements Storager.List
 func (s *Storage) List(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s List [%s]: %w"
-
 	opt, err := parseStoragePairList(pairs...)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		return types.NewError("List", s, path, pairs, err)
 	}
 
 	rp := s.getAbsPath(path)
 
+	// ch will be closed by upyun sdk after List returned.
 	ch := make(chan *upyun.FileInfo, 200)
-	defer close(ch)
+	quit := make(chan bool)
+	errc := make(chan error, 1)
 
 	go func() {
-		for v := range ch {
-			o := &types.Object{
-				ID:         v.Name,
-				Name:       s.getRelPath(v.Name),
-				Type:       types.ObjectTypeFile,
-				Size:       v.Size,
-				UpdatedAt:  v.Time,
-				ObjectMeta: metadata.NewObjectMeta(),
+		errc <- s.bucket.List(&upyun.GetObjectsConfig{
+			Path:        rp,
+			ObjectsChan: ch,
+			QuitChan:    quit,
+		})
+	}()
+
+	for v := range ch {
+		if err = opt.Context.Err(); err != nil {
+			// Tell sdk to stop listing and drain ch so that it will not be blocked.
+			close(quit)
+			for range ch {
 			}
-			o.SetETag(v.ETag)
+			return types.NewError("List", s, path, pairs, err)
+		}
+
+		o := &types.Object{
+			ID:         v.Name,
+			Name:       s.getRelPath(v.Name),
+			Type:       types.ObjectTypeFile,
+			Size:       v.Size,
+			UpdatedAt:  v.Time,
+			ObjectMeta: metadata.NewObjectMeta(),
+		}
+		o.SetETag(v.ETag)
+		checksum.FromETag(o.ObjectMeta, v.ETag)
 
+		if opt.HasFileFunc {
 			opt.FileFunc(o)
 		}
-	}()
+	}
 
-	err = s.bucket.List(&upyun.GetObjectsConfig{
-		Path:        rp,
-		ObjectsChan: ch,
-	})
+	err = <-errc
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleUssError(err)
+		return types.NewError("List", s, path, pairs, err)
 	}
 	return
 }
 
 // Read implements Storager.Read
 func (s *Storage) Read(path string, pairs ...*types.Pair) (r io.ReadCloser, err error) {
-	const errorMessage = "%s Read [%s]: %w"
+	opt, err := parseStoragePairRead(pairs...)
+	if err != nil {
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return nil, types.NewError("Read", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
-	r, w := io.Pipe()
-
-	_, err = s.bucket.Get(&upyun.GetObjectConfig{
-		Path:   rp,
-		Writer: w,
-	})
-	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
-	}
-	return r, nil
+	// upyun sdk writes content into Writer directly, so we need to run it in
+	// another goroutine and pass the error to reader side.
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := s.bucket.Get(&upyun.GetObjectConfig{
+			Path:   rp,
+			Writer: pw,
+		})
+		if err != nil {
+			err = handleUssError(err)
+			pw.CloseWithError(types.NewError("Read", s, path, pairs, err))
+			return
+		}
+		pw.Close()
+	}()
+	return iowrap.ContextReadCloser(opt.Context, pr), nil
 }
 
 // Write implements Storager.Write
 func (s *Storage) Write(path string, r io.Reader, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Write [%s]: %w"
+	opt, err := parseStoragePairWrite(pairs...)
+	if err != nil {
+		return types.NewError("Write", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Write", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
 	cfg := &upyun.PutObjectConfig{
 		Path:   rp,
-		Reader: r,
+		Reader: iowrap.ContextReader(opt.Context, r),
+	}
+	cfg.Headers = make(map[string]string)
+	for k, v := range opt.UserMetadata {
+		cfg.Headers[userMetadataPrefix+strings.ToLower(k)] = v
+	}
+	if opt.HasContentType {
+		cfg.Headers["Content-Type"] = opt.ContentType
 	}
 
 	err = s.bucket.Put(cfg)
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleUssError(err)
+		return types.NewError("Write", s, path, pairs, err)
 	}
 	return
 }
 
 // Stat implements Storager.Stat
 func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err error) {
-	const errorMessage = "%s Stat [%s]: %w"
+	opt, err := parseStoragePairStat(pairs...)
+	if err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return nil, types.NewError("Stat", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
-	output, err := s.bucket.GetInfo(rp)
+	output, contentMD5, err := s.getInfo(opt.Context, rp)
 	if err != nil {
-		return nil, fmt.Errorf(errorMessage, s, path, err)
+		err = handleUssError(err)
+		return nil, types.NewError("Stat", s, path, pairs, err)
 	}
 
 	o = &types.Object{
@@ -173,12 +235,28 @@ func (s *Storage) Stat(path string, pairs ...*types.Pair) (o *types.Object, err
 		UpdatedAt:  output.Time,
 		ObjectMeta: metadata.NewObjectMeta(),
 	}
+	if contentMD5 != "" {
+		o.SetContentMD5(contentMD5)
+	}
+	if len(output.Meta) > 0 {
+		o.SetUserMetadata(formatUserMetadata(output.Meta))
+	}
 	return o, nil
 }
 
 // Delete implements Storager.Delete
 func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
-	const errorMessage = "%s Delete [%s]: %w"
+	opt, err := parseStoragePairDelete(pairs...)
+	if err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if opt.HasIfMatch || opt.HasIfNoneMatch || opt.HasIfModifiedSince || opt.HasIfUnmodifiedSince {
+		err = types.NewErrPairNotSupported(ps.IfMatch, ps.IfNoneMatch, ps.IfModifiedSince, ps.IfUnmodifiedSince)
+		return types.NewError("Delete", s, path, pairs, err)
+	}
+	if err = opt.Context.Err(); err != nil {
+		return types.NewError("Delete", s, path, pairs, err)
+	}
 
 	rp := s.getAbsPath(path)
 
@@ -186,7 +264,8 @@ func (s *Storage) Delete(path string, pairs ...*types.Pair) (err error) {
 		Path: rp,
 	})
 	if err != nil {
-		return fmt.Errorf(errorMessage, s, path, err)
+		err = handleUssError(err)
+		return types.NewError("Delete", s, path, pairs, err)
 	}
 	return
 }
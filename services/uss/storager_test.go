@@ -0,0 +1,61 @@
+package uss
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/upyun/go-sdk/upyun"
+
+	"github.com/Xuanwo/storage/types"
+)
+
+func TestStorage_Stat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "UpYun operator:"))
+
+		if r.URL.Path != "/test/prefix/object" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("x-upyun-file-type", "file")
+		w.Header().Set("x-upyun-file-size", "5")
+		w.Header().Set("x-upyun-file-date", "1577808000")
+		w.Header().Set("x-upyun-meta-key", "value")
+		w.Header().Set("Content-Md5", "5D41402ABC4B2A76B9719D911017C592")
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Storage{
+		bucket: upyun.NewUpYun(&upyun.UpYunConfig{
+			Bucket:   "test",
+			Operator: "operator",
+			Password: "password",
+			Hosts:    map[string]string{restHost: u.Host},
+		}),
+		name:    "test",
+		workDir: "prefix",
+	}
+
+	o, err := s.Stat("object")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), o.Size)
+	assert.Equal(t, int64(1577808000), o.UpdatedAt.Unix())
+	md5, ok := o.GetContentMD5()
+	assert.True(t, ok)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", md5)
+	userMetadata, _ := o.GetUserMetadata()
+	assert.Equal(t, map[string]string{"key": "value"}, userMetadata)
+
+	_, err = s.Stat("not_exist")
+	assert.True(t, errors.Is(err, types.ErrObjectNotExist))
+}
@@ -1,7 +1,19 @@
 package uss
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/upyun/go-sdk/upyun"
+
+	"github.com/Xuanwo/storage/types"
 )
 
 func (s *Storage) getAbsPath(path string) string {
@@ -11,3 +23,109 @@ func (s *Storage) getAbsPath(path string) string {
 func (s *Storage) getRelPath(path string) string {
 	return strings.TrimPrefix(path, s.workDir+"/")
 }
+
+// userMetadataPrefix is the header prefix for user metadata, uss returns it in lower case.
+const userMetadataPrefix = "x-upyun-meta-"
+
+// formatUserMetadata will trim userMetadataPrefix from uss metadata keys.
+func formatUserMetadata(in map[string]string) map[string]string {
+	m := make(map[string]string, len(in))
+	for k, v := range in {
+		m[strings.TrimPrefix(k, userMetadataPrefix)] = v
+	}
+	return m
+}
+
+// statusCodeRegexp will match the http method and status code in uss error.
+//
+// uss sdk doesn't have error type, errors are formatted like "getinfo abc: HEAD 404 {...}".
+var statusCodeRegexp = regexp.MustCompile(`\b(?:GET|HEAD|PUT|POST|DELETE) (\d{3})\b`)
+
+// handleUssError will map uss http status codes onto sentinel errors.
+//
+// ref: https://help.upyun.com/knowledge-base/errno/
+func handleUssError(err error) error {
+	if err == nil {
+		panic("error must not be nil")
+	}
+	// Context errors will be returned as is, so caller could check them via errors.Is.
+	if types.IsContextError(err) {
+		return err
+	}
+
+	// uss will refuse to delete a dir which is not empty with message "directory not empty".
+	if strings.Contains(err.Error(), "not empty") {
+		return fmt.Errorf("%w: %v", types.ErrDirNotEmpty, err)
+	}
+
+	m := statusCodeRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		if types.IsTimeout(err) {
+			return fmt.Errorf("%w: %v", types.ErrTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+	}
+
+	code, _ := strconv.Atoi(m[1])
+	if se := types.ErrFromStatusCode(code); se != nil {
+		return fmt.Errorf("%w: %v", se, err)
+	}
+	return fmt.Errorf("%w: %v", types.ErrUnhandledError, err)
+}
+
+// restHost is the host of uss REST API, it could be overwritten via SDK's Hosts.
+const restHost = "v0.api.upyun.com"
+
+// getInfo will get object's info and content md5 via HEAD request.
+//
+// uss SDK's GetInfo drops the Content-Md5 header, so the request will be sent here and
+// signed via SDK.
+//
+// ref: https://help.upyun.com/knowledge-base/rest_api/#e88eb7e58f96e69687e4bbb6e4bfa1e681af
+func (s *Storage) getInfo(ctx context.Context, rp string) (fi *upyun.FileInfo, contentMD5 string, err error) {
+	uri := (&url.URL{Path: path.Join("/", s.bucket.Bucket, rp)}).EscapedPath()
+	host := restHost
+	if v := s.bucket.Hosts[restHost]; v != "" {
+		host = v
+	}
+
+	req, err := http.NewRequest(http.MethodHead, "http://"+host+uri, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	date := time.Now().UTC().Format(http.TimeFormat)
+	req.Header.Set("Date", date)
+	req.Header.Set("Authorization", s.bucket.MakeUnifiedAuth(&upyun.UnifiedAuthConfig{
+		Method:  http.MethodHead,
+		Uri:     uri,
+		DateStr: date,
+	}))
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Keep the same format with SDK's errors, so that handleUssError could handle it.
+		return nil, "", fmt.Errorf("getinfo %s: HEAD %d", rp, resp.StatusCode)
+	}
+
+	fi = &upyun.FileInfo{
+		Name:  rp,
+		IsDir: resp.Header.Get("x-upyun-file-type") == "folder",
+	}
+	fi.Size, _ = strconv.ParseInt(resp.Header.Get("x-upyun-file-size"), 10, 64)
+	if v, err := strconv.ParseInt(resp.Header.Get("x-upyun-file-date"), 10, 64); err == nil {
+		fi.Time = time.Unix(v, 0)
+	}
+	for k, v := range resp.Header {
+		if k = strings.ToLower(k); strings.HasPrefix(k, userMetadataPrefix) {
+			if fi.Meta == nil {
+				fi.Meta = make(map[string]string)
+			}
+			fi.Meta[k] = v[0]
+		}
+	}
+	return fi, strings.ToLower(resp.Header.Get("Content-Md5")), nil
+}
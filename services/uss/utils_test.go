@@ -0,0 +1,29 @@
+package uss
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Xuanwo/storage/types"
+)
+
+func TestHandleUssError(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    error
+		expected error
+	}{
+		{"not found", errors.New("getinfo abc: HEAD 404 "), types.ErrObjectNotExist},
+		{"rate limited", errors.New(`doRESTRequest: PUT 429 {"code": 42900001}`), types.ErrRateLimited},
+		{"dir not empty", errors.New(`doRESTRequest: DELETE 403 {"msg": "directory not empty"}`), types.ErrDirNotEmpty},
+		{"no status code", errors.New("test"), types.ErrUnhandledError},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, errors.Is(handleUssError(tt.input), tt.expected))
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"io"
 
+	"github.com/Xuanwo/storage/pkg/iterator"
+	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types"
 	"github.com/Xuanwo/storage/types/metadata"
 )
@@ -80,6 +82,11 @@ type Storager interface {
 	// StatWithContext will stat a path to get info of an object.
 	StatWithContext(ctx context.Context, path string, pairs ...*types.Pair) (o *types.Object, err error)
 	// Delete will delete an Object from service.
+	//
+	// Implementer:
+	//   - MUST return ErrDirNotEmpty while deleting a non-empty Dir.
+	// Caller:
+	//   - SHOULD use RecursiveDeleter to delete a Dir with all Objects under it.
 	Delete(path string, pairs ...*types.Pair) (err error)
 	// DeleteWithContext will delete an Object from service.
 	DeleteWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error)
@@ -112,6 +119,20 @@ type Servicer interface {
 	DeleteWithContext(ctx context.Context, name string, pairs ...*types.Pair) (err error)
 }
 
+// Iterable is the interface for Iterate.
+type Iterable interface {
+	// Iterate will return an iterator which lists a specific path page by page.
+	//
+	// Implementer:
+	//   - MUST return objects in the same way as List, both Dir and File will be returned.
+	//   - MUST resume from the position which continuation_token points to.
+	// Caller:
+	//   - SHOULD call Next until iterator.Done returned.
+	Iterate(path string, pairs ...*types.Pair) (it *iterator.ObjectIterator, err error)
+	// IterateWithContext will return an iterator which lists a specific path page by page.
+	IterateWithContext(ctx context.Context, path string, pairs ...*types.Pair) (it *iterator.ObjectIterator, err error)
+}
+
 // Copier is the interface for Copy.
 type Copier interface {
 	// Copy will copy an Object or multiple object in the service.
@@ -128,6 +149,157 @@ type Mover interface {
 	MoveWithContext(ctx context.Context, src, dst string, pairs ...*types.Pair) (err error)
 }
 
+// BatchDeleter is the interface for DeleteBatch.
+type BatchDeleter interface {
+	// DeleteBatch will delete multiple objects from service.
+	//
+	// Implementer:
+	//   - MUST return a result for every path in the same order as paths.
+	//   - SHOULD split paths into multiple requests while exceeding service's limit.
+	//   - SHOULD only return err while the whole operation failed, like context canceled.
+	// Caller:
+	//   - SHOULD check Err in every result.
+	DeleteBatch(paths []string, pairs ...*types.Pair) (results []types.DeleteResult, err error)
+	// DeleteBatchWithContext will delete multiple objects from service.
+	DeleteBatchWithContext(ctx context.Context, paths []string, pairs ...*types.Pair) (results []types.DeleteResult, err error)
+}
+
+// RecursiveDeleter is the interface for DeleteAll.
+type RecursiveDeleter interface {
+	// DeleteAll will delete a path and all Objects under it, like os.RemoveAll.
+	//
+	// Implementer:
+	//   - MUST treat path as a Dir, and delete the File with the same name if exists.
+	//   - MUST NOT return error if path doesn't exist.
+	//   - MUST return ErrRootNotAllowed while path is empty or root, like checked by types.CheckNotRoot.
+	//   - SHOULD use batch delete while service supports it.
+	// Caller:
+	//   - SHOULD NOT expect DeleteAll to be atomic, Objects could be partially deleted while error happened.
+	DeleteAll(path string, pairs ...*types.Pair) (err error)
+	// DeleteAllWithContext will delete a path and all Objects under it, like os.RemoveAll.
+	DeleteAllWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error)
+}
+
+// Versioner is the interface for object versioning.
+type Versioner interface {
+	// ListVersions will list all versions of a File.
+	//
+	// Implementer:
+	//   - MUST call file_func for every version with version-id set in object meta.
+	//   - SHOULD return versions from the newest to the oldest.
+	//   - SHOULD NOT return delete markers.
+	// Caller:
+	//   - SHOULD use version_id pair in Read, Stat and Delete to operate on a specific version.
+	ListVersions(path string, pairs ...*types.Pair) (err error)
+	// ListVersionsWithContext will list all versions of a File.
+	ListVersionsWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error)
+}
+
+// Tagger is the interface for object tagging.
+type Tagger interface {
+	// GetTags will get all tags of a File.
+	//
+	// Implementer:
+	//   - MUST return an empty map while File doesn't have any tag.
+	GetTags(path string, pairs ...*types.Pair) (tags map[string]string, err error)
+	// GetTagsWithContext will get all tags of a File.
+	GetTagsWithContext(ctx context.Context, path string, pairs ...*types.Pair) (tags map[string]string, err error)
+	// SetTags will set tags of a File.
+	//
+	// Implementer:
+	//   - MUST replace all existing tags with the given tags.
+	// Caller:
+	//   - SHOULD use tags pair in Write to set tags while creating a File.
+	SetTags(path string, tags map[string]string, pairs ...*types.Pair) (err error)
+	// SetTagsWithContext will set tags of a File.
+	SetTagsWithContext(ctx context.Context, path string, tags map[string]string, pairs ...*types.Pair) (err error)
+	// DeleteTags will delete all tags of a File.
+	DeleteTags(path string, pairs ...*types.Pair) (err error)
+	// DeleteTagsWithContext will delete all tags of a File.
+	DeleteTagsWithContext(ctx context.Context, path string, pairs ...*types.Pair) (err error)
+}
+
+// LifecycleManager is the interface for bucket lifecycle rules.
+type LifecycleManager interface {
+	// GetLifecycle will get all lifecycle rules of the bucket.
+	//
+	// Implementer:
+	//   - MUST return an empty slice while bucket doesn't have any rule.
+	//   - MUST return ErrNotSupported while a rule can't be represented by LifecycleRule.
+	GetLifecycle(pairs ...*types.Pair) (rules []types.LifecycleRule, err error)
+	// GetLifecycleWithContext will get all lifecycle rules of the bucket.
+	GetLifecycleWithContext(ctx context.Context, pairs ...*types.Pair) (rules []types.LifecycleRule, err error)
+	// SetLifecycle will set lifecycle rules of the bucket.
+	//
+	// Implementer:
+	//   - MUST replace all existing rules with the given rules.
+	//   - MUST delete all rules while rules is empty.
+	//   - MUST return ErrNotSupported while a rule can't be represented by service.
+	// Caller:
+	//   - SHOULD get rules via GetLifecycle and modify them to keep rules set by others.
+	SetLifecycle(rules []types.LifecycleRule, pairs ...*types.Pair) (err error)
+	// SetLifecycleWithContext will set lifecycle rules of the bucket.
+	SetLifecycleWithContext(ctx context.Context, rules []types.LifecycleRule, pairs ...*types.Pair) (err error)
+	// DeleteLifecycle will delete all lifecycle rules of the bucket.
+	DeleteLifecycle(pairs ...*types.Pair) (err error)
+	// DeleteLifecycleWithContext will delete all lifecycle rules of the bucket.
+	DeleteLifecycleWithContext(ctx context.Context, pairs ...*types.Pair) (err error)
+}
+
+// Restorer is the interface for restoring Cold Files.
+type Restorer interface {
+	// Restore will restore a Cold File temporarily, so that it could be read.
+	//
+	// Implementer:
+	//   - SHOULD keep the restored File for days, services which always restore for a fixed time could ignore it.
+	//   - SHOULD use service's default tier while tier is empty or not supported.
+	//   - SHOULD NOT return error while the File is being restored or has been restored.
+	// Caller:
+	//   - SHOULD check restore-status in object meta via Stat, restoring could take minutes to hours.
+	Restore(path string, days int, tier storageclass.RestoreTier, pairs ...*types.Pair) (err error)
+	// RestoreWithContext will restore a Cold File temporarily, so that it could be read.
+	RestoreWithContext(ctx context.Context, path string, days int, tier storageclass.RestoreTier, pairs ...*types.Pair) (err error)
+}
+
+// Transiter is the interface for changing storage class of existing Files.
+type Transiter interface {
+	// Transit will change the storage class of a File in place.
+	//
+	// Implementer:
+	//   - MUST use service's native way which doesn't download and upload the File again.
+	//   - SHOULD keep the File's metadata.
+	// Caller:
+	//   - SHOULD restore Cold Files via Restorer before transiting them out of Cold in some services.
+	Transit(path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error)
+	// TransitWithContext will change the storage class of a File in place.
+	TransitWithContext(ctx context.Context, path string, storageClass storageclass.Type, pairs ...*types.Pair) (err error)
+}
+
+// Appender is the interface for appending data to a File.
+type Appender interface {
+	// InitAppend will create an appendable File if not exist, and return the next append position.
+	//
+	// Implementer:
+	//   - MUST return the File's size as offset while the appendable File already exists.
+	//   - MUST return ErrObjectAlreadyExist while a File which is not appendable exists.
+	// Caller:
+	//   - SHOULD call InitAppend to get the offset while resuming appending, like after crash.
+	InitAppend(path string, pairs ...*types.Pair) (offset int64, err error)
+	// InitAppendWithContext will create an appendable File if not exist, and return the next append position.
+	InitAppendWithContext(ctx context.Context, path string, pairs ...*types.Pair) (offset int64, err error)
+	// WriteAppend will append all data read from r to a File at offset, and return the next append position.
+	//
+	// Implementer:
+	//   - MUST return ErrPreconditionFailed while offset is not the File's size.
+	//   - SHOULD split data into multiple requests while exceeding service's limit.
+	// Caller:
+	//   - SHOULD NOT append to the same File concurrently.
+	//   - SHOULD call InitAppend to get the offset again while WriteAppend failed, data could be partially appended.
+	WriteAppend(path string, offset int64, r io.Reader, pairs ...*types.Pair) (next int64, err error)
+	// WriteAppendWithContext will append all data read from r to a File at offset, and return the next append position.
+	WriteAppendWithContext(ctx context.Context, path string, offset int64, r io.Reader, pairs ...*types.Pair) (next int64, err error)
+}
+
 // Reacher is the interface for Reach.
 type Reacher interface {
 	// Reach will provide a way, which can reach the object.
@@ -1,8 +1,14 @@
 package types
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
 )
 
 // All possible error could be return by services.
@@ -16,6 +22,19 @@ var (
 	ErrObjectNotExist           = errors.New("object not exist")
 	ErrStorageClassNotSupported = errors.New("storage class not supported")
 	ErrDirNotEmpty              = errors.New("dir not empty")
+	ErrChecksumMismatch         = errors.New("checksum mismatch")
+	ErrChecksumNotAvailable     = errors.New("checksum not available")
+	ErrObjectAlreadyExist       = errors.New("object already exist")
+	ErrNotSupported             = errors.New("operation not supported")
+	ErrPreconditionFailed       = errors.New("precondition failed")
+	ErrQuotaExceeded            = errors.New("quota exceeded")
+	ErrObjectArchived           = errors.New("object archived")
+	ErrRootNotAllowed           = errors.New("root not allowed")
+
+	// retryable error
+	ErrRateLimited        = errors.New("rate limited")
+	ErrTimeout            = errors.New("timeout")
+	ErrServiceUnavailable = errors.New("service unavailable")
 
 	// unhandleable error
 	ErrUnhandledError = errors.New("unhandled error")
@@ -25,3 +44,154 @@ var (
 func NewErrPairRequired(pair string) error {
 	return fmt.Errorf("%s is required but missing: %w", pair, ErrPairRequired)
 }
+
+// NewErrPairNotSupported will create a new not supported error for pairs which are declared but can't be
+// handled by service, so that they will not be ignored silently.
+func NewErrPairNotSupported(pairs ...string) error {
+	return fmt.Errorf("%s not supported: %w", strings.Join(pairs, ", "), ErrNotSupported)
+}
+
+// CheckNotRoot will return ErrRootNotAllowed if p refers to the root of work dir, it's used to prevent
+// destructive operations like DeleteAll from removing the whole work dir by mistake.
+func CheckNotRoot(p string) error {
+	if path.Clean("/"+p) == "/" {
+		return ErrRootNotAllowed
+	}
+	return nil
+}
+
+// IsRetryable will check whether err could be recovered by retrying later.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrTimeout) ||
+		errors.Is(err, ErrServiceUnavailable)
+}
+
+// ErrFromStatusCode will return the sentinel error for http status code, nil will be returned
+// if status code doesn't match any of them.
+//
+// Services should check their error codes first, and use this as fallback. Not Modified is treated
+// as precondition failed, because it's only returned while if_none_match or if_modified_since not met.
+// Conflict is not mapped, because it's used for many different conflicts besides already exists, services
+// should map their "already exists" error codes by themselves.
+func ErrFromStatusCode(code int) error {
+	switch code {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrPermissionDenied
+	case http.StatusNotFound:
+		return ErrObjectNotExist
+	case http.StatusPreconditionFailed, http.StatusNotModified:
+		return ErrPreconditionFailed
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrTimeout
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusNotImplemented, http.StatusMethodNotAllowed:
+		return ErrNotSupported
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return ErrServiceUnavailable
+	case http.StatusInsufficientStorage:
+		return ErrQuotaExceeded
+	default:
+		return nil
+	}
+}
+
+// IsTimeout will check whether err is caused by timeout, like context deadline exceeded
+// or net.Error's timeout.
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var e net.Error
+	return errors.As(err, &e) && e.Timeout()
+}
+
+// IsContextError will check whether err is caused by context canceled or deadline exceeded.
+//
+// Services will return context errors as is, so caller could check them via errors.Is.
+func IsContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Error is the error returned by services, which carries the details of failed operation.
+//
+// Caller could use errors.As to get an Error, and errors.Is will still work for sentinel errors
+// via Unwrap.
+type Error struct {
+	// Op is the operation name, like "Read", "Write".
+	Op string
+	// Service is the description of servicer or storager which returns this error.
+	Service string
+	// Path is the path of object, or name of bucket, or id of segment, empty if not available.
+	Path string
+	// Dst is the destination path for Copy and Move, empty for other operations.
+	Dst string
+	// Pairs is the summary of pairs passed to this operation.
+	//
+	// Only pairs with basic value will be included with their values, others like credential,
+	// context and funcs only have their keys.
+	Pairs string
+	// Err is the underlying error.
+	Err error
+}
+
+// NewError will create a new Error.
+func NewError(op string, s fmt.Stringer, path string, ps []*Pair, err error) *Error {
+	return &Error{
+		Op:      op,
+		Service: fmt.Sprint(s),
+		Path:    path,
+		Pairs:   FormatPairs(ps),
+		Err:     err,
+	}
+}
+
+// NewErrorWithDst will create a new Error for operations like Copy and Move.
+func NewErrorWithDst(op string, s fmt.Stringer, src, dst string, ps []*Pair, err error) *Error {
+	e := NewError(op, s, src, ps, err)
+	e.Dst = dst
+	return e
+}
+
+// Error implements error.Error
+func (e *Error) Error() string {
+	switch {
+	case e.Dst != "":
+		return fmt.Sprintf("%s %s from [%s] to [%s]: %v", e.Service, e.Op, e.Path, e.Dst, e.Err)
+	case e.Path != "":
+		return fmt.Sprintf("%s %s [%s]: %v", e.Service, e.Op, e.Path, e.Err)
+	default:
+		return fmt.Sprintf("%s %s: %v", e.Service, e.Op, e.Err)
+	}
+}
+
+// Unwrap implements errors.Unwrap
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// FormatPairs will format pairs into a summary like "location=pek3b, credential".
+//
+// Only values with basic kind will be formatted to prevent leaking secrets.
+func FormatPairs(ps []*Pair) string {
+	s := make([]string, 0, len(ps))
+	for _, v := range ps {
+		if v == nil {
+			continue
+		}
+		if v.Value == nil {
+			s = append(s, v.Key)
+			continue
+		}
+		switch reflect.TypeOf(v.Value).Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			s = append(s, fmt.Sprintf("%s=%v", v.Key, v.Value))
+		default:
+			s = append(s, v.Key)
+		}
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testStringer string
+
+func (s testStringer) String() string {
+	return string(s)
+}
+
+func TestError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      *Error
+		expected string
+	}{
+		{
+			"without path",
+			NewError("List", testStringer("Servicer test"), "", nil, ErrPermissionDenied),
+			"Servicer test List: permission denied",
+		},
+		{
+			"with path",
+			NewError("Read", testStringer("Storager test"), "abc", nil, ErrObjectNotExist),
+			"Storager test Read [abc]: object not exist",
+		},
+		{
+			"with dst",
+			NewErrorWithDst("Copy", testStringer("Storager test"), "abc", "def", nil, ErrObjectNotExist),
+			"Storager test Copy from [abc] to [def]: object not exist",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.err.Error())
+
+			err := fmt.Errorf("wrapped: %w", tt.err)
+			e := &Error{}
+			assert.True(t, errors.As(err, &e))
+			assert.Equal(t, tt.err, e)
+			assert.True(t, errors.Is(err, tt.err.Err))
+		})
+	}
+}
+
+func TestFormatPairs(t *testing.T) {
+	ps := []*Pair{
+		{Key: "location", Value: "pek3b"},
+		{Key: "size", Value: int64(1024)},
+		{Key: "verify_checksum", Value: true},
+		{Key: "context", Value: context.Background()},
+		{Key: "file_func", Value: ObjectFunc(func(*Object) {})},
+	}
+
+	assert.Equal(t, "location=pek3b, size=1024, verify_checksum=true, context, file_func", FormatPairs(ps))
+	assert.Equal(t, "", FormatPairs(nil))
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrFromStatusCode(t *testing.T) {
+	cases := []struct {
+		code     int
+		expected error
+	}{
+		{403, ErrPermissionDenied},
+		{404, ErrObjectNotExist},
+		{409, nil},
+		{304, ErrPreconditionFailed},
+		{412, ErrPreconditionFailed},
+		{429, ErrRateLimited},
+		{501, ErrNotSupported},
+		{503, ErrServiceUnavailable},
+		{504, ErrTimeout},
+		{200, nil},
+		{444, nil},
+	}
+
+	for _, tt := range cases {
+		t.Run(fmt.Sprint(tt.code), func(t *testing.T) {
+			assert.Equal(t, tt.expected, ErrFromStatusCode(tt.code))
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(fmt.Errorf("%w: slow down", ErrRateLimited)))
+	assert.True(t, IsRetryable(NewError("Read", testStringer("Storager test"), "abc", nil, ErrServiceUnavailable)))
+	assert.False(t, IsRetryable(ErrPermissionDenied))
+	assert.False(t, IsRetryable(nil))
+}
+
+func TestIsTimeout(t *testing.T) {
+	assert.True(t, IsTimeout(context.DeadlineExceeded))
+	assert.True(t, IsTimeout(fmt.Errorf("read: %w", timeoutError{})))
+	assert.False(t, IsTimeout(errors.New("test")))
+	assert.False(t, IsTimeout(nil))
+}
+
+func TestCheckNotRoot(t *testing.T) {
+	for _, v := range []string{"", "/", ".", "./", "a/.."} {
+		assert.True(t, errors.Is(CheckNotRoot(v), ErrRootNotAllowed), v)
+	}
+	for _, v := range []string{"a", "a/", "/a/b"} {
+		assert.NoError(t, CheckNotRoot(v), v)
+	}
+}
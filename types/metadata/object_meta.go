@@ -2,19 +2,114 @@
 package metadata
 
 import (
+	"time"
+
 	"github.com/Xuanwo/storage/pkg/storageclass"
 )
 
 var _ storageclass.Type
+var _ time.Time
 
 // All available metadata.
 const (
-	ObjectMetaContentMD5   = "content-md5"
-	ObjectMetaContentType  = "content-type"
-	ObjectMetaETag         = "etag"
-	ObjectMetaStorageClass = "storage-class"
+	ObjectMetaCacheControl       = "cache-control"
+	ObjectMetaContentCRC32C      = "content-crc32c"
+	ObjectMetaContentDisposition = "content-disposition"
+	ObjectMetaContentEncoding    = "content-encoding"
+	ObjectMetaContentMD5         = "content-md5"
+	ObjectMetaContentSHA256      = "content-sha256"
+	ObjectMetaContentType        = "content-type"
+	ObjectMetaDropboxContentHash = "dropbox-content-hash"
+	ObjectMetaETag               = "etag"
+	ObjectMetaExpires            = "expires"
+	ObjectMetaMultipartETag      = "multipart-etag"
+	ObjectMetaQiniuETag          = "qiniu-etag"
+	ObjectMetaRestoreStatus      = "restore-status"
+	ObjectMetaStorageClass       = "storage-class"
+	ObjectMetaUserMetadata       = "user-metadata"
+	ObjectMetaVersionID          = "version-id"
 )
 
+// GetCacheControl will get cache-control value from metadata.
+func (m ObjectMeta) GetCacheControl() (string, bool) {
+	v, ok := m.m[ObjectMetaCacheControl]
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// MustGetCacheControl will get cache-control value from metadata.
+func (m ObjectMeta) MustGetCacheControl() string {
+	return m.m[ObjectMetaCacheControl].(string)
+}
+
+// SetCacheControl will set cache-control value into metadata.
+func (m ObjectMeta) SetCacheControl(v string) ObjectMeta {
+	m.m[ObjectMetaCacheControl] = v
+	return m
+}
+
+// GetContentCRC32C will get content-crc32c value from metadata.
+func (m ObjectMeta) GetContentCRC32C() (string, bool) {
+	v, ok := m.m[ObjectMetaContentCRC32C]
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// MustGetContentCRC32C will get content-crc32c value from metadata.
+func (m ObjectMeta) MustGetContentCRC32C() string {
+	return m.m[ObjectMetaContentCRC32C].(string)
+}
+
+// SetContentCRC32C will set content-crc32c value into metadata.
+func (m ObjectMeta) SetContentCRC32C(v string) ObjectMeta {
+	m.m[ObjectMetaContentCRC32C] = v
+	return m
+}
+
+// GetContentDisposition will get content-disposition value from metadata.
+func (m ObjectMeta) GetContentDisposition() (string, bool) {
+	v, ok := m.m[ObjectMetaContentDisposition]
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// MustGetContentDisposition will get content-disposition value from metadata.
+func (m ObjectMeta) MustGetContentDisposition() string {
+	return m.m[ObjectMetaContentDisposition].(string)
+}
+
+// SetContentDisposition will set content-disposition value into metadata.
+func (m ObjectMeta) SetContentDisposition(v string) ObjectMeta {
+	m.m[ObjectMetaContentDisposition] = v
+	return m
+}
+
+// GetContentEncoding will get content-encoding value from metadata.
+func (m ObjectMeta) GetContentEncoding() (string, bool) {
+	v, ok := m.m[ObjectMetaContentEncoding]
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// MustGetContentEncoding will get content-encoding value from metadata.
+func (m ObjectMeta) MustGetContentEncoding() string {
+	return m.m[ObjectMetaContentEncoding].(string)
+}
+
+// SetContentEncoding will set content-encoding value into metadata.
+func (m ObjectMeta) SetContentEncoding(v string) ObjectMeta {
+	m.m[ObjectMetaContentEncoding] = v
+	return m
+}
+
 // GetContentMD5 will get content-md5 value from metadata.
 func (m ObjectMeta) GetContentMD5() (string, bool) {
 	v, ok := m.m[ObjectMetaContentMD5]
@@ -35,6 +130,26 @@ func (m ObjectMeta) SetContentMD5(v string) ObjectMeta {
 	return m
 }
 
+// GetContentSHA256 will get content-sha256 value from metadata.
+func (m ObjectMeta) GetContentSHA256() (string, bool) {
+	v, ok := m.m[ObjectMetaContentSHA256]
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// MustGetContentSHA256 will get content-sha256 value from metadata.
+func (m ObjectMeta) MustGetContentSHA256() string {
+	return m.m[ObjectMetaContentSHA256].(string)
+}
+
+// SetContentSHA256 will set content-sha256 value into metadata.
+func (m ObjectMeta) SetContentSHA256(v string) ObjectMeta {
+	m.m[ObjectMetaContentSHA256] = v
+	return m
+}
+
 // GetContentType will get content-type value from metadata.
 func (m ObjectMeta) GetContentType() (string, bool) {
 	v, ok := m.m[ObjectMetaContentType]
@@ -55,6 +170,26 @@ func (m ObjectMeta) SetContentType(v string) ObjectMeta {
 	return m
 }
 
+// GetDropboxContentHash will get dropbox-content-hash value from metadata.
+func (m ObjectMeta) GetDropboxContentHash() (string, bool) {
+	v, ok := m.m[ObjectMetaDropboxContentHash]
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// MustGetDropboxContentHash will get dropbox-content-hash value from metadata.
+func (m ObjectMeta) MustGetDropboxContentHash() string {
+	return m.m[ObjectMetaDropboxContentHash].(string)
+}
+
+// SetDropboxContentHash will set dropbox-content-hash value into metadata.
+func (m ObjectMeta) SetDropboxContentHash(v string) ObjectMeta {
+	m.m[ObjectMetaDropboxContentHash] = v
+	return m
+}
+
 // GetETag will get etag value from metadata.
 func (m ObjectMeta) GetETag() (string, bool) {
 	v, ok := m.m[ObjectMetaETag]
@@ -75,6 +210,86 @@ func (m ObjectMeta) SetETag(v string) ObjectMeta {
 	return m
 }
 
+// GetExpires will get expires value from metadata.
+func (m ObjectMeta) GetExpires() (time.Time, bool) {
+	v, ok := m.m[ObjectMetaExpires]
+	if !ok {
+		return time.Time{}, false
+	}
+	return v.(time.Time), true
+}
+
+// MustGetExpires will get expires value from metadata.
+func (m ObjectMeta) MustGetExpires() time.Time {
+	return m.m[ObjectMetaExpires].(time.Time)
+}
+
+// SetExpires will set expires value into metadata.
+func (m ObjectMeta) SetExpires(v time.Time) ObjectMeta {
+	m.m[ObjectMetaExpires] = v
+	return m
+}
+
+// GetMultipartETag will get multipart-etag value from metadata.
+func (m ObjectMeta) GetMultipartETag() (string, bool) {
+	v, ok := m.m[ObjectMetaMultipartETag]
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// MustGetMultipartETag will get multipart-etag value from metadata.
+func (m ObjectMeta) MustGetMultipartETag() string {
+	return m.m[ObjectMetaMultipartETag].(string)
+}
+
+// SetMultipartETag will set multipart-etag value into metadata.
+func (m ObjectMeta) SetMultipartETag(v string) ObjectMeta {
+	m.m[ObjectMetaMultipartETag] = v
+	return m
+}
+
+// GetQiniuETag will get qiniu-etag value from metadata.
+func (m ObjectMeta) GetQiniuETag() (string, bool) {
+	v, ok := m.m[ObjectMetaQiniuETag]
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// MustGetQiniuETag will get qiniu-etag value from metadata.
+func (m ObjectMeta) MustGetQiniuETag() string {
+	return m.m[ObjectMetaQiniuETag].(string)
+}
+
+// SetQiniuETag will set qiniu-etag value into metadata.
+func (m ObjectMeta) SetQiniuETag(v string) ObjectMeta {
+	m.m[ObjectMetaQiniuETag] = v
+	return m
+}
+
+// GetRestoreStatus will get restore-status value from metadata.
+func (m ObjectMeta) GetRestoreStatus() (storageclass.RestoreStatus, bool) {
+	v, ok := m.m[ObjectMetaRestoreStatus]
+	if !ok {
+		return "", false
+	}
+	return v.(storageclass.RestoreStatus), true
+}
+
+// MustGetRestoreStatus will get restore-status value from metadata.
+func (m ObjectMeta) MustGetRestoreStatus() storageclass.RestoreStatus {
+	return m.m[ObjectMetaRestoreStatus].(storageclass.RestoreStatus)
+}
+
+// SetRestoreStatus will set restore-status value into metadata.
+func (m ObjectMeta) SetRestoreStatus(v storageclass.RestoreStatus) ObjectMeta {
+	m.m[ObjectMetaRestoreStatus] = v
+	return m
+}
+
 // GetStorageClass will get storage-class value from metadata.
 func (m ObjectMeta) GetStorageClass() (storageclass.Type, bool) {
 	v, ok := m.m[ObjectMetaStorageClass]
@@ -94,3 +309,43 @@ func (m ObjectMeta) SetStorageClass(v storageclass.Type) ObjectMeta {
 	m.m[ObjectMetaStorageClass] = v
 	return m
 }
+
+// GetUserMetadata will get user-metadata value from metadata.
+func (m ObjectMeta) GetUserMetadata() (map[string]string, bool) {
+	v, ok := m.m[ObjectMetaUserMetadata]
+	if !ok {
+		return nil, false
+	}
+	return v.(map[string]string), true
+}
+
+// MustGetUserMetadata will get user-metadata value from metadata.
+func (m ObjectMeta) MustGetUserMetadata() map[string]string {
+	return m.m[ObjectMetaUserMetadata].(map[string]string)
+}
+
+// SetUserMetadata will set user-metadata value into metadata.
+func (m ObjectMeta) SetUserMetadata(v map[string]string) ObjectMeta {
+	m.m[ObjectMetaUserMetadata] = v
+	return m
+}
+
+// GetVersionID will get version-id value from metadata.
+func (m ObjectMeta) GetVersionID() (string, bool) {
+	v, ok := m.m[ObjectMetaVersionID]
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// MustGetVersionID will get version-id value from metadata.
+func (m ObjectMeta) MustGetVersionID() string {
+	return m.m[ObjectMetaVersionID].(string)
+}
+
+// SetVersionID will set version-id value into metadata.
+func (m ObjectMeta) SetVersionID(v string) ObjectMeta {
+	m.m[ObjectMetaVersionID] = v
+	return m
+}
@@ -2,16 +2,40 @@
 package metadata
 
 import (
+	"time"
+
 	"github.com/Xuanwo/storage/pkg/storageclass"
 )
 
 var _ storageclass.Type
+var _ time.Time
 
 // All available metadata.
 const (
+	StorageMetaFlatList = "flat-list"
 	StorageMetaLocation = "location"
 )
 
+// GetFlatList will get flat-list value from metadata.
+func (m StorageMeta) GetFlatList() (bool, bool) {
+	v, ok := m.m[StorageMetaFlatList]
+	if !ok {
+		return false, false
+	}
+	return v.(bool), true
+}
+
+// MustGetFlatList will get flat-list value from metadata.
+func (m StorageMeta) MustGetFlatList() bool {
+	return m.m[StorageMetaFlatList].(bool)
+}
+
+// SetFlatList will set flat-list value into metadata.
+func (m StorageMeta) SetFlatList(v bool) StorageMeta {
+	m.m[StorageMetaFlatList] = v
+	return m
+}
+
 // GetLocation will get location value from metadata.
 func (m StorageMeta) GetLocation() (string, bool) {
 	v, ok := m.m[StorageMetaLocation]
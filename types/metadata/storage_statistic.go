@@ -2,10 +2,13 @@
 package metadata
 
 import (
+	"time"
+
 	"github.com/Xuanwo/storage/pkg/storageclass"
 )
 
 var _ storageclass.Type
+var _ time.Time
 
 // All available metadata.
 const (
@@ -3,6 +3,7 @@ package pairs
 
 import (
 	"context"
+	"time"
 
 	"github.com/Xuanwo/storage"
 	"github.com/Xuanwo/storage/pkg/credential"
@@ -14,26 +15,50 @@ import (
 
 // All available pairs.
 const (
-	Checksum     = "checksum"
-	Context      = "context"
-	Credential   = "credential"
-	DirFunc      = "dir_func"
-	Endpoint     = "endpoint"
-	Expire       = "expire"
-	FileFunc     = "file_func"
-	Location     = "location"
-	Name         = "name"
-	Offset       = "offset"
-	PartSize     = "part_size"
-	Project      = "project"
-	SegmentFunc  = "segment_func"
-	Size         = "size"
-	StorageClass = "storage_class"
-	StoragerFunc = "storager_func"
-	Type         = "type"
-	WorkDir      = "work_dir"
+	CacheControl       = "cache_control"
+	Checksum           = "checksum"
+	ContentDisposition = "content_disposition"
+	ContentEncoding    = "content_encoding"
+	ContentType        = "content_type"
+	Context            = "context"
+	ContinuationToken  = "continuation_token"
+	Credential         = "credential"
+	DirFunc            = "dir_func"
+	Endpoint           = "endpoint"
+	Expire             = "expire"
+	Expires            = "expires"
+	FileFunc           = "file_func"
+	ForcePathStyle     = "force_path_style"
+	IfMatch            = "if_match"
+	IfModifiedSince    = "if_modified_since"
+	IfNoneMatch        = "if_none_match"
+	IfUnmodifiedSince  = "if_unmodified_since"
+	Location           = "location"
+	Name               = "name"
+	Offset             = "offset"
+	PartSize           = "part_size"
+	Project            = "project"
+	SegmentFunc        = "segment_func"
+	Size               = "size"
+	StorageClass       = "storage_class"
+	StoragerFunc       = "storager_func"
+	Tags               = "tags"
+	Type               = "type"
+	UserMetadata       = "user_metadata"
+	VerifyChecksum     = "verify_checksum"
+	VersionID          = "version_id"
+	Versioning         = "versioning"
+	WorkDir            = "work_dir"
 )
 
+// WithCacheControl will apply cache_control value to Options
+func WithCacheControl(v string) *types.Pair {
+	return &types.Pair{
+		Key:   CacheControl,
+		Value: v,
+	}
+}
+
 // WithChecksum will apply checksum value to Options
 func WithChecksum(v string) *types.Pair {
 	return &types.Pair{
@@ -42,6 +67,30 @@ func WithChecksum(v string) *types.Pair {
 	}
 }
 
+// WithContentDisposition will apply content_disposition value to Options
+func WithContentDisposition(v string) *types.Pair {
+	return &types.Pair{
+		Key:   ContentDisposition,
+		Value: v,
+	}
+}
+
+// WithContentEncoding will apply content_encoding value to Options
+func WithContentEncoding(v string) *types.Pair {
+	return &types.Pair{
+		Key:   ContentEncoding,
+		Value: v,
+	}
+}
+
+// WithContentType will apply content_type value to Options
+func WithContentType(v string) *types.Pair {
+	return &types.Pair{
+		Key:   ContentType,
+		Value: v,
+	}
+}
+
 // WithContext will apply context value to Options
 func WithContext(v context.Context) *types.Pair {
 	return &types.Pair{
@@ -50,8 +99,16 @@ func WithContext(v context.Context) *types.Pair {
 	}
 }
 
+// WithContinuationToken will apply continuation_token value to Options
+func WithContinuationToken(v string) *types.Pair {
+	return &types.Pair{
+		Key:   ContinuationToken,
+		Value: v,
+	}
+}
+
 // WithCredential will apply credential value to Options
-func WithCredential(v *credential.Provider) *types.Pair {
+func WithCredential(v credential.Provider) *types.Pair {
 	return &types.Pair{
 		Key:   Credential,
 		Value: v,
@@ -82,6 +139,14 @@ func WithExpire(v int) *types.Pair {
 	}
 }
 
+// WithExpires will apply expires value to Options
+func WithExpires(v time.Time) *types.Pair {
+	return &types.Pair{
+		Key:   Expires,
+		Value: v,
+	}
+}
+
 // WithFileFunc will apply file_func value to Options
 func WithFileFunc(v types.ObjectFunc) *types.Pair {
 	return &types.Pair{
@@ -90,6 +155,46 @@ func WithFileFunc(v types.ObjectFunc) *types.Pair {
 	}
 }
 
+// WithForcePathStyle will apply force_path_style value to Options
+func WithForcePathStyle(v bool) *types.Pair {
+	return &types.Pair{
+		Key:   ForcePathStyle,
+		Value: v,
+	}
+}
+
+// WithIfMatch will apply if_match value to Options
+func WithIfMatch(v string) *types.Pair {
+	return &types.Pair{
+		Key:   IfMatch,
+		Value: v,
+	}
+}
+
+// WithIfModifiedSince will apply if_modified_since value to Options
+func WithIfModifiedSince(v time.Time) *types.Pair {
+	return &types.Pair{
+		Key:   IfModifiedSince,
+		Value: v,
+	}
+}
+
+// WithIfNoneMatch will apply if_none_match value to Options
+func WithIfNoneMatch(v string) *types.Pair {
+	return &types.Pair{
+		Key:   IfNoneMatch,
+		Value: v,
+	}
+}
+
+// WithIfUnmodifiedSince will apply if_unmodified_since value to Options
+func WithIfUnmodifiedSince(v time.Time) *types.Pair {
+	return &types.Pair{
+		Key:   IfUnmodifiedSince,
+		Value: v,
+	}
+}
+
 // WithLocation will apply location value to Options
 func WithLocation(v string) *types.Pair {
 	return &types.Pair{
@@ -162,6 +267,14 @@ func WithStoragerFunc(v storage.StoragerFunc) *types.Pair {
 	}
 }
 
+// WithTags will apply tags value to Options
+func WithTags(v map[string]string) *types.Pair {
+	return &types.Pair{
+		Key:   Tags,
+		Value: v,
+	}
+}
+
 // WithType will apply type value to Options
 func WithType(v string) *types.Pair {
 	return &types.Pair{
@@ -170,6 +283,38 @@ func WithType(v string) *types.Pair {
 	}
 }
 
+// WithUserMetadata will apply user_metadata value to Options
+func WithUserMetadata(v map[string]string) *types.Pair {
+	return &types.Pair{
+		Key:   UserMetadata,
+		Value: v,
+	}
+}
+
+// WithVerifyChecksum will apply verify_checksum value to Options
+func WithVerifyChecksum(v bool) *types.Pair {
+	return &types.Pair{
+		Key:   VerifyChecksum,
+		Value: v,
+	}
+}
+
+// WithVersionID will apply version_id value to Options
+func WithVersionID(v string) *types.Pair {
+	return &types.Pair{
+		Key:   VersionID,
+		Value: v,
+	}
+}
+
+// WithVersioning will apply versioning value to Options
+func WithVersioning(v bool) *types.Pair {
+	return &types.Pair{
+		Key:   Versioning,
+		Value: v,
+	}
+}
+
 // WithWorkDir will apply work_dir value to Options
 func WithWorkDir(v string) *types.Pair {
 	return &types.Pair{
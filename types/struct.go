@@ -3,6 +3,7 @@ package types
 import (
 	"time"
 
+	"github.com/Xuanwo/storage/pkg/storageclass"
 	"github.com/Xuanwo/storage/types/metadata"
 )
 
@@ -56,3 +57,38 @@ type Pair struct {
 	Key   string
 	Value interface{}
 }
+
+// DeleteResult is the result for deleting an object in batch.
+type DeleteResult struct {
+	// Path is the path of the object to delete.
+	Path string
+	// Err is the error happened while deleting this object, nil means deleted.
+	Err error
+}
+
+// LifecycleRule is a service independent lifecycle rule of a bucket.
+type LifecycleRule struct {
+	// ID is the unique id of this rule in bucket, services will generate one if empty.
+	ID string
+	// Prefix is the key prefix of Objects this rule applies to, empty means the whole bucket.
+	//
+	// Lifecycle rules are bucket level, so Prefix is NOT relative to WorkDir.
+	Prefix string
+	// Enabled means this rule is in effect.
+	Enabled bool
+
+	// ExpirationDays is the days after Objects created to delete them, 0 means never.
+	ExpirationDays int
+	// Transitions will change Objects' storage class after days.
+	Transitions []LifecycleTransition
+	// AbortIncompleteSegmentDays is the days after Segments initiated to abort them, 0 means never.
+	AbortIncompleteSegmentDays int
+}
+
+// LifecycleTransition is the transition of Objects' storage class in LifecycleRule.
+type LifecycleTransition struct {
+	// Days is the days after Objects created to do this transition.
+	Days int
+	// StorageClass is the storage class to transit to, usually storageclass.Warm or storageclass.Cold.
+	StorageClass storageclass.Type
+}